@@ -0,0 +1,23 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleAnchor_sharedLine() {
+	// Ids on the same line would pin it twice, so they are ignored like ids on several lines
+	for _, right := range []string{"y\n# lhdiff:anchor a lhdiff:anchor b\n", "# lhdiff:anchor b\ny\n# lhdiff:anchor a\n"} {
+		result, err := Compare("// lhdiff:anchor a lhdiff:anchor b\nx\n", right)
+		printErr(err)
+		for _, anchor := range result.Anchors {
+			fmt.Printf("%s %d,%d\n", anchor.ID, anchor.Left, anchor.Right)
+		}
+		fmt.Println(len(result.Mappings))
+	}
+
+	// Output:
+	// 4
+	// b -1,0
+	// a -1,2
+	// 6
+}
@@ -0,0 +1,47 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+func ExampleCompareFiles() {
+	old := MapSnapshot{
+		"a.txt": "one\ntwo\nthree",
+		"b.txt": "four\nfive",
+		"c.txt": "six",
+		"e.png": "\x89PNG\r\n\x1a\n\x00\x00",
+	}
+	new := MapSnapshot{
+		"a.txt": "zero\none\ntwo\nthree",
+		"b.txt": "five\nfour",
+		"e.png": "\x89PNG\r\n\x1a\n\x00\x01",
+	}
+
+	var lines []string
+	for fileResult := range CompareFiles(old, new, []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.png"}) {
+		switch {
+		case errors.Is(fileResult.Err, ErrBinaryFile):
+			lines = append(lines, fmt.Sprintf("%s: binary", fileResult.Path))
+		case fileResult.Err != nil:
+			lines = append(lines, fmt.Sprintf("%s: %v", fileResult.Path, fileResult.Err))
+		case fileResult.Result == nil:
+			lines = append(lines, fmt.Sprintf("%s: deleted", fileResult.Path))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %v", fileResult.Path, fileResult.Result.Mappings))
+		}
+	}
+	// Results arrive in the order they complete
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	// Output:
+	// a.txt: [{0 1 1 false} {1 2 1 false} {2 3 1 false} {-1 0 0 false}]
+	// b.txt: [{0 1 1 false} {1 0 1 false}]
+	// c.txt: deleted
+	// d.txt: open d.txt: file does not exist
+	// e.png: binary
+}
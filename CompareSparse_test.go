@@ -0,0 +1,31 @@
+package lhdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+func ExampleCompareSparse() {
+	var lines strings.Builder
+	for i := 0; i < 500000; i++ {
+		_, _ = fmt.Fprintf(&lines, "line %d\n", i)
+	}
+	left := lines.String()
+	right := strings.Replace(left, "line 250000\n", "line 250000 changed\n", 1)
+	right = strings.Replace(right, "line 400000\n", "", 1)
+
+	result, err := CompareSparse(left, right)
+	printErr(err)
+	fmt.Println(result.Identical)
+	fmt.Println(result.Mappings)
+	fmt.Println(result.RightLine(300000))
+	fmt.Println(result.LeftLine(250000))
+	fmt.Println(len(result.Expand().Mappings))
+
+	// Output:
+	// [{0 0 250000} {250001 250001 149999} {400001 400000 100000}]
+	// [{250000 250000 0.76 false} {400000 -1 0 false}]
+	// 300000 1 true
+	// 250000 0.76 true
+	// 500001
+}
@@ -0,0 +1,23 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleConvertToLinesWithoutNewLine() {
+	text := "plain line\n  indented\n\ttabbed\nspaced  out\ntrailing \r\n\n \nnon-breaking\u00a0\nlast"
+	for _, line := range ConvertToLinesWithoutNewLine(text) {
+		fmt.Printf("%q\n", line)
+	}
+
+	// Output:
+	// "plain line\n"
+	// "indented\n"
+	// "tabbed\n"
+	// "spaced out\n"
+	// "trailing\n"
+	// "\n"
+	// "\n"
+	// "non-breaking\n"
+	// "last\n"
+}
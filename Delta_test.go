@@ -0,0 +1,27 @@
+package lhdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+func ExampleResult_Delta() {
+	left := "package main\n\nfunc main() {\n\tprintln(\"one\")\n\tprintln(\"two\")\n}\n\nfunc unused() {\n}\n"
+	right := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"one\")\n\tprintln(\"two\")\n}\n"
+	result, err := Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	delta := result.Delta()
+	data, err := json.Marshal(delta)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+	fmt.Println(reflect.DeepEqual(delta.Expand(), result))
+
+	// Output:
+	// {"Changes":[{"Left":2,"Right":4,"Similarity":1},{"Left":3,"Right":5,"Similarity":0.5747174927339251},{"Left":7,"Right":-1,"Similarity":0},{"Left":8,"Right":-1,"Similarity":0},{"Left":9,"Right":3,"Similarity":0.656737550101585}],"LeftLineCount":10,"RightLineCount":9}
+	// true
+}
@@ -0,0 +1,25 @@
+package lhdiff
+
+func ExampleDuplicates() {
+	text := `func add(a int, b int) int {
+	sum := a + b
+	return sum
+}
+
+func subtract(a int, b int) int {
+	difference := a - b
+	return difference
+}
+
+func add2(a int, b int) int {
+	sum := a + b
+	return sum
+}
+`
+	err := PrintDuplicates(Duplicates(text, 4))
+	printErr(err)
+
+	// Output:
+	// 1,6,0.66
+	// 1-3,11-13,0.91
+}
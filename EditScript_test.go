@@ -0,0 +1,39 @@
+package lhdiff
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleEditScript() {
+	left := "package main\n\nfunc main() {\n\tprintln(\"one\")\n\tprintln(\"two\")\n}\n\nfunc helper() {\n\treturn\n}\n\nfunc unused() {\n}\n"
+	right := "package main\n\nfunc helper() {\n\treturn\n}\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"one\")\n\tprintln(\"two\")\n}\n"
+	result, err := Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	script, err := EditScript(left, right, result)
+	if err != nil {
+		panic(err)
+	}
+	if err := WriteEditScript(os.Stdout, script); err != nil {
+		panic(err)
+	}
+	applied, err := ApplyEditScript(left, script)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(applied == right)
+
+	// Output:
+	// move 3 -> 9
+	// edit 4 -> 10
+	// +	fmt.Println("one")
+	// move 5 -> 11
+	// delete 6
+	// move 7 -> 8
+	// delete 12
+	// insert -> 7
+	// +import "fmt"
+	// true
+}
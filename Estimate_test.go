@@ -0,0 +1,32 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleEstimate() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	cost, err := Estimate(left, right)
+	printErr(err)
+	fmt.Println(cost.Comparisons, cost.Memory)
+	cost, err = Estimate(left, right, WithMaxComparisons(4))
+	printErr(err)
+	fmt.Println(cost.Comparisons)
+	_, err = Estimate(left, right, WithMaxLines(4))
+	fmt.Println(err)
+
+	// Output:
+	// 6 1895
+	// 4
+	// the right file has 5 lines, more than the limit of 4
+}
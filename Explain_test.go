@@ -0,0 +1,108 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleExplain() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	for _, lines := range [][2]int{{2, 1}, {1, 2}, {0, 0}, {0, 1}} {
+		explanation, err := Explain(left, right, lines[0], lines[1])
+		printErr(err)
+		fmt.Printf("%d,%d %.3f %v: %s\n", lines[0], lines[1], explanation.Similarity, explanation.Paired, explanation.Reason)
+	}
+
+	// Output:
+	// 2,1 0.574 true: the similarity 0.574 is above the threshold 0.450, and no other left line is more similar to the right line
+	// 1,2 0.000 false: the content similarity 0.214 isn't above 0.5
+	// 0,0 0.703 true: the lines are unchanged
+	// 0,1 0.000 false: the left line is unchanged, paired with right line 1
+}
+
+func ExampleExplain_sameContent() {
+	left := `func one() {
+	return 1
+}
+
+func two() {
+	return 2
+}`
+
+	right := `func two() {
+	return 2
+}
+
+func one() {
+	return 1
+}`
+
+	for _, lines := range [][2]int{{0, 4}, {1, 5}, {1, 1}} {
+		explanation, err := Explain(left, right, lines[0], lines[1])
+		printErr(err)
+		fmt.Printf("%d,%d %v: %s\n", lines[0], lines[1], explanation.Paired, explanation.Reason)
+	}
+
+	// Output:
+	// 0,4 true: the lines have the same content, and were paired before comparing the other lines by similarity
+	// 1,5 true: the lines have the same content, and were paired before comparing the other lines by similarity
+	// 1,1 false: the right line is unchanged, paired with left line 6
+}
+
+func ExampleExplain_duplicates() {
+	left := `func run() error {
+	if err := open(); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	if err := parse(); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	return nil
+}
+
+func main() {
+	fmt.Println("starting")
+	config := load()
+	server := start(config)
+	defer server.Close()
+	wait()
+	fmt.Println("stopping")
+}`
+
+	right := `func main() {
+	fmt.Println("starting")
+	config := load()
+	server := start(config)
+	defer server.Close()
+	wait()
+	fmt.Println("stopping")
+}
+
+func run() error {
+	if err := openFile(); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	return nil
+}`
+
+	// The return was deleted twice and added once: the one closest to where it would be if
+	// the function hadn't moved is paired, and the other one is deleted
+	for _, lines := range [][2]int{{2, 11}, {5, 11}} {
+		explanation, err := Explain(left, right, lines[0], lines[1])
+		printErr(err)
+		fmt.Printf("%d,%d %v: %s\n", lines[0], lines[1], explanation.Paired, explanation.Reason)
+	}
+
+	// Output:
+	// 2,11 true: the lines have the same content, and were paired before comparing the other lines by similarity
+	// 5,11 false: left line 3 is more similar to the right line (1.000)
+}
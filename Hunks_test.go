@@ -0,0 +1,44 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleSparseResult_Hunks() {
+	left := `package main
+import "fmt"
+func hello() { fmt.Println("hello") }
+func main() { hello(); world() }
+var unused = 1
+var greeting = "hi"
+var answer = 42
+var question = "?"
+func world() { fmt.Println("world") }
+`
+	right := `package main
+import "fmt"
+func main() { hello(); world() }
+var unused = 1
+var greeting = "hi"
+var answer = 42
+var question = "?"
+func world() { fmt.Println("world!") }
+func hello() { fmt.Println("hello") }
+`
+	// hello moved from the first hunk to the second one
+	result, err := CompareSparse(left, right)
+	printErr(err)
+	for _, hunk := range result.Hunks(1) {
+		fmt.Printf("@@ %d-%d %d-%d @@\n", hunk.LeftStart, hunk.LeftEnd, hunk.RightStart, hunk.RightEnd)
+		for _, mapping := range hunk.Mappings {
+			fmt.Printf("%d,%d %.2f\n", mapping.Left, mapping.Right, mapping.Similarity)
+		}
+	}
+
+	// Output:
+	// @@ 1-4 1-3 @@
+	// 2,8 1.00
+	// @@ 7-10 6-10 @@
+	// 8,7 0.77
+	// 2,8 1.00
+}
@@ -0,0 +1,33 @@
+package lhdiff
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+func ExampleLhdiffLines() {
+	left := []string{"one two three four", "eight", "nine ten eleven twelve", "thirteen fourteen fifteen"}
+
+	var right []string
+	scanner := bufio.NewScanner(strings.NewReader("one  two three four\r\nnine ten twelve\r\nfive six BANANA seven eight\r\nAPPLE PEAR\r\nthirteen fourteen fifteen\r\n"))
+	for scanner.Scan() {
+		right = append(right, scanner.Text())
+	}
+
+	result, err := LhdiffLines(left, right)
+	printErr(err)
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%d,%d %.2f\n", mapping.Left, mapping.Right, mapping.Similarity)
+	}
+	fmt.Printf("%q\n", right[0])
+
+	// Output:
+	// 0,0 1.00
+	// 1,-1 0.00
+	// 2,1 0.57
+	// 3,4 1.00
+	// -1,2 0.00
+	// -1,3 0.00
+	// "one  two three four"
+}
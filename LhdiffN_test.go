@@ -0,0 +1,42 @@
+package lhdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+func ExampleLhdiffN() {
+	versions := []string{
+		`func main() {
+	fmt.Println("hello")
+	log.Println("debugging")
+}`,
+		`func main() {
+	fmt.Println("hello, world")
+}`,
+		`func main() {
+	fmt.Println("hello, world")
+	log.Println("debugging again")
+}`,
+	}
+
+	trajectories, err := LhdiffN(versions)
+	printErr(err)
+	for _, trajectory := range trajectories {
+		var hops []string
+		for version, line := range trajectory.Lines {
+			if line == -1 {
+				hops = append(hops, "_")
+			} else {
+				hops = append(hops, fmt.Sprintf("%d:%.2f", line, trajectory.Similarities[version]))
+			}
+		}
+		fmt.Println(strings.Join(hops, " "))
+	}
+
+	// Output:
+	// 0:0.00 0:1.00 0:1.00
+	// 1:0.00 1:0.61 1:1.00
+	// 2:0.00 _ 2:0.55
+	// 3:0.00 2:1.00 3:1.00
+}
@@ -0,0 +1,75 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func ExampleLimitError() {
+	_, err := Compare("one\ntwo\n", "one\ntwo\nthree\n", WithMaxLines(3))
+	var limitError *LimitError
+	fmt.Println(errors.As(err, &limitError), err)
+	fmt.Println(errors.Is(err, ErrTooLarge))
+
+	// Output:
+	// true the right file has 4 lines, more than the limit of 3
+	// true
+}
+
+func ExampleLimitError_cached() {
+	// The limit isn't part of the key of the cache, so it is checked before the cache is
+	cache := NewMemoryCache(10)
+	left, right := "one\ntwo\n", "one\ntwo\nthree\n"
+	_, err := Compare(left, right, WithCache(cache))
+	printErr(err)
+	_, err = Compare(left, right, WithCache(cache), WithMaxLines(3))
+	fmt.Println(err)
+	_, err = LhdiffLines(strings.Split(left, "\n"), strings.Split(right, "\n"), WithCache(cache))
+	printErr(err)
+	_, err = LhdiffLines(strings.Split(left, "\n"), strings.Split(right, "\n"), WithCache(cache), WithMaxLines(3))
+	fmt.Println(err)
+
+	// Output:
+	// the right file has 4 lines, more than the limit of 3
+	// the right file has 4 lines, more than the limit of 3
+}
+
+func ExampleLimitError_millionsOfLines() {
+	// Files of millions of lines are rejected without being compared
+	var lines strings.Builder
+	for i := 0; i < 3000000; i++ {
+		_, _ = fmt.Fprintf(&lines, "line %d\n", i)
+	}
+	_, err := Compare("one\n", lines.String(), WithMaxLines(2500000))
+	fmt.Println(err)
+	_, err = LhdiffLines(strings.Split(lines.String(), "\n"), nil, WithMaxLines(2500000))
+	fmt.Println(err)
+
+	// Output:
+	// the right file has 3000001 lines, more than the limit of 2500000
+	// the left file has 3000001 lines, more than the limit of 2500000
+}
+
+func ExampleCompare_withMillionsOfLines() {
+	var lines strings.Builder
+	for i := 0; i < 2000000; i++ {
+		_, _ = fmt.Fprintf(&lines, "line %d\n", i)
+	}
+	left := lines.String()
+	right := strings.Replace(left, "line 1999999\n", "line 1999999 changed\n", 1)
+	right = strings.Replace(right, "line 1000000\n", "", 1)
+
+	result, err := Compare(left, right)
+	printErr(err)
+	fmt.Println(result.LeftLineCount, result.RightLineCount)
+	fmt.Println(result.RightLine(999999))
+	fmt.Println(result.RightLine(1000000))
+	fmt.Println(result.RightLine(1999999))
+
+	// Output:
+	// 2000001 2000000
+	// 999999 1 true
+	// -1 0 false
+	// 1999998 0.7714285714285715 true
+}
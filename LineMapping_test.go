@@ -0,0 +1,60 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleLineMapping() {
+	left := `func area(width, height int) int {
+	return width*height
+}
+func perimeter(width, height int) int {
+	return 2*(width+height)
+}`
+
+	right := `func area(width, height int) int {
+        return width * height
+}
+func perimeter(width, height int) int {
+	return 2*width + 2*height
+}`
+
+	// area was only reformatted, while perimeter was rewritten. The indentation of lines isn't
+	// a change at all.
+	result, err := Compare(left, right)
+	printErr(err)
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%d,%d %.2f whitespace-only=%v\n", mapping.Left, mapping.Right, mapping.Similarity, mapping.WhitespaceOnly)
+	}
+
+	// Output:
+	// 0,0 1.00 whitespace-only=false
+	// 1,1 0.69 whitespace-only=true
+	// 2,2 1.00 whitespace-only=false
+	// 3,3 1.00 whitespace-only=false
+	// 4,4 0.63 whitespace-only=false
+	// 5,5 1.00 whitespace-only=false
+}
+
+func ExampleLineMapping_Displacement() {
+	left := `func hello() { fmt.Println("hello") }
+func world() { fmt.Println("world") }
+func main() { hello(); world() }`
+
+	right := `package main
+func world() { fmt.Println("world") }
+func main() { hello(); world() }
+func hello() { fmt.Println("hello") }`
+
+	result, err := Compare(left, right)
+	printErr(err)
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%d,%d %+d\n", mapping.Left, mapping.Right, mapping.Displacement())
+	}
+
+	// Output:
+	// 0,3 +3
+	// 1,1 +0
+	// 2,2 +0
+	// -1,0 +0
+}
@@ -0,0 +1,19 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleOptionsKey() {
+	fmt.Println(OptionsKey() == OptionsKey(WithContextSize(4)))
+	fmt.Println(OptionsKey() == OptionsKey(WithContextSize(2)))
+	fmt.Println(OptionsKey() == OptionsKey(WithProfiles(map[string][]Option{".json": {WithContextSize(2)}})))
+	// Options that don't affect results don't change the key
+	fmt.Println(OptionsKey() == OptionsKey(WithRedaction("salt"), WithMaxLines(100)))
+
+	// Output:
+	// true
+	// false
+	// false
+	// true
+}
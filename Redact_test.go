@@ -0,0 +1,24 @@
+package lhdiff
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRedact() {
+	fmt.Println(Redact("salt", "password := \"hunter2\""))
+	fmt.Println(Redact("salt", "password := \"hunter2\""))
+	fmt.Println(Redact("pepper", "password := \"hunter2\""))
+	fmt.Printf("%q\n", Redact("salt", ""))
+
+	_, err := Compare("one\npassword := \"hunter2\"\n", "one\npassword := \"hunter3\"\n", WithRedaction("salt"), WithDebug(os.Stdout, 1))
+	printErr(err)
+
+	// Output:
+	// #c3e5cc127588aec3
+	// #c3e5cc127588aec3
+	// #29780ff215389880
+	// ""
+	// right 2 "#9d895cc7af424d20": paired with left 2
+	//   left 2 "#c3e5cc127588aec3": similarity 0.973, content 0.955, context 1.000
+}
@@ -0,0 +1,50 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleRemapBreakpoints() {
+	old := MapSnapshot{
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello")
+	fmt.Println("world")
+}
+`,
+		"util.go": `package main
+`,
+	}
+	new := MapSnapshot{
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	greeting := "hello"
+	fmt.Println(greeting)
+	fmt.Println("world!")
+}
+`,
+	}
+
+	remappings, err := RemapBreakpoints(old, new, []Breakpoint{
+		{Path: "main.go", Line: 5, Column: 1},
+		{Path: "main.go", Line: 6, Column: 2},
+		{Path: "main.go", Line: 7, Column: 2},
+		{Path: "util.go", Line: 1},
+	})
+	printErr(err)
+	for _, remapping := range remappings {
+		fmt.Printf("%v -> %v deleted=%v\n", remapping.Old, remapping.New, remapping.Deleted)
+	}
+
+	// Output:
+	// {main.go 5 1} -> {main.go 5 1} deleted=false
+	// {main.go 6 2} -> {main.go 7 0} deleted=false
+	// {main.go 7 2} -> {main.go 8 0} deleted=false
+	// {util.go 1 0} -> { 0 0} deleted=true
+}
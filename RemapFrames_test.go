@@ -0,0 +1,46 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleRemapFrames() {
+	old := MapSnapshot{
+		"parse.go": `package parse
+
+func Parse(s string) int {
+	if s == "" {
+		panic("empty")
+	}
+	return len(s)
+}
+`,
+	}
+	new := MapSnapshot{
+		"parse.go": `package parse
+
+import "strings"
+
+func Parse(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		panic("empty string")
+	}
+	return len(s)
+}
+`,
+	}
+
+	remappings, err := RemapFrames(old, new, []Frame{
+		{Path: "parse.go", Line: 5, Function: "parse.Parse"},
+		{Path: "parse.go", Line: 7, Function: "parse.Parse"},
+	})
+	printErr(err)
+	for _, remapping := range remappings {
+		fmt.Printf("%s:%d -> %s:%d\n", remapping.Old.Path, remapping.Old.Line, remapping.New.Path, remapping.New.Line)
+	}
+
+	// Output:
+	// parse.go:5 -> parse.go:8
+	// parse.go:7 -> parse.go:10
+}
@@ -0,0 +1,24 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleShardPaths() {
+	paths := []string{"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go", "h.go"}
+	for shard := 1; shard <= 3; shard++ {
+		sharded, err := ShardPaths(paths, shard, 3)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%d/3: %v\n", shard, sharded)
+	}
+	_, err := ShardPaths(paths, 1, 0)
+	fmt.Println(err)
+
+	// Output:
+	// 1/3: [c.go d.go f.go g.go]
+	// 2/3: [h.go]
+	// 3/3: [a.go b.go e.go]
+	// invalid shard 1/0: a shard is i/n, with 1 <= i <= n
+}
@@ -0,0 +1,24 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleSimilarity() {
+	scores := Similarity("nine ten eleven twelve", "nine  ten twelve")
+	fmt.Printf("%.3f %.3f %.3f\n", scores.Content, scores.Context, scores.Combined)
+
+	scores = Similarity("nine ten eleven twelve", "nine ten twelve", WithSurroundings(
+		Surroundings{Before: []string{"one two three four"}, After: []string{"", "thirteen fourteen fifteen"}},
+		Surroundings{Before: []string{"one two three four"}, After: []string{"}", "thirteen fourteen"}},
+	))
+	fmt.Printf("%.3f %.3f %.3f\n", scores.Content, scores.Context, scores.Combined)
+
+	scores = Similarity("nine ten eleven twelve", "something else")
+	fmt.Printf("%.3f %.3f %.3f\n", scores.Content, scores.Context, scores.Combined)
+
+	// Output:
+	// 0.696 0.000 0.696
+	// 0.696 0.619 0.665
+	// 0.304 0.000 0.000
+}
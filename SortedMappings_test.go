@@ -0,0 +1,27 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleResult_SortedMappings() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	result, err := Compare(left, right)
+	printErr(err)
+	fmt.Println(result.SortedMappings(RightOrder))
+	fmt.Println(result.SortedMappings(SimilarityOrder))
+
+	// Output:
+	// [{0 0 1 false} {2 1 0.5740687026357825 false} {-1 2 0 false} {-1 3 0 false} {3 4 1 false} {1 -1 0 false}]
+	// [{1 -1 0 false} {-1 2 0 false} {-1 3 0 false} {2 1 0.5740687026357825 false} {0 0 1 false} {3 4 1 false}]
+}
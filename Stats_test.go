@@ -0,0 +1,45 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleResult_Stats() {
+	left := `package main
+import "fmt"
+func hello() { fmt.Println("hello") }
+func world() { fmt.Println("world") }
+func foo() { fmt.Println("foo") }
+func bar() { fmt.Println("bar") }
+func main() { hello(); world(); foo(); bar() }
+`
+	right := `package main
+import "fmt"
+func foo() { fmt.Println("foo") }
+func bar() { fmt.Println("bar") }
+func main() { hello(); world(); foo(); bar(); fmt.Println() }
+func hello() { fmt.Println("hello") }
+func world() { fmt.Println("world") }
+`
+	result, err := Compare(left, right)
+	printErr(err)
+	stats := result.Stats()
+	fmt.Println(stats.Summary)
+	fmt.Printf("%.3f %+v\n", stats.Similarity, stats.LargestMove)
+	fmt.Println(result.MovedBlocks(), result.Lost())
+
+	result, err = Compare("one\ntwo\n", "one\n")
+	printErr(err)
+	fmt.Println(result.MovedBlocks(), result.Lost())
+	stats.Add(result.Stats())
+	fmt.Println(stats.Summary, stats.LeftLineCount, stats.RightLineCount)
+	fmt.Printf("%.3f %+v\n", stats.Similarity, stats.LargestMove)
+
+	// Output:
+	// 5 unchanged, 1 changed, 2 reordered, 0 deleted, 0 added
+	// 0.958 {Left:2 Right:5 Length:2}
+	// [{2 5 2}] []
+	// [] [1]
+	// 7 unchanged, 1 changed, 2 reordered, 1 deleted, 0 added 11 10
+	// 0.967 {Left:2 Right:5 Length:2}
+}
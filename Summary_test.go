@@ -0,0 +1,38 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleResult_Summary() {
+	left := `package main
+import "fmt"
+func hello() { fmt.Println("hello") }
+func world() { fmt.Println("world") }
+func main() { hello(); world() }
+`
+	right := `package main
+import "fmt"
+func world() { fmt.Println("world!") }
+func hello() { fmt.Println("hello") }
+func main() { hello(); world(); fmt.Println() }
+`
+	for _, opts := range [][]Option{nil, {WithMonotonic()}} {
+		result, err := Compare(left, right, opts...)
+		printErr(err)
+		fmt.Println(result.Reordered())
+		fmt.Println(result.Summary())
+	}
+	result, err := Compare(left, right)
+	printErr(err)
+	fmt.Println(result.Summary().Tracked())
+	fmt.Printf("%.2f %d\n", result.Summary().MeanDisplacement, result.Summary().MaxDisplacement)
+
+	// Output:
+	// [{2 3 1 false}]
+	// 3 unchanged, 2 changed, 1 reordered, 0 deleted, 0 added
+	// [{2 3 1 false}]
+	// 3 unchanged, 2 changed, 1 reordered, 0 deleted, 0 added
+	// 6
+	// 0.33 1
+}
@@ -0,0 +1,30 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+)
+
+func ExampleTrackLine() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	line, similarity, err := TrackLine(left, right, 2)
+	printErr(err)
+	fmt.Printf("%d %.2f\n", line, similarity)
+
+	_, _, err = TrackLine(left, right, 1)
+	fmt.Println(errors.Is(err, ErrLineNotFound), err)
+
+	// Output:
+	// 1 0.57
+	// true line 1: line not found
+}
@@ -0,0 +1,46 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleTrackRange() {
+	left := `func open() {
+	file := create()
+	defer file.Close()
+	write(file)
+}
+
+func close() {
+	cleanup()
+}`
+
+	right := `func close() {
+	cleanup()
+}
+
+func open() {
+	file := create()
+	defer file.Close()
+	log("writing")
+	write(file)
+}`
+
+	ranges, err := TrackRange(left, right, 0, 4)
+	printErr(err)
+	for _, r := range ranges {
+		fmt.Printf("%d-%d %.2f\n", r.Start, r.End, r.Similarity)
+	}
+
+	ranges, err = TrackRange(left, right, 3, 7)
+	printErr(err)
+	for _, r := range ranges {
+		fmt.Printf("%d-%d %.2f\n", r.Start, r.End, r.Similarity)
+	}
+
+	// Output:
+	// 4-9 1.00
+	// 0-1 1.00
+	// 3-3 0.83
+	// 8-9 1.00
+}
@@ -0,0 +1,34 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithAdaptiveWeighting() {
+	left := `open()
+read(a)
+close()
+log(a)
+open()
+write(b)
+close()
+`
+	right := `open()
+read(a)
+close(a)
+log(a)
+open()
+write(b)
+flush()
+`
+	// close() and flush() are short lines that look alike, but are unrelated
+	for _, opts := range [][]Option{nil, {WithAdaptiveWeighting(DefaultShortLineLength, DefaultLongLineLength)}} {
+		result, err := Compare(left, right, opts...)
+		printErr(err)
+		fmt.Println(result.Mappings[6])
+	}
+
+	// Output:
+	// {6 6 0.4532229893276206 false}
+	// {6 -1 0 false}
+}
@@ -0,0 +1,84 @@
+package lhdiff
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func ExampleWithCache() {
+	dir, err := ioutil.TempDir("", "lhdiff-cache")
+	printErr(err)
+	defer os.RemoveAll(dir)
+	cache := DirCache(dir)
+
+	left := "one\ntwo\nthree"
+	right := "zero\none\ntwo\nthree"
+	for i := 0; i < 2; i++ {
+		result, err := Compare(left, right, WithCache(cache))
+		printErr(err)
+		fmt.Println(result.Mappings)
+	}
+	// A different context size is cached separately
+	_, err = Compare(left, right, WithCache(cache), WithContextSize(2))
+	printErr(err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	printErr(err)
+	fmt.Println(len(files))
+
+	// Output:
+	// [{0 1 1 false} {1 2 1 false} {2 3 1 false} {-1 0 0 false}]
+	// [{0 1 1 false} {1 2 1 false} {2 3 1 false} {-1 0 0 false}]
+	// 2
+}
+
+func ExampleDirCache_corrupted() {
+	dir, err := ioutil.TempDir("", "lhdiff-cache")
+	printErr(err)
+	defer os.RemoveAll(dir)
+	cache := DirCache(dir)
+
+	left := "one\ntwo\nthree"
+	right := "zero\none\ntwo\nthree"
+	_, err = Compare(left, right, WithCache(cache))
+	printErr(err)
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	printErr(err)
+	// An entry that doesn't have a mapping for each line is ignored
+	printErr(ioutil.WriteFile(files[0], []byte(`{"Mappings":[{"Left":0,"Right":7}],"LeftLineCount":3,"RightLineCount":4}`), 0644))
+	result, err := Compare(left, right, WithCache(cache))
+	printErr(err)
+	fmt.Println(result.Mappings)
+	fmt.Println(result.RightLine(2))
+
+	// Output:
+	// [{0 1 1 false} {1 2 1 false} {2 3 1 false} {-1 0 0 false}]
+	// 3 1 true
+}
+
+func ExampleMemoryCache() {
+	cache := NewMemoryCache(1)
+	left := "one\ntwo\nthree"
+	for _, right := range []string{"zero\none\ntwo\nthree", "one\nthree"} {
+		result, err := Compare(left, right, WithCache(cache))
+		printErr(err)
+		fmt.Println(result.Mappings)
+	}
+	result, _ := Compare(left, "one\nthree")
+	cache.Put("key", result)
+	// Modifying a cached result doesn't modify the one in the cache
+	result.Mappings[0].Right = 7
+	cached, ok := cache.Get("key")
+	fmt.Println(cached.Mappings, ok)
+	// The cache holds a single result, so the result of the last comparison was evicted
+	_, ok = cache.Get(cacheKey(left, "one\nthree", newOptions([]Option{WithCache(cache)})))
+	fmt.Println(ok)
+
+	// Output:
+	// [{0 1 1 false} {1 2 1 false} {2 3 1 false} {-1 0 0 false}]
+	// [{0 0 1 false} {1 -1 0 false} {2 1 1 false}]
+	// [{0 0 1 false} {1 -1 0 false} {2 1 1 false}] true
+	// false
+}
@@ -0,0 +1,65 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithCalibratedThreshold() {
+	// Unrelated lines of a terse file are very different, so an edited line is paired even
+	// though little of it is left
+	left := "[log]\nlevel = info\nformat = json\nrotate = daily\n[db]\nname = app\n"
+	right := "[log]\nlevel = debug\nformats = text\nrotation = weekly\n[db]\nname = app\n"
+	printChanged(left, right)
+	printChanged(left, right, WithCalibratedThreshold())
+
+	// Unrelated lines of a verbose file share a lot of boilerplate, so a replaced line isn't
+	// paired just because it looks like the others
+	left = `class Account {
+    private final String owner;
+    private final String currency;
+    private final long balance;
+
+    String getOwnerName() {
+        return owner;
+    }
+
+    String getCurrencyCode() {
+        return currency;
+    }
+}
+`
+	right = `class Account {
+    private final String ownerName;
+    private final String currencyCode;
+    private final Instant openedAt;
+
+    String getOwnerName() {
+        return ownerName;
+    }
+
+    String getCurrencyCode() {
+        return currencyCode;
+    }
+}
+`
+	printChanged(left, right)
+	printChanged(left, right, WithCalibratedThreshold())
+
+	// Output:
+	// [1,-1 2,2 3,-1 -1,1 -1,3]
+	// [1,1 2,2 3,3]
+	// [1,1 2,2 3,3 6,6 10,10]
+	// [1,1 2,2 3,-1 6,6 10,10 -1,3]
+}
+
+func printChanged(left string, right string, opts ...Option) {
+	result, err := Compare(left, right, opts...)
+	printErr(err)
+	var changed []string
+	for _, mapping := range result.Mappings {
+		if mapping.Similarity != 1 {
+			changed = append(changed, fmt.Sprintf("%d,%d", mapping.Left, mapping.Right))
+		}
+	}
+	fmt.Println(changed)
+}
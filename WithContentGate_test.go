@@ -0,0 +1,34 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithContentGate() {
+	left := `func handle(request Request) error {
+	validate(request)
+	return store.save(request.body)
+	log.info("handled")
+}`
+
+	right := `func handle(request Request) error {
+	validate(request)
+	if err := repository.persist(ctx, request.Payload()); err != nil {
+	log.info("handled")
+}`
+
+	for _, gate := range []float64{ContentSimilarityGate, 0.2, -1} {
+		result, err := Compare(left, right, WithContentGate(gate))
+		printErr(err)
+		fmt.Println(result.Mappings[2])
+	}
+	explanation, err := Explain(left, right, 2, 2)
+	printErr(err)
+	fmt.Println(explanation.Reason)
+
+	// Output:
+	// {2 -1 0 false}
+	// {2 2 0.5880597014925373 false}
+	// {2 2 0.5880597014925373 false}
+	// the content similarity 0.313 isn't above 0.5
+}
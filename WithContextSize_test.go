@@ -0,0 +1,33 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithContextSize() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	for _, contextSize := range []int{4, 0, -1} {
+		result, err := Compare(left, right, WithContextSize(contextSize))
+		printErr(err)
+		fmt.Println(result.Mappings)
+	}
+	explanation, err := Explain(left, right, 2, 1, WithContextSize(0))
+	printErr(err)
+	fmt.Println(explanation.ContentFactor, explanation.ContextFactor, explanation.ContentSimilarity, explanation.Similarity)
+
+	// Output:
+	// [{0 0 1 false} {1 -1 0 false} {2 1 0.5740687026357825 false} {3 4 1 false} {-1 2 0 false} {-1 3 0 false}]
+	// [{0 0 1 false} {1 -1 0 false} {2 1 0.6956521739130435 false} {3 4 1 false} {-1 2 0 false} {-1 3 0 false}]
+	// [{0 0 1 false} {1 -1 0 false} {2 1 0.6956521739130435 false} {3 4 1 false} {-1 2 0 false} {-1 3 0 false}]
+	// 1 0 0.6956521739130435 0.6956521739130435
+}
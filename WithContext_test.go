@@ -0,0 +1,49 @@
+package lhdiff
+
+import (
+	"context"
+	"fmt"
+)
+
+// cancelledAfter is a context that is cancelled once Err has been called calls times, to cancel
+// comparisons at a given point.
+type cancelledAfter struct {
+	context.Context
+	calls int
+}
+
+func (ctx *cancelledAfter) Err() error {
+	if ctx.calls == 0 {
+		return context.Canceled
+	}
+	ctx.calls--
+	return nil
+}
+
+func ExampleWithContext() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+eight!
+thirteen fourteen fifteen`
+
+	for calls := 4; calls >= 0; calls-- {
+		result, err := Compare(left, right, WithContext(&cancelledAfter{Context: context.Background(), calls: calls}))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(result.Mappings, result.Partial)
+	}
+
+	// Output:
+	// [{0 0 1 false} {1 2 0.8131423034693432 false} {2 1 0.5976146572784521 false} {3 3 1 false}] false
+	// [{0 0 1 false} {1 -1 0 false} {2 1 0.5976146572784521 false} {3 3 1 false} {-1 2 0 false}] true
+	// [{0 0 1 false} {1 -1 0 false} {2 -1 0 false} {3 3 1 false} {-1 1 0 false} {-1 2 0 false}] true
+	// [{0 0 1 false} {1 -1 0 false} {2 -1 0 false} {3 3 1 false} {-1 1 0 false} {-1 2 0 false}] true
+	// context canceled
+}
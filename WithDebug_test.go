@@ -0,0 +1,32 @@
+package lhdiff
+
+import (
+	"os"
+)
+
+func ExampleWithDebug() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	_, err := Compare(left, right, WithDebug(os.Stdout, 2))
+	printErr(err)
+
+	// Output:
+	// right 2 "nine ten twelve": paired with left 3
+	//   left 3 "nine ten eleven twelve": similarity 0.574, content 0.696, context 0.392
+	//   left 2 "eight": similarity 0.000, content 0.188, context 0.160
+	// right 3 "five six BANANA seven eight": added, best similarity 0.000 isn't above the threshold 0.450
+	//   left 3 "nine ten eleven twelve": similarity 0.000, content 0.321, context 0.275
+	//   left 2 "eight": similarity 0.000, content 0.214, context 0.478
+	// right 4 "APPLE PEAR": added, best similarity 0.000 isn't above the threshold 0.450
+	//   left 3 "nine ten eleven twelve": similarity 0.000, content 0.087, context 0.370
+	//   left 2 "eight": similarity 0.000, content 0.091, context 0.358
+}
@@ -0,0 +1,45 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithDiffEngine() {
+	left := `import "fmt"
+
+func main() {
+
+	fmt.Println("hello")
+}
+`
+	right := `import "fmt"
+func main() {
+
+
+	fmt.Println("hello")
+}
+`
+	for _, engine := range []DiffEngine{Myers{}, Myers{IgnoreBlankLines: true}} {
+		result, err := Compare(left, right, WithDiffEngine(engine))
+		printErr(err)
+		for _, mapping := range result.Mappings {
+			fmt.Printf("%d,%d %.2f\n", mapping.Left, mapping.Right, mapping.Similarity)
+		}
+	}
+
+	// Output:
+	// 0,0 1.00
+	// 1,3 1.00
+	// 2,1 1.00
+	// 3,2 1.00
+	// 4,4 1.00
+	// 5,5 1.00
+	// 6,6 1.00
+	// 0,0 1.00
+	// 1,2 1.00
+	// 2,1 1.00
+	// 3,3 1.00
+	// 4,4 1.00
+	// 5,5 1.00
+	// 6,6 1.00
+}
@@ -0,0 +1,33 @@
+package lhdiff
+
+import (
+	"os"
+)
+
+func ExampleWithDiffOutput() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	_, err := Compare(left, right, WithDiffOutput(os.Stdout))
+	printErr(err)
+
+	// Output:
+	// --- left
+	// +++ right
+	// @@ -1,4 +1,5 @@
+	//  one two three four
+	// -eight
+	// -nine ten eleven twelve
+	// +nine ten twelve
+	// +five six BANANA seven eight
+	// +APPLE PEAR
+	//  thirteen fourteen fifteen
+}
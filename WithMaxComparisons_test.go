@@ -0,0 +1,30 @@
+package lhdiff
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleWithMaxComparisons() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+eight!
+thirteen fourteen fifteen`
+
+	for _, opts := range [][]Option{nil, {WithMaxComparisons(2)}, {WithMaxComparisons(1)}, {WithDeadline(time.Nanosecond)}} {
+		result, err := Compare(left, right, opts...)
+		printErr(err)
+		fmt.Println(result.Mappings, result.Truncated)
+	}
+
+	// Output:
+	// [{0 0 1 false} {1 2 0.8131423034693432 false} {2 1 0.5976146572784521 false} {3 3 1 false}] false
+	// [{0 0 1 false} {1 -1 0 false} {2 1 0.5976146572784521 false} {3 3 1 false} {-1 2 0 false}] true
+	// [{0 0 1 false} {1 -1 0 false} {2 -1 0 false} {3 3 1 false} {-1 1 0 false} {-1 2 0 false}] true
+	// [{0 0 1 false} {1 -1 0 false} {2 -1 0 false} {3 3 1 false} {-1 1 0 false} {-1 2 0 false}] true
+}
@@ -0,0 +1,28 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithMonotonic() {
+	left := `package main
+import "fmt"
+func hello() { fmt.Println("hello") }
+func world() { fmt.Println("world") }
+func main() { hello(); world() }
+`
+	right := `package main
+import "fmt"
+func world() { fmt.Println("world") }
+func hello() { fmt.Println("hello") }
+func main() { hello(); world() }
+`
+	result, err := Compare(left, right, WithMonotonic())
+	printErr(err)
+	fmt.Println(result.Mappings)
+	fmt.Println(result.Moved)
+
+	// Output:
+	// [{0 0 1 false} {1 1 1 false} {2 -1 0 false} {3 2 1 false} {4 4 1 false} {5 5 1 false} {-1 3 0 false}]
+	// [{2 3 1 false}]
+}
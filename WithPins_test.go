@@ -0,0 +1,37 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithPins() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	// A reviewer knows that eight became five six BANANA seven eight, and that one two three
+	// four was rewritten as APPLE PEAR
+	result, err := Compare(left, right, WithPins([]Pin{{Left: 1, Right: 2}, {Left: 0, Right: 3}}))
+	printErr(err)
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%d,%d %.2f\n", mapping.Left, mapping.Right, mapping.Similarity)
+	}
+
+	_, err = Compare(left, right, WithPins([]Pin{{Left: 1, Right: 2}, {Left: 1, Right: 3}}))
+	fmt.Println(err)
+
+	// Output:
+	// 0,3 0.00
+	// 1,2 0.00
+	// 2,1 0.57
+	// 3,4 1.00
+	// -1,0 0.00
+	// pin 2,4: a line can only be pinned once
+}
@@ -0,0 +1,26 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithProfiles() {
+	left := `one two three four
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+thirteen fourteen fifteen`
+
+	profiles := map[string][]Option{".strict": {WithThreshold(0.9)}}
+	for _, path := range []string{"notes.txt", "NOTES.STRICT"} {
+		result, err := Compare(left, right, WithProfiles(profiles), WithPath(path))
+		printErr(err)
+		fmt.Println(path, result.Mappings)
+	}
+
+	// Output:
+	// notes.txt [{0 0 1 false} {1 1 0.817391304347826 false} {2 2 1 false}]
+	// NOTES.STRICT [{0 0 1 false} {1 -1 0 false} {2 2 1 false} {-1 1 0 false}]
+}
@@ -0,0 +1,31 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithReferenceMode() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen
+sixteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen
+sixteen
+eight`
+
+	for _, opts := range [][]Option{nil, {WithReferenceMode()}} {
+		result, err := Compare(left, right, opts...)
+		printErr(err)
+		fmt.Println(result.Mappings)
+	}
+
+	// Output:
+	// [{0 0 1 false} {1 6 1 false} {2 1 0.5832398294982923 false} {3 4 1 false} {4 5 1 false} {-1 2 0 false} {-1 3 0 false}]
+	// [{0 0 1 false} {1 6 0.7392621247645583 false} {2 1 0.7272299720444195 false} {3 4 1 false} {4 5 1 false} {-1 2 0 false} {-1 3 0 false}]
+}
@@ -0,0 +1,18 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithStopWords() {
+	left := Surroundings{Before: []string{"if err != nil {", "return nil, err"}, After: []string{"return result, nil"}}
+	right := Surroundings{Before: []string{"if len(items) == 0 {", "return nil, nil"}, After: []string{"return total, nil"}}
+	for _, opts := range [][]Option{nil, {WithStopWords("if", "return", "nil,", "nil", "{", "}")}} {
+		scores := Similarity("result := parse(data)", "total := sum(items)", append(opts, WithSurroundings(left, right))...)
+		fmt.Printf("%.3f\n", scores.Context)
+	}
+
+	// Output:
+	// 0.156
+	// 0.000
+}
@@ -0,0 +1,25 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleUnicodeTokenizer() {
+	fmt.Printf("%q\n", UnicodeTokenizer{}.Tokenize("用户登录成功 user_name=ユーザー名 (café) x := f(y)"))
+
+	// Output:
+	// ["用户" "户登" "登录" "录成" "成功" "user_name" "ユー" "ーザ" "ザー" "ー名" "café" "x" ":=" "f(y)"]
+}
+
+func ExampleWithTokenizer() {
+	left := Surroundings{Before: []string{"// 读取用户的配置文件"}, After: []string{"// 如果配置文件不存在就使用默认值"}}
+	right := Surroundings{Before: []string{"// 读取当前用户的配置文件"}, After: []string{"// 配置文件不存在时使用默认值"}}
+	for _, tokenizer := range []Tokenizer{WhitespaceTokenizer{}, UnicodeTokenizer{}} {
+		scores := Similarity("config := load(path)", "config, err := load(path)", WithSurroundings(left, right), WithTokenizer(tokenizer))
+		fmt.Printf("%T %.3f %.3f\n", tokenizer, scores.Context, scores.Combined)
+	}
+
+	// Output:
+	// lhdiff.WhitespaceTokenizer 0.013 0.490
+	// lhdiff.UnicodeTokenizer 0.327 0.615
+}
@@ -0,0 +1,48 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleVectorizer_Similarity() {
+	vectorizer := NewVectorizer(`if err != nil {
+	return err
+}
+return nil`)
+
+	fmt.Printf("%.3f\n", vectorizer.Similarity("return err", "err != nil"))
+	fmt.Printf("%.3f\n", vectorizer.Similarity("return err", "return nil"))
+	fmt.Printf("%.3f\n", vectorizer.Similarity("", ""))
+
+	// Output:
+	// 0.372
+	// 0.500
+	// 1.000
+}
+
+func ExampleWithVectorizer() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	result, err := Compare(left, right, WithVectorizer(NewVectorizer(left, right)))
+	printErr(err)
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%d,%d %.2f\n", mapping.Left, mapping.Right, mapping.Similarity)
+	}
+
+	// Output:
+	// 0,0 1.00
+	// 1,-1 0.00
+	// 2,1 0.70
+	// 3,4 1.00
+	// -1,2 0.00
+	// -1,3 0.00
+}
@@ -0,0 +1,40 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+// printingVisitor prints the pairs it visits, with one-based lines.
+type printingVisitor struct{}
+
+func (printingVisitor) Paired(mapping LineMapping) {
+	fmt.Printf("paired %d-%d %.2f\n", mapping.Left+1, mapping.Right+1, mapping.Similarity)
+}
+
+func (printingVisitor) Rejected(mapping LineMapping) {
+	fmt.Printf("rejected %d-%d %.2f\n", mapping.Left+1, mapping.Right+1, mapping.Similarity)
+}
+
+func ExampleWithVisitor() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen
+sixteen`
+
+	right := `one two three four
+nine ten twelve
+eight!
+thirteen fourteen fifteen
+something else entirely`
+
+	_, err := Compare(left, right, WithVisitor(printingVisitor{}))
+	printErr(err)
+
+	// Output:
+	// paired 1-1 1.00
+	// paired 4-4 1.00
+	// rejected 5-5 0.00
+	// paired 2-3 0.66
+	// paired 3-2 0.52
+}
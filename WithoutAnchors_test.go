@@ -0,0 +1,51 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+func ExampleWithoutAnchors() {
+	left := `// lhdiff:anchor parse
+func parse(input string) error {
+	return nil
+}
+// lhdiff:anchor render
+func render(output string) {
+}`
+
+	right := `// lhdiff:anchor render
+func draw(output io.Writer) {
+}
+// lhdiff:anchor parse
+func parseAll(input []byte) error {
+	return errors.New("not implemented")
+}`
+
+	// The anchored lines are paired by their ids, whatever their contexts
+	result, err := Compare(left, right)
+	printErr(err)
+	for _, anchor := range result.Anchors {
+		fmt.Printf("%s %d,%d\n", anchor.ID, anchor.Left, anchor.Right)
+	}
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%d,%d %.2f\n", mapping.Left, mapping.Right, mapping.Similarity)
+	}
+
+	result, err = Compare(left, right, WithoutAnchors())
+	printErr(err)
+	fmt.Println(len(result.Anchors))
+
+	// Output:
+	// parse 0,3
+	// render 4,0
+	// 0,3 1.00
+	// 1,4 0.54
+	// 2,-1 0.00
+	// 3,2 0.68
+	// 4,0 1.00
+	// 5,-1 0.00
+	// 6,6 1.00
+	// -1,1 0.00
+	// -1,5 0.00
+	// 0
+}
@@ -0,0 +1,50 @@
+package lhdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+func ExampleWriteColorMovedDiff() {
+	left := `package main
+func hello() { fmt.Println("hello") }
+func world() { fmt.Println("world") }
+import "fmt"
+var answer = 42
+var question = "?"
+func main() { hello(); world() }
+`
+	right := `package main
+import "fmt"
+var answer = 42
+var question = "?"
+func world() { fmt.Println("world!") }
+func main() { hello(); world() }
+func hello() { fmt.Println("hello") }
+func unused() {}
+`
+	leftLines, rightLines := ConvertToLinesWithoutNewLine(left), ConvertToLinesWithoutNewLine(right)
+	result, err := CompareSparse(left, right)
+	printErr(err)
+	var diff strings.Builder
+	err = WriteColorMovedDiff(&diff, leftLines, rightLines, result)
+	printErr(err)
+	// hello and world moved to different places, so they are different blocks. The escape
+	// character is replaced so that the colors can be read
+	fmt.Print(strings.ReplaceAll(diff.String(), "\x1b", `\e`))
+
+	// Output:
+	// \e[1m--- left\e[m
+	// \e[1m+++ right\e[m
+	// \e[36m@@ -1,8 +1,9 @@\e[m
+	//  package main
+	// \e[1;35m-func hello() { fmt.Println("hello") }\e[m
+	// \e[1;34m-func world() { fmt.Println("world") }\e[m
+	//  import "fmt"
+	//  var answer = 42
+	//  var question = "?"
+	// \e[1;36m+func world() { fmt.Println("world!") }\e[m
+	//  func main() { hello(); world() }
+	// \e[1;36m+func hello() { fmt.Println("hello") }\e[m
+	// \e[32m+func unused() {}\e[m
+}
@@ -0,0 +1,33 @@
+package lhdiff
+
+import (
+	"os"
+)
+
+func ExampleWriteSVG() {
+	left := `one
+two
+three
+four`
+	right := `one
+three
+two
+four x
+five`
+
+	result, err := Compare(left, right)
+	printErr(err)
+	err = WriteSVG(os.Stdout, result)
+	printErr(err)
+
+	// Output:
+	// <svg xmlns="http://www.w3.org/2000/svg" width="400" height="20" viewBox="0 0 400 20.00">
+	// <rect x="0" y="0" width="60" height="16.00" fill="#eeeeee"/>
+	// <rect x="340" y="0" width="60" height="20.00" fill="#eeeeee"/>
+	// <path d="M60 0.00 C200 0.00 200 0.00 340 0.00 V4.00 C200 4.00 200 4.00 60 4.00 Z" fill="hsl(120,70%,45%)" fill-opacity="0.6"/>
+	// <path d="M60 4.00 C200 4.00 200 8.00 340 8.00 V12.00 C200 12.00 200 8.00 60 8.00 Z" fill="hsl(120,70%,45%)" fill-opacity="0.6"/>
+	// <path d="M60 8.00 C200 8.00 200 4.00 340 4.00 V8.00 C200 8.00 200 12.00 60 12.00 Z" fill="hsl(120,70%,45%)" fill-opacity="0.6"/>
+	// <path d="M60 12.00 C200 12.00 200 12.00 340 12.00 V16.00 C200 16.00 200 16.00 60 16.00 Z" fill="hsl(30,70%,45%)" fill-opacity="0.6"/>
+	// <rect x="340" y="16.00" width="60" height="4.00" fill="#d62728"/>
+	// </svg>
+}
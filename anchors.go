@@ -0,0 +1,115 @@
+package lhdiff
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Anchor is a line marked with a lhdiff:anchor directive, such as
+//
+//	// lhdiff:anchor license-header
+//
+// in the comment syntax of any language. Left and Right are the zero-based lines with the
+// directive in the left and right file, and -1 if the other file doesn't have the anchor.
+type Anchor struct {
+	ID    string
+	Left  int
+	Right int
+}
+
+var /* const */ anchorDirective = regexp.MustCompile(`lhdiff:anchor\s+([\w.:-]+)`)
+
+// findAnchors returns the anchors of the lines of left and right, those of left first in left
+// line order, followed by those that are only in right. An id that is on more than one line of a
+// file, or on the same line as another id, is ambiguous, and ignored.
+func findAnchors(leftLines []string, rightLines []string) []Anchor {
+	lefts, leftIDs := anchorLines(leftLines)
+	rights, rightIDs := anchorLines(rightLines)
+	var anchors []Anchor
+	for _, id := range leftIDs {
+		right, ok := rights[id]
+		if !ok {
+			right = -1
+		}
+		anchors = append(anchors, Anchor{ID: id, Left: lefts[id], Right: right})
+	}
+	for _, id := range rightIDs {
+		if _, ok := lefts[id]; !ok {
+			anchors = append(anchors, Anchor{ID: id, Left: -1, Right: rights[id]})
+		}
+	}
+	return anchors
+}
+
+// anchorLines returns the line of each unambiguous id of lines, and the ids in line order.
+func anchorLines(lines []string) (map[string]int, []string) {
+	found := make(map[string][]int)
+	for i, line := range lines {
+		// Most lines don't have a directive, and the regexp is slower than looking for its start
+		if !strings.Contains(line, "lhdiff:anchor") {
+			continue
+		}
+		matches := anchorDirective.FindAllStringSubmatch(line, -1)
+		for _, match := range matches {
+			found[match[1]] = append(found[match[1]], i)
+			// A line can only be paired once, so ids sharing it are ambiguous like repeated ones
+			if len(matches) > 1 {
+				found[match[1]] = append(found[match[1]], i)
+			}
+		}
+	}
+	anchors := make(map[string]int)
+	var ids []string
+	for id, lineNumbers := range found {
+		if len(lineNumbers) == 1 {
+			anchors[id] = lineNumbers[0]
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return anchors[ids[i]] < anchors[ids[j]]
+	})
+	return anchors, ids
+}
+
+// WithoutAnchors makes Compare treat lhdiff:anchor directives like any other text, for files
+// whose directives aren't meant for lhdiff, such as its own documentation.
+func WithoutAnchors() Option {
+	return func(o *options) {
+		o.ignoreAnchors = true
+	}
+}
+
+// anchorPins returns the pins of the anchors that are in both files, except those that are
+// unchanged lines already, followed by the pins that don't pin an anchored line, since anchors
+// take priority.
+func anchorPins(anchors []Anchor, runs []Run, pins []Pin) []Pin {
+	var anchored []Pin
+	lefts, rights := make(map[int]bool), make(map[int]bool)
+	for _, anchor := range anchors {
+		if anchor.Left != -1 {
+			lefts[anchor.Left] = true
+		}
+		if anchor.Right != -1 {
+			rights[anchor.Right] = true
+		}
+		if anchor.Left != -1 && anchor.Right != -1 && !unchanged(runs, anchor.Left, anchor.Right) {
+			anchored = append(anchored, Pin{Left: anchor.Left, Right: anchor.Right})
+		}
+	}
+	for _, pin := range pins {
+		if !lefts[pin.Left] && !rights[pin.Right] {
+			anchored = append(anchored, pin)
+		}
+	}
+	return anchored
+}
+
+// unchanged returns true if the left and right lines are paired by one of the runs.
+func unchanged(runs []Run, left int, right int) bool {
+	i := sort.Search(len(runs), func(i int) bool {
+		return runs[i].Left+runs[i].Length > left
+	})
+	return i < len(runs) && runs[i].Left <= left && right-runs[i].Right == left-runs[i].Left
+}
@@ -0,0 +1,163 @@
+// Package avro writes line mappings as Avro object container files, which data pipelines such as
+// Spark and BigQuery can ingest directly. Only what lhdiff needs is implemented: records of
+// primitive fields and unions, written without compression.
+package avro
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"math"
+)
+
+// MappingSchema is the schema of the records written by WriteMappings. There is one record per
+// mapping, in the order of Result.Mappings. Line numbers are one-based.
+const MappingSchema = `{
+  "type": "record",
+  "name": "Mapping",
+  "namespace": "com.smartbear.lhdiff",
+  "doc": "A line of the left file mapped to a line of the right file.",
+  "fields": [
+    {"name": "left_line", "type": ["null", "long"], "doc": "One-based left line, or null if the right line was added."},
+    {"name": "right_line", "type": ["null", "long"], "doc": "One-based right line, or null if the left line was deleted."},
+    {"name": "similarity", "type": "double", "doc": "Similarity of the lines, 1 if unchanged and 0 if one of them is null."}
+  ]
+}`
+
+// blockSize is the number of records written per block.
+const blockSize = 1000
+
+var /* const */ magic = []byte{'O', 'b', 'j', 1}
+
+// Writer writes records to an object container file. The records must be encoded with the
+// schema the Writer was created with.
+type Writer struct {
+	w     io.Writer
+	sync  [16]byte
+	block Encoder
+	count int
+}
+
+// NewWriter writes the header of an object container file with schema to w.
+func NewWriter(w io.Writer, schema string) (*Writer, error) {
+	writer := &Writer{w: w}
+	if _, err := rand.Read(writer.sync[:]); err != nil {
+		return nil, err
+	}
+	header := &Encoder{}
+	header.buf.Write(magic)
+	header.Long(2)
+	header.String("avro.schema")
+	header.String(schema)
+	header.String("avro.codec")
+	header.String("null")
+	header.Long(0)
+	header.buf.Write(writer.sync[:])
+	if _, err := w.Write(header.buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Append appends a record, whose fields are encoded by encode in the order of the schema.
+func (writer *Writer) Append(encode func(*Encoder)) error {
+	encode(&writer.block)
+	writer.count++
+	if writer.count == blockSize {
+		return writer.Flush()
+	}
+	return nil
+}
+
+// Flush writes the records appended since the last flush as a block.
+func (writer *Writer) Flush() error {
+	if writer.count == 0 {
+		return nil
+	}
+	header := &Encoder{}
+	header.Long(int64(writer.count))
+	header.Long(int64(writer.block.buf.Len()))
+	for _, data := range [][]byte{header.buf.Bytes(), writer.block.buf.Bytes(), writer.sync[:]} {
+		if _, err := writer.w.Write(data); err != nil {
+			return err
+		}
+	}
+	writer.block.buf.Reset()
+	writer.count = 0
+	return nil
+}
+
+// Close flushes the records that haven't been written yet. It doesn't close the underlying writer.
+func (writer *Writer) Close() error {
+	return writer.Flush()
+}
+
+// Encoder encodes values in the Avro binary encoding.
+type Encoder struct {
+	buf bytes.Buffer
+}
+
+// Long encodes an int or a long.
+func (encoder *Encoder) Long(value int64) {
+	var data [binary.MaxVarintLen64]byte
+	encoder.buf.Write(data[:binary.PutVarint(data[:], value)])
+}
+
+// Double encodes a double.
+func (encoder *Encoder) Double(value float64) {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], math.Float64bits(value))
+	encoder.buf.Write(data[:])
+}
+
+// String encodes a string.
+func (encoder *Encoder) String(value string) {
+	encoder.Long(int64(len(value)))
+	encoder.buf.WriteString(value)
+}
+
+// Boolean encodes a boolean.
+func (encoder *Encoder) Boolean(value bool) {
+	if value {
+		encoder.buf.WriteByte(1)
+	} else {
+		encoder.buf.WriteByte(0)
+	}
+}
+
+// Union encodes the branch of a union that the next value is of. Nothing follows a null branch.
+func (encoder *Encoder) Union(branch int) {
+	encoder.Long(int64(branch))
+}
+
+// optionalLine encodes a zero-based line number, or -1, as a one-based ["null", "long"] union.
+func (encoder *Encoder) optionalLine(line int) {
+	if line == -1 {
+		encoder.Union(0)
+		return
+	}
+	encoder.Union(1)
+	encoder.Long(int64(line) + 1)
+}
+
+// WriteMappings writes the mappings of result to w as an object container file with MappingSchema.
+func WriteMappings(w io.Writer, result *lhdiff.Result) error {
+	writer, err := NewWriter(w, MappingSchema)
+	if err != nil {
+		return err
+	}
+	for _, mapping := range result.Mappings {
+		mapping := mapping
+		err := writer.Append(func(encoder *Encoder) {
+			encoder.optionalLine(mapping.Left)
+			encoder.optionalLine(mapping.Right)
+			encoder.Double(mapping.Similarity)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
@@ -0,0 +1,107 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"math"
+)
+
+func ExampleWriteMappings() {
+	left := `one two three four
+eight
+nine ten eleven twelve
+thirteen fourteen fifteen`
+
+	right := `one two three four
+nine ten twelve
+five six BANANA seven eight
+APPLE PEAR
+thirteen fourteen fifteen`
+
+	result, err := lhdiff.Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteMappings(&buf, result); err != nil {
+		panic(err)
+	}
+
+	container := newDecoder(buf.Bytes())
+	fmt.Println(container.header())
+	count := container.long()
+	container.long()
+	for i := int64(0); i < count; i++ {
+		fmt.Println(container.optionalLong(), container.optionalLong(), container.double())
+	}
+	fmt.Println(container.trailer())
+
+	// Output:
+	// true null true
+	// 1 1 1
+	// 2 <nil> 0
+	// 3 2 0.5740687026357825
+	// 4 5 1
+	// <nil> 3 0
+	// <nil> 4 0
+	// true
+}
+
+// decoder reads the parts of an object container file written by WriteMappings.
+type decoder struct {
+	data []byte
+	sync []byte
+}
+
+func newDecoder(data []byte) *decoder {
+	return &decoder{data: data}
+}
+
+// header reads the header and the sync marker, and returns whether the magic is right, the codec
+// and whether the schema is MappingSchema.
+func (d *decoder) header() (bool, string, bool) {
+	magicOk := bytes.Equal(d.data[:4], magic)
+	d.data = d.data[4:]
+	metadata := map[string]string{}
+	for count := d.long(); count != 0; count = d.long() {
+		for i := int64(0); i < count; i++ {
+			metadata[d.string()] = d.string()
+		}
+	}
+	d.sync = d.data[:16]
+	d.data = d.data[16:]
+	return magicOk, metadata["avro.codec"], metadata["avro.schema"] == MappingSchema
+}
+
+// trailer returns whether the block ends with the sync marker and nothing follows it.
+func (d *decoder) trailer() bool {
+	return bytes.Equal(d.data, d.sync)
+}
+
+func (d *decoder) long() int64 {
+	value, n := binary.Varint(d.data)
+	d.data = d.data[n:]
+	return value
+}
+
+func (d *decoder) optionalLong() interface{} {
+	if d.long() == 0 {
+		return nil
+	}
+	return d.long()
+}
+
+func (d *decoder) double() float64 {
+	value := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+	d.data = d.data[8:]
+	return value
+}
+
+func (d *decoder) string() string {
+	length := d.long()
+	value := string(d.data[:length])
+	d.data = d.data[length:]
+	return value
+}
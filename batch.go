@@ -0,0 +1,86 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// FileResult is the comparison of the old and new versions of a file in a batch. Result is
+// nil if the file doesn't exist in the new snapshot, or if Err is set.
+type FileResult struct {
+	Path   string
+	Result *Result
+	Err    error
+}
+
+// CompareFiles compares the old and new versions of each of paths concurrently, and sends the
+// result of each file on the returned channel as soon as it is done, so that results can be
+// written or uploaded while the rest of the batch is being compared. Results are therefore
+// not in the order of paths. The channel is closed when all the files have been compared.
+// The snapshots must be safe for concurrent use, as DirSnapshot and MapSnapshot are.
+func CompareFiles(old Snapshot, new Snapshot, paths []string, opts ...Option) <-chan FileResult {
+	results := make(chan FileResult)
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				results <- compareFile(old, new, path, opts)
+			}
+		}()
+	}
+	go func() {
+		for _, path := range paths {
+			jobs <- path
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func compareFile(old Snapshot, new Snapshot, path string, opts []Option) FileResult {
+	left, err := old.ReadFile(path)
+	if err != nil {
+		return FileResult{Path: path, Err: err}
+	}
+	right, err := new.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return FileResult{Path: path}
+	}
+	if err != nil {
+		return FileResult{Path: path, Err: err}
+	}
+	if err := checkText(path, left, right); err != nil {
+		return FileResult{Path: path, Err: err}
+	}
+	result, err := Compare(left, right, append([]Option{WithPath(path)}, opts...)...)
+	return FileResult{Path: path, Result: result, Err: err}
+}
+
+// ShardPaths returns the paths of shard (one-based) of shards, so that a large batch can be
+// compared by several processes or machines, each given the same paths. A path is in the same
+// shard whatever the order of the paths and whichever machine shards them, since shards are
+// given by the hashes of the paths, and each path is in exactly one shard. It returns an error
+// unless 1 <= shard <= shards.
+func ShardPaths(paths []string, shard int, shards int) ([]string, error) {
+	if shard < 1 || shard > shards {
+		return nil, fmt.Errorf("invalid shard %d/%d: a shard is i/n, with 1 <= i <= n", shard, shards)
+	}
+	var sharded []string
+	for _, path := range paths {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(path))
+		if int(hash.Sum32()%uint32(shards)) == shard-1 {
+			sharded = append(sharded, path)
+		}
+	}
+	return sharded, nil
+}
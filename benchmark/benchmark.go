@@ -0,0 +1,241 @@
+// Package benchmark reads and writes datasets of pairs of files along with the mapping of their
+// lines that is known to be correct, and scores lhdiff against them with the measures of the
+// LHDiff paper.
+//
+// A dataset is a directory with a directory for each case, holding the two versions of a file,
+// named left and right followed by the extension of the file (such as left.java), and the
+// correct mapping in truth.txt. The mapping is in the format printed by the mapping command:
+// one-based left,right pairs of lines, one per line, with _ for a line that has no counterpart.
+// Blank lines and # comments are ignored, and the left lines that aren't listed aren't scored,
+// so that a truth can cover only some of the lines.
+//
+// This is the only format that is read and written. Reading the mutation benchmark of the
+// LHDiff paper in its own format is a follow-up; until then, its cases must be converted.
+package benchmark
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TruthFile is the name of the file with the correct mapping of a case.
+const TruthFile = "truth.txt"
+
+// Case is a pair of files and the correct mapping of their lines, with zero-based line numbers
+// and -1 for lines without a counterpart as in lhdiff.LineMapping. Similarities are ignored.
+type Case struct {
+	Name string
+	// Extension is the extension of the files, including the dot, or empty
+	Extension string
+	Left      string
+	Right     string
+	Truth     []lhdiff.LineMapping
+}
+
+// ReadTruth reads a mapping in the format of the mapping command.
+func ReadTruth(r io.Reader) ([]lhdiff.LineMapping, error) {
+	var truth []lhdiff.LineMapping
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%d: expected left,right: %s", lineNumber, line)
+		}
+		left, leftErr := parseLine(fields[0])
+		right, rightErr := parseLine(fields[1])
+		if leftErr != nil || rightErr != nil || (left == -1 && right == -1) {
+			return nil, fmt.Errorf("%d: expected left,right: %s", lineNumber, line)
+		}
+		truth = append(truth, lhdiff.LineMapping{Left: left, Right: right})
+	}
+	return truth, scanner.Err()
+}
+
+func parseLine(field string) (int, error) {
+	field = strings.TrimSpace(field)
+	if field == "_" {
+		return -1, nil
+	}
+	line, err := strconv.Atoi(field)
+	if err == nil && line < 1 {
+		err = fmt.Errorf("invalid line: %d", line)
+	}
+	return line - 1, err
+}
+
+// WriteTruth writes a mapping in the format of the mapping command.
+func WriteTruth(w io.Writer, truth []lhdiff.LineMapping) error {
+	for _, mapping := range truth {
+		if _, err := fmt.Fprintf(w, "%s,%s\n", lineString(mapping.Left), lineString(mapping.Right)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lineString(line int) string {
+	if line == -1 {
+		return "_"
+	}
+	return strconv.Itoa(line + 1)
+}
+
+// ReadDataset reads the cases of the dataset in dir, in name order.
+func ReadDataset(dir string) ([]Case, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var cases []Case
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		c, err := ReadCase(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].Name < cases[j].Name
+	})
+	return cases, nil
+}
+
+// ReadCase reads the case in dir, which is named after dir.
+func ReadCase(dir string) (Case, error) {
+	c := Case{Name: filepath.Base(dir)}
+	lefts, err := filepath.Glob(filepath.Join(dir, "left*"))
+	if err != nil {
+		return c, err
+	}
+	rights, err := filepath.Glob(filepath.Join(dir, "right*"))
+	if err != nil {
+		return c, err
+	}
+	if len(lefts) != 1 || len(rights) != 1 {
+		return c, fmt.Errorf("%s: expected a left and a right file", dir)
+	}
+	c.Extension = strings.TrimPrefix(filepath.Base(lefts[0]), "left")
+	left, err := ioutil.ReadFile(lefts[0])
+	if err != nil {
+		return c, err
+	}
+	right, err := ioutil.ReadFile(rights[0])
+	if err != nil {
+		return c, err
+	}
+	c.Left, c.Right = string(left), string(right)
+	f, err := os.Open(filepath.Join(dir, TruthFile))
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+	if c.Truth, err = ReadTruth(f); err != nil {
+		return c, fmt.Errorf("%s:%w", f.Name(), err)
+	}
+	return c, nil
+}
+
+// WriteCase writes a case to a directory of dir named after the case.
+func WriteCase(dir string, c Case) error {
+	caseDir := filepath.Join(dir, c.Name)
+	if err := os.MkdirAll(caseDir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(caseDir, "left"+c.Extension), []byte(c.Left), 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(caseDir, "right"+c.Extension), []byte(c.Right), 0644); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(caseDir, TruthFile))
+	if err != nil {
+		return err
+	}
+	err = WriteTruth(f, c.Truth)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Score counts the left lines of the truth of a case by what lhdiff did with them, with the
+// measures of the LHDiff paper.
+type Score struct {
+	// Correct lines are mapped to the right line of the truth, or deleted in both
+	Correct int
+	// Changed lines are mapped to another right line than the one of the truth
+	Changed int
+	// Spurious lines are mapped although the truth has them deleted
+	Spurious int
+	// Eliminated lines are deleted although the truth maps them
+	Eliminated int
+}
+
+// Lines returns the number of lines that were scored.
+func (score Score) Lines() int {
+	return score.Correct + score.Changed + score.Spurious + score.Eliminated
+}
+
+// Accuracy returns the fraction of the lines that are correct. It is 1 if there are none.
+func (score Score) Accuracy() float64 {
+	if score.Lines() == 0 {
+		return 1
+	}
+	return float64(score.Correct) / float64(score.Lines())
+}
+
+// Add adds the counts of other to score.
+func (score *Score) Add(other Score) {
+	score.Correct += other.Correct
+	score.Changed += other.Changed
+	score.Spurious += other.Spurious
+	score.Eliminated += other.Eliminated
+}
+
+func (score Score) String() string {
+	return fmt.Sprintf("%d correct, %d changed, %d spurious, %d eliminated", score.Correct, score.Changed, score.Spurious, score.Eliminated)
+}
+
+// Evaluate compares the files of a case with opts, and scores the result against the truth.
+func Evaluate(c Case, opts ...lhdiff.Option) (Score, error) {
+	var score Score
+	result, err := lhdiff.Compare(c.Left, c.Right, append([]lhdiff.Option{lhdiff.WithPath("right" + c.Extension)}, opts...)...)
+	if err != nil {
+		return score, err
+	}
+	for _, mapping := range c.Truth {
+		if mapping.Left == -1 {
+			continue
+		}
+		right, _, ok := result.RightLine(mapping.Left)
+		if !ok {
+			right = -1
+		}
+		switch {
+		case right == mapping.Right:
+			score.Correct++
+		case mapping.Right == -1:
+			score.Spurious++
+		case right == -1:
+			score.Eliminated++
+		default:
+			score.Changed++
+		}
+	}
+	return score, nil
+}
@@ -0,0 +1,70 @@
+package benchmark
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func ExampleEvaluate() {
+	dir, err := ioutil.TempDir("", "benchmark")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	truth, err := ReadTruth(strings.NewReader(`# the second line was rewritten
+1,1
+2,3
+3,2
+_,4
+`))
+	if err != nil {
+		panic(err)
+	}
+	err = WriteCase(dir, Case{
+		Name:      "rewrite",
+		Extension: ".txt",
+		Left:      "one two three four\neight\nnine ten eleven twelve\n",
+		Right:     "one two three four\nnine ten twelve\neight!\nAPPLE PEAR\n",
+		Truth:     truth,
+	})
+	if err != nil {
+		panic(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "rewrite", "*"))
+	for _, file := range files {
+		fmt.Println(filepath.Base(file))
+	}
+
+	cases, err := ReadDataset(dir)
+	if err != nil {
+		panic(err)
+	}
+	for _, opts := range [][]lhdiff.Option{nil, {lhdiff.WithContentGate(0.9)}} {
+		score, err := Evaluate(cases[0], opts...)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: %s, %.2f accurate\n", cases[0].Name, score, score.Accuracy())
+	}
+	_ = WriteTruth(os.Stdout, cases[0].Truth)
+
+	_, err = ReadTruth(strings.NewReader("1,2,3\n"))
+	fmt.Println(err)
+
+	// Output:
+	// left.txt
+	// right.txt
+	// truth.txt
+	// rewrite: 3 correct, 0 changed, 0 spurious, 0 eliminated, 1.00 accurate
+	// rewrite: 1 correct, 0 changed, 0 spurious, 2 eliminated, 0.33 accurate
+	// 1,1
+	// 2,3
+	// 3,2
+	// _,4
+	// 1: expected left,right: 1,2,3
+}
@@ -0,0 +1,32 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrBinaryFile is returned by the functions comparing the files of snapshots, such as
+// CompareFiles and Remapper.Result, for files whose lines can't be tracked because they are binary.
+var ErrBinaryFile = errors.New("binary file")
+
+// binarySniffLength is the length of the start of a file that is searched for NUL bytes, like git
+// does to tell binary files from text files.
+const binarySniffLength = 8000
+
+// checkText returns an error wrapping ErrBinaryFile if left or right is binary.
+func checkText(path string, left string, right string) error {
+	if IsBinary(left) || IsBinary(right) {
+		return fmt.Errorf("%s: %w", path, ErrBinaryFile)
+	}
+	return nil
+}
+
+// IsBinary returns true if content is binary rather than text: if its start has a NUL byte, as
+// git decides.
+func IsBinary(content string) bool {
+	if len(content) > binarySniffLength {
+		content = content[:binarySniffLength]
+	}
+	return strings.IndexByte(content, 0) != -1
+}
@@ -0,0 +1,44 @@
+package lhdiff
+
+// Breakpoint is a source breakpoint as used by the Debug Adapter Protocol. Line and Column
+// are one-based, and a Column of 0 means the breakpoint applies to the whole line.
+type Breakpoint struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+// BreakpointRemapping is the result of remapping a breakpoint to a new snapshot.
+type BreakpointRemapping struct {
+	Old Breakpoint
+	// New is the remapped breakpoint. It is the zero Breakpoint if Deleted is true.
+	New        Breakpoint
+	Deleted    bool
+	Similarity float64
+}
+
+// RemapBreakpoints remaps breakpoints set against the old snapshot to the new snapshot, for
+// example after a hot reload. Breakpoints on deleted lines (or in deleted files) are flagged
+// as Deleted. The column of a breakpoint is only kept if its line is unchanged, since it is
+// unlikely to be meaningful on a modified line.
+func RemapBreakpoints(old Snapshot, new Snapshot, breakpoints []Breakpoint, opts ...Option) ([]BreakpointRemapping, error) {
+	remapper := NewRemapper(old, new, opts...)
+	remappings := make([]BreakpointRemapping, len(breakpoints))
+	for i, breakpoint := range breakpoints {
+		line, similarity, ok, err := remapper.Remap(breakpoint.Path, breakpoint.Line-1)
+		if err != nil {
+			return nil, err
+		}
+		remappings[i] = BreakpointRemapping{Old: breakpoint, Deleted: !ok}
+		if !ok {
+			continue
+		}
+		column := 0
+		if similarity == 1 {
+			column = breakpoint.Column
+		}
+		remappings[i].New = Breakpoint{Path: breakpoint.Path, Line: line + 1, Column: column}
+		remappings[i].Similarity = similarity
+	}
+	return remappings, nil
+}
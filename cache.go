@@ -0,0 +1,213 @@
+package lhdiff
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// cacheVersion is part of every cache key. It must be incremented whenever a change to the
+// algorithm changes the results, so that results cached by older versions aren't used.
+const cacheVersion = 6
+
+// Cache stores the results of Compare, keyed by the hashes of the compared files and of the
+// options that affect the result (see WithCache).
+type Cache interface {
+	// Get returns the result stored under key. The boolean is false if there is none.
+	Get(key string) (*Result, bool)
+	// Put stores result under key. Failing to store a result isn't an error, since it
+	// will simply be computed again.
+	Put(key string, result *Result)
+}
+
+// DirCache is a Cache storing each result as a JSON file below a directory. It can be shared
+// by several processes, such as the jobs of a CI pipeline.
+type DirCache string
+
+func (dir DirCache) Get(key string) (*Result, bool) {
+	data, err := ioutil.ReadFile(dir.path(key))
+	if err != nil {
+		return nil, false
+	}
+	result := &Result{}
+	if err := json.Unmarshal(data, result); err != nil || !result.consistent() {
+		return nil, false
+	}
+	return result, true
+}
+
+// consistent returns true if the mappings of a result read back from a cache are those of
+// LeftLineCount left lines and RightLineCount right lines, as Compare returns them, so that a
+// corrupted entry is recomputed rather than indexed out of range.
+func (result *Result) consistent() bool {
+	if result.LeftLineCount < 0 || result.RightLineCount < 0 || len(result.Mappings) < result.LeftLineCount {
+		return false
+	}
+	for i, mapping := range result.Mappings {
+		left := -1
+		if i < result.LeftLineCount {
+			left = i
+		}
+		if mapping.Left != left || mapping.Right < -1 || mapping.Right >= result.RightLineCount {
+			return false
+		}
+	}
+	for _, mapping := range result.Moved {
+		if mapping.Left < 0 || mapping.Left >= result.LeftLineCount || mapping.Right < 0 || mapping.Right >= result.RightLineCount {
+			return false
+		}
+	}
+	for _, anchor := range result.Anchors {
+		if anchor.Left < -1 || anchor.Left >= result.LeftLineCount || anchor.Right < -1 || anchor.Right >= result.RightLineCount {
+			return false
+		}
+	}
+	return true
+}
+
+func (dir DirCache) Put(key string, result *Result) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	path := dir.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first, so that concurrent readers never see a partial result
+	f, err := ioutil.TempFile(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+func (dir DirCache) path(key string) string {
+	return filepath.Join(string(dir), key[:2], key+".json")
+}
+
+// MemoryCache is a Cache holding the most recently used results in memory. It is safe for
+// concurrent use, so that the clients of a long-running process can share it.
+type MemoryCache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// recent has the keys of the entries, most recently used first
+	recent *list.List
+}
+
+type memoryEntry struct {
+	key    string
+	result *Result
+}
+
+// NewMemoryCache returns a MemoryCache holding at most size results.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{size: size, entries: make(map[string]*list.Element), recent: list.New()}
+}
+
+func (cache *MemoryCache) Get(key string) (*Result, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	cache.recent.MoveToFront(element)
+	return element.Value.(*memoryEntry).result.copy(), true
+}
+
+func (cache *MemoryCache) Put(key string, result *Result) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		element.Value.(*memoryEntry).result = result.copy()
+		cache.recent.MoveToFront(element)
+		return
+	}
+	cache.entries[key] = cache.recent.PushFront(&memoryEntry{key: key, result: result.copy()})
+	for cache.recent.Len() > cache.size {
+		oldest := cache.recent.Back()
+		cache.recent.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// copy returns a copy of the result, so that the results of a MemoryCache aren't modified
+// through the results of Compare.
+func (result *Result) copy() *Result {
+	copied := *result
+	copied.Mappings = append([]LineMapping(nil), result.Mappings...)
+	if result.Moved != nil {
+		copied.Moved = append([]LineMapping(nil), result.Moved...)
+	}
+	if result.Anchors != nil {
+		copied.Anchors = append([]Anchor(nil), result.Anchors...)
+	}
+	return &copied
+}
+
+// OptionsKey returns a key of the options that affect the results of Compare, including those
+// of the profiles of WithProfiles, and of the version of the algorithm, so that results stored
+// by other means than a Cache, such as the genealogy database of the repo package, can be told
+// apart from results that other options would give.
+func OptionsKey(opts ...Option) string {
+	o := newOptions(opts)
+	extensions := make([]string, 0, len(o.profiles))
+	for extension := range o.profiles {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+	key := fmt.Sprintf("v%d %s", cacheVersion, o.key())
+	for _, extension := range extensions {
+		profiled := newOptions(append(append([]Option(nil), opts...), WithPath("file"+extension)))
+		key += fmt.Sprintf(" %s={%s}", extension, profiled.key())
+	}
+	optionsHash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(optionsHash[:])
+}
+
+// cacheKey combines the hashes of left, right and the options into a single key.
+func cacheKey(left string, right string, o *options) string {
+	leftHash := sha256.Sum256([]byte(left))
+	rightHash := sha256.Sum256([]byte(right))
+	return combinedCacheKey(leftHash[:], rightHash[:], fmt.Sprintf("v%d %s", cacheVersion, o.key()))
+}
+
+// linesCacheKey is like cacheKey for normalized lines. Lines hash like the text they were split
+// from, which may have one more line (see ConvertToLinesWithoutNewLine), so the options are
+// hashed differently for the keys of lines and of texts not to collide.
+func linesCacheKey(leftLines []string, rightLines []string, o *options) string {
+	return combinedCacheKey(hashLines(leftLines), hashLines(rightLines), fmt.Sprintf("v%d lines %s", cacheVersion, o.key()))
+}
+
+func hashLines(lines []string) []byte {
+	hash := sha256.New()
+	for _, line := range lines {
+		_, _ = io.WriteString(hash, line)
+	}
+	return hash.Sum(nil)
+}
+
+func combinedCacheKey(leftHash []byte, rightHash []byte, options string) string {
+	optionsHash := sha256.Sum256([]byte(options))
+	key := sha256.Sum256(append(append(append([]byte(nil), leftHash...), rightHash...), optionsHash[:]...))
+	return hex.EncodeToString(key[:])
+}
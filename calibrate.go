@@ -0,0 +1,38 @@
+package lhdiff
+
+import (
+	"sort"
+)
+
+// The range that a calibrated threshold is clamped to. Below the minimum, lines sharing little
+// more than a few characters would be paired; above the maximum, plain edits would be missed.
+const minCalibratedThreshold = 0.35
+const maxCalibratedThreshold = 0.6
+
+// minCalibrationSamples is the number of scores needed to calibrate the threshold.
+const minCalibrationSamples = 3
+
+// noiseQuantile is the quantile of the scores of unrelated pairs that the threshold is set to.
+const noiseQuantile = 0.9
+
+// calibrateThreshold picks a similarity threshold from the similarities of the second most
+// similar candidate of each added line. Those candidates weren't chosen, so their scores show
+// how similar unrelated lines of the compared files are: they are high in verbose files, where
+// lines share a lot of boilerplate, and low in terse files such as configuration files. The
+// threshold is set just above most of them, so that pairs are only made when they are more
+// similar than the noise. SimilarityThreshold is returned when there are too few scores.
+func calibrateThreshold(runnerUpSimilarities []float64) float64 {
+	if len(runnerUpSimilarities) < minCalibrationSamples {
+		return SimilarityThreshold
+	}
+	scores := append([]float64(nil), runnerUpSimilarities...)
+	sort.Float64s(scores)
+	threshold := scores[int(noiseQuantile*float64(len(scores)-1))]
+	if threshold < minCalibratedThreshold {
+		return minCalibratedThreshold
+	}
+	if threshold > maxCalibratedThreshold {
+		return maxCalibratedThreshold
+	}
+	return threshold
+}
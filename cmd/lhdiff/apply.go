@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+)
+
+func newApplyCommand() *command {
+	cmd := &command{
+		name:    "apply",
+		usage:   "apply [options] -mapping file [-locations file]",
+		summary: "Remap path:line locations with mappings written by -format json.",
+		flags:   flag.NewFlagSet("apply", flag.ExitOnError),
+	}
+	var mappings []string
+	cmd.flags.Func("mapping", "JSON file of the mappings of files, as written by -format json (may be repeated)", func(path string) error {
+		mappings = append(mappings, path)
+		return nil
+	})
+	locations := cmd.flags.String("locations", "", "File of path:line[:text] locations to remap (default stdin)")
+	output := cmd.flags.String("o", "", "Write the remapped locations to this file instead of stdout")
+	cmd.run = func(args []string) error {
+		if len(mappings) == 0 || len(args) > 0 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		files := make(fileMappings)
+		for _, path := range mappings {
+			if err := files.read(path); err != nil {
+				return err
+			}
+		}
+		in := os.Stdin
+		if *locations != "" {
+			f, err := os.Open(*locations)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		out := os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return applyMappings(in, out, os.Stderr, files)
+	}
+	return cmd
+}
+
+// fileMapping is the mapping of a file in a mapping file. Path is the path of the file in the
+// new version, if it was renamed. A file that was deleted has no mapping (null). Delta-encoded
+// mappings are in Delta instead of Result, which read expands them into.
+type fileMapping struct {
+	Path string `json:",omitempty"`
+	*lhdiff.Result
+	Delta *lhdiff.Delta `json:",omitempty"`
+}
+
+// fileMappings are the mappings of files, keyed by the paths that locations refer to: the path
+// of the right file with -format json, since the left one is often a temporary file, such as
+// that of git show, and the paths of the files in both snapshots with batch.
+type fileMappings map[string]*fileMapping
+
+func (files fileMappings) read(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var read fileMappings
+	if err := json.Unmarshal(data, &read); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for file, mapping := range read {
+		if mapping != nil && mapping.Delta != nil {
+			mapping.Result, mapping.Delta = mapping.Delta.Expand(), nil
+		}
+		if mapping != nil && mapping.Result == nil {
+			return fmt.Errorf("%s: the mapping of %s has no Mappings", path, file)
+		}
+		files[file] = mapping
+	}
+	return nil
+}
+
+// remap maps a one-based line of the old version of path to the new version. Files without a
+// mapping are taken to be unchanged.
+func (files fileMappings) remap(path string, line int) (string, int, bool) {
+	mapping, ok := files[path]
+	if !ok {
+		return path, line, true
+	}
+	if mapping == nil {
+		return "", 0, false
+	}
+	if mapping.Path != "" {
+		path = mapping.Path
+	}
+	// The left lines of the pairs of Moved are deleted in Mappings
+	for _, moved := range mapping.Moved {
+		if moved.Left == line-1 {
+			return path, moved.Right + 1, true
+		}
+	}
+	right, _, ok := mapping.RightLine(line - 1)
+	return path, right + 1, ok
+}
+
+// applyMappings remaps the path:line[:text] locations read from in, and writes them to out. The
+// locations on deleted lines or in deleted files are orphans, written to report as they are.
+// Lines that aren't locations are kept as-is.
+func applyMappings(in io.Reader, out io.Writer, report io.Writer, files fileMappings) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := linesBookmark.FindStringSubmatch(line); match != nil {
+			lineNumber, _ := strconv.Atoi(match[2])
+			path, newLine, ok := files.remap(match[1], lineNumber)
+			if !ok {
+				_, _ = fmt.Fprintln(report, line)
+				continue
+			}
+			line = fmt.Sprintf("%s:%d%s", path, newLine, match[3])
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/objectstore"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func newBatchCommand() *command {
+	cmd := &command{
+		name:    "batch",
+		usage:   "batch [options] -old dir|ref -new dir|ref [-o dir|ref] [-shard i/n] [path...] | -merge file...",
+		summary: "Compare the files of two snapshots, in directories or below s3:// or gs:// references, and write their mappings.",
+		flags:   flag.NewFlagSet("batch", flag.ExitOnError),
+	}
+	oldLocation := cmd.flags.String("old", "", "Directory or s3:// or gs:// reference of the old snapshot")
+	newLocation := cmd.flags.String("new", "", "Directory or s3:// or gs:// reference of the new snapshot")
+	output := cmd.flags.String("o", "", "Write the mapping of each file to <path>.json below this directory or s3:// or gs:// reference, instead of all of them to stdout")
+	shard, shards := 1, 1
+	cmd.flags.Func("shard", "Only compare the paths of shard i of n, such as 3/16, so that n jobs given the same paths compare each of them once", func(value string) (err error) {
+		shard, shards, err = parseShard(value)
+		return err
+	})
+	delta := cmd.flags.Bool("delta", false, "Write the mappings delta-encoded, with only the mappings that differ from the identity")
+	merge := cmd.flags.Bool("merge", false, "Merge the mappings written to stdout by the shards of a batch, in the files given as arguments")
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if *merge {
+			return mergeShards(args)
+		}
+		if *oldLocation == "" || *newLocation == "" {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		paths := args
+		if len(paths) == 0 {
+			// The paths are read from stdin, such as the output of git diff --name-only
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				if path := scanner.Text(); path != "" {
+					paths = append(paths, path)
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+		}
+		paths, err := lhdiff.ShardPaths(paths, shard, shards)
+		if err != nil {
+			return err
+		}
+		files := make(fileMappings)
+		failed := 0
+		for fileResult := range lhdiff.CompareFiles(openSnapshot(*oldLocation), openSnapshot(*newLocation), paths, opts()...) {
+			if fileResult.Err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", fileResult.Path, fileResult.Err)
+				failed++
+				continue
+			}
+			var mapping *fileMapping
+			if fileResult.Result != nil {
+				mapping = &fileMapping{Result: fileResult.Result}
+				if *delta {
+					mapping = &fileMapping{Delta: fileResult.Result.Delta()}
+				}
+			}
+			if *output == "" {
+				files[fileResult.Path] = mapping
+				continue
+			}
+			// Each mapping file can be used with apply -mapping on its own
+			data, err := json.Marshal(fileMappings{fileResult.Path: mapping})
+			if err != nil {
+				return err
+			}
+			if err := writeLocation(*output, fileResult.Path+".json", data); err != nil {
+				return err
+			}
+		}
+		if *output == "" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(files); err != nil {
+				return err
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d file(s) couldn't be compared", failed, len(paths))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// parseShard parses a shard of the form i/n.
+func parseShard(value string) (int, int, error) {
+	var shard, shards int
+	if _, err := fmt.Sscanf(value, "%d/%d", &shard, &shards); err != nil || fmt.Sprintf("%d/%d", shard, shards) != value || shard < 1 || shard > shards {
+		return 0, 0, fmt.Errorf("a shard is i/n, with 1 <= i <= n: %s", value)
+	}
+	return shard, shards, nil
+}
+
+// mergeShards writes the mappings of the files written by the shards of a batch to stdout, as
+// a single batch would have. Since shards are disjoint, a file in several of them means they
+// aren't the shards of one batch. Mappings are copied as they are, so delta-encoded mappings
+// stay so.
+func mergeShards(paths []string) error {
+	files := make(map[string]json.RawMessage)
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var shard map[string]json.RawMessage
+		if err := json.Unmarshal(data, &shard); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for file, mapping := range shard {
+			if _, exists := files[file]; exists {
+				return fmt.Errorf("%s: %s is in another shard too", path, file)
+			}
+			files[file] = mapping
+		}
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(files)
+}
+
+// openSnapshot returns the snapshot of a directory, or of the objects below an s3:// or gs://
+// reference.
+func openSnapshot(location string) lhdiff.Snapshot {
+	if objectstore.IsReference(location) {
+		return objectstore.Snapshot{Prefix: location}
+	}
+	return lhdiff.DirSnapshot(location)
+}
+
+// writeLocation writes data to path below a directory, or below an s3:// or gs:// reference.
+func writeLocation(location string, path string, data []byte) error {
+	if objectstore.IsReference(location) {
+		return objectstore.Put(objectstore.Join(location, path), data)
+	}
+	path = filepath.Join(location, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
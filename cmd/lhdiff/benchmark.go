@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff/benchmark"
+	"os"
+)
+
+func newBenchmarkCommand() *command {
+	cmd := &command{
+		name:    "benchmark",
+		usage:   "benchmark [options] dir",
+		summary: "Score the mappings of the cases of a dataset against their correct mappings.",
+		flags:   flag.NewFlagSet("benchmark", flag.ExitOnError),
+	}
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		cases, err := benchmark.ReadDataset(args[0])
+		if err != nil {
+			return err
+		}
+		var total benchmark.Score
+		_, _ = fmt.Println("accuracy\tcorrect\tchanged\tspurious\teliminated\tcase")
+		for _, c := range cases {
+			score, err := benchmark.Evaluate(c, opts()...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", c.Name, err)
+			}
+			total.Add(score)
+			if err := printScore(score, c.Name); err != nil {
+				return err
+			}
+		}
+		return printScore(total, "total")
+	}
+	return cmd
+}
+
+func printScore(score benchmark.Score, name string) error {
+	_, err := fmt.Printf("%.1f%%\t%d\t%d\t%d\t%d\t%s\n", 100*score.Accuracy(), score.Correct, score.Changed, score.Spurious, score.Eliminated, name)
+	return err
+}
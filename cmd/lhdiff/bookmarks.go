@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func newBookmarksCommand() *command {
+	cmd := &command{
+		name:    "bookmarks",
+		usage:   "bookmarks [options] -old dir -new dir [file]",
+		summary: "Remap a bookmarks file from an old source tree to a new one.",
+		flags:   flag.NewFlagSet("bookmarks", flag.ExitOnError),
+	}
+	oldDir := cmd.flags.String("old", "", "Directory with the old version of the source tree")
+	newDir := cmd.flags.String("new", "", "Directory with the new version of the source tree")
+	format := cmd.flags.String("format", "lines", "Bookmarks format: lines (path:line[:text]) or vim (output of :marks)")
+	buffer := cmd.flags.String("buffer", "", "File that lowercase vim marks belong to")
+	output := cmd.flags.String("o", "", "Write the remapped bookmarks to this file instead of stdout")
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if *oldDir == "" || *newDir == "" || len(args) > 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		in := os.Stdin
+		if len(args) == 1 {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		var remap func(string, *bookmarkRemapper) (string, bool, error)
+		switch *format {
+		case "lines":
+			remap = remapLinesBookmark
+		case "vim":
+			remap = remapVimMark
+		default:
+			return fmt.Errorf("unknown format: %s", *format)
+		}
+		out := os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		remapper := &bookmarkRemapper{
+			remapper: lhdiff.NewRemapper(lhdiff.DirSnapshot(*oldDir), lhdiff.DirSnapshot(*newDir), opts()...),
+			newDir:   *newDir,
+			buffer:   *buffer,
+		}
+		return remapBookmarks(in, out, os.Stderr, remapper, remap)
+	}
+	return cmd
+}
+
+type bookmarkRemapper struct {
+	remapper *lhdiff.Remapper
+	newDir   string
+	buffer   string
+}
+
+// remap maps a one-based line in path, which can be relative to the source tree or an
+// absolute path inside the new source tree.
+func (b *bookmarkRemapper) remap(path string, line int) (int, bool, error) {
+	path = b.relativePath(path)
+	newLine, _, ok, err := b.remapper.Remap(path, line-1)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	return newLine + 1, ok, err
+}
+
+func (b *bookmarkRemapper) relativePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	root, err := filepath.Abs(b.newDir)
+	if err != nil {
+		return path
+	}
+	relative, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return path
+	}
+	return filepath.ToSlash(relative)
+}
+
+func remapBookmarks(in io.Reader, out io.Writer, report io.Writer, remapper *bookmarkRemapper, remap func(string, *bookmarkRemapper) (string, bool, error)) error {
+	scanner := bufio.NewScanner(in)
+	dropped := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		remapped, ok, err := remap(line, remapper)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			dropped++
+			_, _ = fmt.Fprintf(report, "dropped: %s\n", line)
+			continue
+		}
+		if _, err := fmt.Fprintln(out, remapped); err != nil {
+			return err
+		}
+	}
+	if dropped > 0 {
+		_, _ = fmt.Fprintf(report, "%d bookmark(s) dropped\n", dropped)
+	}
+	return scanner.Err()
+}
+
+var /* const */ linesBookmark = regexp.MustCompile(`^(.+?):(\d+)(:.*)?$`)
+
+// remapLinesBookmark remaps a path:line[:text] bookmark. Lines that aren't bookmarks are kept as-is.
+func remapLinesBookmark(line string, remapper *bookmarkRemapper) (string, bool, error) {
+	match := linesBookmark.FindStringSubmatch(line)
+	if match == nil {
+		return line, true, nil
+	}
+	lineNumber, _ := strconv.Atoi(match[2])
+	newLine, ok, err := remapper.remap(match[1], lineNumber)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return fmt.Sprintf("%s:%d%s", match[1], newLine, match[3]), true, nil
+}
+
+var /* const */ vimMark = regexp.MustCompile(`^(\s*)(\S)(\s+)(\d+)(\s+\d+\s+)(.*)$`)
+
+// remapVimMark remaps a line of vim's :marks output. File marks (uppercase and digits) name
+// their file in the last column. Lowercase marks belong to the file given with -buffer, and
+// are kept as-is when it isn't set. The header and special marks are kept as-is.
+func remapVimMark(line string, remapper *bookmarkRemapper) (string, bool, error) {
+	match := vimMark.FindStringSubmatch(line)
+	if match == nil {
+		return line, true, nil
+	}
+	mark := match[2]
+	var path string
+	switch {
+	case mark >= "A" && mark <= "Z", mark >= "0" && mark <= "9":
+		path = match[6]
+	case mark >= "a" && mark <= "z" && remapper.buffer != "":
+		path = remapper.buffer
+	default:
+		return line, true, nil
+	}
+	lineNumber, _ := strconv.Atoi(match[4])
+	newLine, ok, err := remapper.remap(path, lineNumber)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	number := strconv.Itoa(newLine)
+	padding := match[3]
+	// Keep the line number right-aligned
+	if delta := len(number) - len(match[4]); delta > 0 && len(padding) > delta {
+		padding = padding[delta:]
+	} else if delta < 0 {
+		padding += strings.Repeat(" ", -delta)
+	}
+	return match[1] + mark + padding + number + match[5] + match[6], true, nil
+}
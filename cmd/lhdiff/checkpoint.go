@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/repo"
+	"os"
+	"os/signal"
+)
+
+// addCheckpointFlag adds the -checkpoint flag to flags, and returns a function opening the
+// checkpoint it sets, if any, adding it to opts. The returned close function writes the pending
+// comparisons of the checkpoint, which is also done when the command is interrupted.
+func addCheckpointFlag(flags *flag.FlagSet) func(opts []lhdiff.Option) ([]lhdiff.Option, func(), error) {
+	path := flags.String("checkpoint", "", "Persist the comparisons in this database file, so that an interrupted run started again with it resumes where it left off (replaces -cache-dir)")
+	return func(opts []lhdiff.Option) ([]lhdiff.Option, func(), error) {
+		if *path == "" {
+			return opts, func() {}, nil
+		}
+		checkpoint, err := repo.OpenCheckpoint(*path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n := checkpoint.Len(); n > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "resuming from %s with %d comparison(s)\n", *path, n)
+		}
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-interrupts:
+				_ = checkpoint.Close()
+				os.Exit(130)
+			case <-done:
+			}
+		}()
+		closeCheckpoint := func() {
+			signal.Stop(interrupts)
+			close(done)
+			if err := checkpoint.Close(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", *path, err)
+			}
+		}
+		return append(opts, lhdiff.WithCache(checkpoint)), closeCheckpoint, nil
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/SmartBear/lhdiff/repo"
+	"io"
+	"strings"
+)
+
+// codeQualityIssue is an issue of a GitLab Code Quality report, which GitLab shows in the merge
+// request widget and on the lines of the diff.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// writeCodeQuality writes the lines that were lost and the pairs of lines less similar than below
+// as a GitLab Code Quality report. Issues are on the lines of the new versions of the files, so
+// a lost line is reported on the line that its predecessor became, and the lines of deleted
+// files aren't reported.
+//
+// GitLab compares the reports of two pipelines by the fingerprints of their issues, so these are
+// hashes of the check, the path and the old line without its whitespace, but not of the line
+// numbers or the similarity, which change when other lines do.
+func writeCodeQuality(w io.Writer, files []repo.FileStats, below float64) error {
+	issues := make([]codeQualityIssue, 0)
+	fingerprints := make(map[string]int)
+	add := func(checkName string, severity string, path string, line int, text string, description string) {
+		hash := md5.Sum([]byte(checkName + "\x00" + path + "\x00" + strings.Join(strings.Fields(text), " ")))
+		fingerprint := hex.EncodeToString(hash[:])
+		// Identical issues in a file are told apart by their order
+		fingerprints[fingerprint]++
+		if n := fingerprints[fingerprint]; n > 1 {
+			hash = md5.Sum([]byte(fmt.Sprintf("%s:%d", fingerprint, n)))
+			fingerprint = hex.EncodeToString(hash[:])
+		}
+		issue := codeQualityIssue{Description: description, CheckName: checkName, Fingerprint: fingerprint, Severity: severity}
+		issue.Location.Path = path
+		issue.Location.Lines.Begin = line
+		issues = append(issues, issue)
+	}
+	for _, file := range files {
+		if file.NewPath == "" || file.Path == "" {
+			continue
+		}
+		result := file.Result
+		for _, lost := range file.Lost {
+			add("lhdiff-lost-line", "minor", file.NewPath, lostLocation(file, lost.Line), lost.Text, fmt.Sprintf("Line %d of %s was deleted: %s", lost.Line+1, file.Path, lost.Text))
+		}
+		for _, mapping := range result.Mappings {
+			if mapping.Left != -1 && mapping.Right != -1 && mapping.Similarity < below {
+				var text string
+				if mapping.Left < len(file.Lines) {
+					text = file.Lines[mapping.Left]
+				}
+				add("lhdiff-low-confidence", "info", file.NewPath, mapping.Right+1, text, fmt.Sprintf("Line %d of %s was tracked here with a low similarity (%.2f)", mapping.Left+1, file.Path, mapping.Similarity))
+			}
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// lostLocation returns the one-based line of the new version of the file next to which the lost
+// line was: the line that the closest tracked line above it became, or the first line if there
+// is none. The line after that one isn't used, since it may be the empty line after the final
+// newline, which isn't a line of the file.
+func lostLocation(file repo.FileStats, lost int) int {
+	line := 1
+	for _, mapping := range file.Result.Mappings {
+		if mapping.Left >= lost || mapping.Left == -1 {
+			break
+		}
+		if mapping.Right != -1 {
+			line = mapping.Right + 1
+		}
+	}
+	return line
+}
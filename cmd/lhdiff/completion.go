@@ -0,0 +1,200 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func newCompletionCommand() *command {
+	cmd := &command{
+		name:    "completion",
+		usage:   "completion bash|zsh|fish",
+		summary: "Print a script completing the commands and options of lhdiff in bash, zsh or fish.",
+		flags:   flag.NewFlagSet("completion", flag.ExitOnError),
+	}
+	cmd.run = func(args []string) error {
+		if len(args) != 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		switch args[0] {
+		case "bash":
+			writeBashCompletion(os.Stdout, documentedCommands())
+		case "zsh":
+			writeZshCompletion(os.Stdout, documentedCommands())
+		case "fish":
+			writeFishCompletion(os.Stdout, documentedCommands())
+		default:
+			return fmt.Errorf("unknown shell: %s", args[0])
+		}
+		return nil
+	}
+	return cmd
+}
+
+// documentedCommands returns the commands with all of their flags, including the ones main adds
+// to every command, so that completions and man pages are generated from the same definitions
+// as the usage.
+func documentedCommands() []*command {
+	all := commands()
+	for _, cmd := range all {
+		addProfileFlags(cmd.flags)
+	}
+	return all
+}
+
+// commandFlags returns the flags of a command, sorted by name.
+func commandFlags(cmd *command) []*flag.Flag {
+	var flags []*flag.Flag
+	cmd.flags.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+// isBoolFlag returns true if a flag doesn't take a value, as -debug.
+func isBoolFlag(f *flag.Flag) bool {
+	value, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && value.IsBoolFlag()
+}
+
+func writeBashCompletion(w io.Writer, all []*command) {
+	var names []string
+	var cases strings.Builder
+	defaultFlags := ""
+	for _, cmd := range all {
+		var flags []string
+		for _, f := range commandFlags(cmd) {
+			flags = append(flags, "-"+f.Name)
+		}
+		if cmd.name == "" {
+			defaultFlags = strings.Join(flags, " ")
+			continue
+		}
+		names = append(names, cmd.name)
+		_, _ = fmt.Fprintf(&cases, "\t%s) flags=\"%s\" ;;\n", cmd.name, strings.Join(flags, " "))
+	}
+	_, _ = fmt.Fprintf(w, `# bash completion for lhdiff, generated by lhdiff completion bash
+_lhdiff() {
+	local cur=${COMP_WORDS[COMP_CWORD]} flags
+	# Like lhdiff, only the first argument can be the name of a command
+	case ${COMP_WORDS[1]} in
+%s	*) flags="%s" ;;
+	esac
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	elif ((COMP_CWORD == 1)); then
+		COMPREPLY=($(compgen -W "%s" -- "$cur") $(compgen -f -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+complete -o filenames -F _lhdiff lhdiff
+`, cases.String(), defaultFlags, strings.Join(names, " "))
+}
+
+func writeZshCompletion(w io.Writer, all []*command) {
+	var descriptions, cases strings.Builder
+	defaultArguments := ""
+	for _, cmd := range all {
+		arguments := zshArguments(cmd)
+		if cmd.name == "" {
+			defaultArguments = arguments
+			continue
+		}
+		_, _ = fmt.Fprintf(&descriptions, "\t\t%s\n", zshQuote(cmd.name+":"+firstSentence(cmd.summary)))
+		_, _ = fmt.Fprintf(&cases, "\t%s)\n\t\tshift words\n\t\t(( CURRENT-- ))\n\t\t_arguments -S%s '*:file:_files'\n\t\t;;\n", cmd.name, arguments)
+	}
+	_, _ = fmt.Fprintf(w, `#compdef lhdiff
+# zsh completion for lhdiff, generated by lhdiff completion zsh
+
+_lhdiff() {
+	local -a subcommands
+	subcommands=(
+%s	)
+	# Like lhdiff, only the first argument can be the name of a command
+	if (( CURRENT == 2 )) && [[ $words[2] != -* ]]; then
+		_describe -t commands command subcommands
+		_files
+		return
+	fi
+	case $words[2] in
+%s	*)
+		_arguments -S%s '*:file:_files'
+		;;
+	esac
+}
+
+_lhdiff "$@"
+`, descriptions.String(), cases.String(), defaultArguments)
+}
+
+// zshArguments returns the specs of the flags of a command for _arguments, each preceded by a
+// space.
+func zshArguments(cmd *command) string {
+	var arguments strings.Builder
+	for _, f := range commandFlags(cmd) {
+		name, usage := flag.UnquoteUsage(f)
+		// Brackets and colons delimit the parts of a spec, and backslashes escape them
+		usage = strings.NewReplacer(`\`, `\\`, "[", "(", "]", ")", ":", ";").Replace(firstSentence(usage))
+		spec := "-" + f.Name + "[" + usage + "]"
+		if !isBoolFlag(f) {
+			if name == "" {
+				name = "value"
+			}
+			spec += ":" + name + ":_files"
+		}
+		arguments.WriteString(" " + zshQuote(spec))
+	}
+	return arguments.String()
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeFishCompletion(w io.Writer, all []*command) {
+	_, _ = fmt.Fprintln(w, "# fish completion for lhdiff, generated by lhdiff completion fish")
+	var names []string
+	for _, cmd := range all {
+		if cmd.name != "" {
+			names = append(names, cmd.name)
+		}
+	}
+	for _, cmd := range all {
+		if cmd.name != "" {
+			_, _ = fmt.Fprintf(w, "complete -c lhdiff -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(firstSentence(cmd.summary)))
+		}
+	}
+	for _, cmd := range all {
+		condition := "'__fish_seen_subcommand_from " + cmd.name + "'"
+		if cmd.name == "" {
+			condition = "'not __fish_seen_subcommand_from " + strings.Join(names, " ") + "'"
+		}
+		for _, f := range commandFlags(cmd) {
+			_, usage := flag.UnquoteUsage(f)
+			required := " -r"
+			if isBoolFlag(f) {
+				required = ""
+			}
+			// Go flags have one dash, which is what fish calls old-style options
+			_, _ = fmt.Fprintf(w, "complete -c lhdiff -n %s -o %s%s -d %s\n", condition, f.Name, required, fishQuote(firstSentence(usage)))
+		}
+	}
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// firstSentence returns the first sentence of a usage or summary, which is short enough to be
+// displayed next to the completions.
+func firstSentence(s string) string {
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// config is a YAML configuration file. Its profiles, keyed by file extension, set the options of
+// the files with that extension, overriding the flags.
+type config struct {
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+type profile struct {
+	Threshold        *float64 `yaml:"threshold"`
+	ContextSize      *int     `yaml:"context-size"`
+	ContentGate      *float64 `yaml:"content-gate"`
+	Calibrate        bool     `yaml:"calibrate"`
+	Adaptive         bool     `yaml:"adaptive"`
+	Monotonic        bool     `yaml:"monotonic"`
+	Minimal          bool     `yaml:"minimal"`
+	IgnoreBlankLines bool     `yaml:"ignore-blank-lines"`
+	Tokenizer        string   `yaml:"tokenizer"`
+	StopWords        []string `yaml:"stop-words"`
+}
+
+// readConfig reads a configuration file, and returns the profiles it defines. Extensions may be
+// given with or without their dot.
+func readConfig(path string) (map[string][]lhdiff.Option, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var c config
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	profiles := make(map[string][]lhdiff.Option, len(c.Profiles))
+	for extension, p := range c.Profiles {
+		extension = strings.ToLower(extension)
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		var opts []lhdiff.Option
+		if p.Threshold != nil {
+			if *p.Threshold < 0 || *p.Threshold > 1 {
+				return nil, fmt.Errorf("%s: the threshold of %s must be between 0 and 1", path, extension)
+			}
+			opts = append(opts, lhdiff.WithThreshold(*p.Threshold))
+		}
+		if p.ContextSize != nil {
+			opts = append(opts, lhdiff.WithContextSize(*p.ContextSize))
+		}
+		if p.ContentGate != nil {
+			opts = append(opts, lhdiff.WithContentGate(*p.ContentGate))
+		}
+		if p.Calibrate {
+			opts = append(opts, lhdiff.WithCalibratedThreshold())
+		}
+		if p.Adaptive {
+			opts = append(opts, lhdiff.WithAdaptiveWeighting(lhdiff.DefaultShortLineLength, lhdiff.DefaultLongLineLength))
+		}
+		if p.Monotonic {
+			opts = append(opts, lhdiff.WithMonotonic())
+		}
+		if p.Minimal || p.IgnoreBlankLines {
+			opts = append(opts, lhdiff.WithDiffEngine(lhdiff.Myers{Minimal: p.Minimal, IgnoreBlankLines: p.IgnoreBlankLines}))
+		}
+		if p.Tokenizer != "" {
+			tokenizer, err := parseTokenizer(p.Tokenizer)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			opts = append(opts, lhdiff.WithTokenizer(tokenizer))
+		}
+		if p.StopWords != nil {
+			opts = append(opts, lhdiff.WithStopWords(p.StopWords...))
+		}
+		profiles[extension] = opts
+	}
+	return profiles, nil
+}
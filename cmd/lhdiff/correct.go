@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func newCorrectCommand() *command {
+	cmd := &command{
+		name:    "correct",
+		usage:   "correct [options] left right",
+		summary: "Review low-confidence and deleted lines interactively, and write the corrected mapping.",
+		flags:   flag.NewFlagSet("correct", flag.ExitOnError),
+	}
+	output := cmd.flags.String("o", "", "File to write the corrected mapping to (default stdout)")
+	below := cmd.flags.Float64("below", 0.8, "Review the pairs of lines with a similarity below this")
+	opts := addOptionFlags(cmd.flags)
+	read := addFetchFlags(cmd.flags)
+	pins := addPinsFlag(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 2 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		left, err := read(args[0])
+		if err != nil {
+			return err
+		}
+		right, err := read(args[1])
+		if err != nil {
+			return err
+		}
+		pinOpts, err := pins()
+		if err != nil {
+			return err
+		}
+		result, err := lhdiff.Compare(string(left), string(right), append(opts(), pinOpts...)...)
+		if err != nil {
+			return err
+		}
+		// The questions are written to stderr, so that the mapping can be written to stdout
+		corrected := correct(os.Stdin, os.Stderr, string(left), string(right), result, *below)
+		out := os.Stdout
+		if *output != "" {
+			out, err = os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+		}
+		return writeMapping(out, corrected, result.RightLineCount)
+	}
+	return cmd
+}
+
+// correct asks about each pair with a similarity below below and each deleted left line, and
+// returns the corrected right line of each left line (-1 for deleted lines).
+func correct(in io.Reader, out io.Writer, left string, right string, result *lhdiff.Result, below float64) []int {
+	leftLines := strings.Split(left, "\n")
+	rightLines := strings.Split(right, "\n")
+	rights := make([]int, result.LeftLineCount)
+	lefts := make(map[int]int)
+	// The left lines of the pairs of Moved are deleted in Mappings, which has one mapping for
+	// each left line, in order
+	mappings := append([]lhdiff.LineMapping(nil), result.Mappings...)
+	for _, moved := range result.Moved {
+		mappings[moved.Left] = moved
+	}
+	for _, mapping := range mappings {
+		if mapping.Left != -1 {
+			rights[mapping.Left] = mapping.Right
+			if mapping.Right != -1 {
+				lefts[mapping.Right] = mapping.Left
+			}
+		}
+	}
+	answers := bufio.NewScanner(in)
+	for _, mapping := range mappings {
+		if mapping.Left == -1 || (mapping.Right != -1 && mapping.Similarity >= below) {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "left %d: %s\n", mapping.Left+1, leftLines[mapping.Left])
+		if mapping.Right == -1 {
+			_, _ = fmt.Fprintln(out, "  deleted")
+		} else {
+			_, _ = fmt.Fprintf(out, "  right %d: %s (similarity %.2f)\n", mapping.Right+1, rightLines[mapping.Right], mapping.Similarity)
+		}
+		for {
+			_, _ = fmt.Fprint(out, "Enter to accept, a right line number to map it to, d if it was deleted, q to stop: ")
+			if !answers.Scan() {
+				_, _ = fmt.Fprintln(out)
+				return rights
+			}
+			answer := strings.TrimSpace(answers.Text())
+			if answer == "" {
+				break
+			}
+			if answer == "q" {
+				return rights
+			}
+			newRight := -1
+			if answer != "d" {
+				line, err := strconv.Atoi(answer)
+				if err != nil || line < 1 || line > result.RightLineCount {
+					_, _ = fmt.Fprintf(out, "  not a line of the right file: %s\n", answer)
+					continue
+				}
+				newRight = line - 1
+			}
+			if oldRight := rights[mapping.Left]; oldRight != -1 {
+				delete(lefts, oldRight)
+			}
+			if other, ok := lefts[newRight]; ok && newRight != -1 {
+				_, _ = fmt.Fprintf(out, "  left %d is now deleted\n", other+1)
+				rights[other] = -1
+			}
+			rights[mapping.Left] = newRight
+			if newRight != -1 {
+				lefts[newRight] = mapping.Left
+			}
+			break
+		}
+	}
+	return rights
+}
+
+// writeMapping writes a mapping in the format of the mapping command, which can be read back
+// with -pins.
+func writeMapping(w io.Writer, rights []int, rightLineCount int) error {
+	mapped := make(map[int]bool)
+	for left, right := range rights {
+		if _, err := fmt.Fprintf(w, "%s,%s\n", lineString(left), lineString(right)); err != nil {
+			return err
+		}
+		mapped[right] = true
+	}
+	for right := 0; right < rightLineCount; right++ {
+		if !mapped[right] {
+			if _, err := fmt.Fprintf(w, "_,%d\n", right+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func lineString(line int) string {
+	if line == -1 {
+		return "_"
+	}
+	return strconv.Itoa(line + 1)
+}
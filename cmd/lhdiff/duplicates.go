@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"github.com/SmartBear/lhdiff"
+	"io/ioutil"
+	"os"
+)
+
+func newDuplicatesCommand() *command {
+	cmd := &command{
+		name:    "duplicates",
+		usage:   "duplicates [options] file",
+		summary: "Report duplicated or near-duplicated lines within a single file.",
+		flags:   flag.NewFlagSet("duplicates", flag.ExitOnError),
+	}
+	contextSize := cmd.flags.Int("context", 4, "Number of context lines above and below each line")
+	cmd.run = func(args []string) error {
+		if len(args) != 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		text, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		return lhdiff.PrintDuplicates(lhdiff.Duplicates(string(text), *contextSize))
+	}
+	return cmd
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"os"
+)
+
+func newExportCommand() *command {
+	cmd := &command{
+		name:    "export",
+		usage:   "export [options]",
+		summary: "Write the line mappings of the genealogy database as an Avro file.",
+		flags:   flag.NewFlagSet("export", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	db := addGenealogyFlag(cmd.flags)
+	output := cmd.flags.String("o", "", "Write the Avro file to this file instead of stdout")
+	cmd.run = func(args []string) error {
+		if len(args) != 0 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		genealogy, err := openGenealogy(openRepository, *db, nil)
+		if err != nil {
+			return err
+		}
+		defer genealogy.Close()
+		out := os.Stdout
+		if *output != "" {
+			out, err = os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+		}
+		return genealogy.WriteAvro(out)
+	}
+	return cmd
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// addFetchFlags adds the flags limiting the downloads of files that are given as http(s) URLs,
+// and returns the function reading the files and URLs.
+func addFetchFlags(flags *flag.FlagSet) func(path string) ([]byte, error) {
+	timeout := flags.Duration("fetch-timeout", 30*time.Second, "Timeout of the download of a file given as an http(s) URL")
+	maxSize := flags.Int64("fetch-max-size", 10<<20, "Largest size in bytes of a file given as an http(s) URL")
+	return func(path string) ([]byte, error) {
+		if !isURL(path) {
+			return ioutil.ReadFile(path)
+		}
+		return fetch(path, *timeout, *maxSize)
+	}
+}
+
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// fetch downloads the file at url, such as the raw link of a file of a hosted repository.
+func fetch(url string, timeout time.Duration, maxSize int64) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	tooLarge := fmt.Errorf("%s is larger than the limit of %d bytes (see -fetch-max-size)", url, maxSize)
+	if response.ContentLength > maxSize {
+		return nil, tooLarge
+	}
+	// The length isn't always known, so one more byte than the limit is read to detect files that
+	// are too large
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, tooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/finding"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func newFindingsCommand() *command {
+	cmd := &command{
+		name:    "findings",
+		usage:   "findings [options] report",
+		summary: "Remap static-analysis findings to a newer version of the source files.",
+		flags:   flag.NewFlagSet("findings", flag.ExitOnError),
+	}
+	format := cmd.flags.String("format", "sarif", "Report format: "+strings.Join(finding.Names(), ", "))
+	oldDir := cmd.flags.String("old", "", "Directory with the source files the report was generated on")
+	from := cmd.flags.String("from", "", "Git revision the report was generated on (instead of -old)")
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository, with -from")
+	newDir := cmd.flags.String("new", ".", "Directory with the current source files")
+	output := cmd.flags.String("o", "", "Write the remapped report to this file instead of stdout")
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 1 || (*oldDir == "") == (*from == "") {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		adapter, ok := finding.Lookup(*format)
+		if !ok {
+			return fmt.Errorf("unknown format: %s", *format)
+		}
+		report, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var old lhdiff.Snapshot = lhdiff.DirSnapshot(*oldDir)
+		if *from != "" {
+			repository, err := openRepository()
+			if err != nil {
+				return err
+			}
+			old = repository.Snapshot(*from)
+		}
+		remapped, stats, err := finding.Remap(adapter, report, lhdiff.NewRemapper(old, lhdiff.DirSnapshot(*newDir), opts()...))
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "findings: %s\n", stats)
+		if *output != "" {
+			return ioutil.WriteFile(*output, remapped, 0644)
+		}
+		_, err = os.Stdout.Write(remapped)
+		return err
+	}
+	return cmd
+}
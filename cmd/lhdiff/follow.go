@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/repo"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+func newFollowCommand() *command {
+	cmd := &command{
+		name:    "follow",
+		usage:   "follow [options] -L start,end:path [rev] | -from rev [-to rev] path:line",
+		summary: "Show the commits that changed a range of lines, like git log -L, or where a line went, tracking it through moves and rewrites.",
+		flags:   flag.NewFlagSet("follow", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	lineRange := cmd.flags.String("L", "", "Range of lines to follow, as start,end:path (one-based, inclusive)")
+	from := cmd.flags.String("from", "", "Revision the line number of path:line refers to")
+	to := cmd.flags.String("to", "HEAD", "Revision to follow path:line to")
+	db := addGenealogyFlag(cmd.flags)
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if *lineRange == "" && len(args) == 1 && *from != "" {
+			if match := pathAndLine.FindStringSubmatch(args[0]); match != nil {
+				line, _ := strconv.Atoi(match[2])
+				return followLine(openRepository, *db, *from, *to, match[1], line, opts())
+			}
+		}
+		match := followRange.FindStringSubmatch(*lineRange)
+		if match == nil || len(args) > 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		start, _ := strconv.Atoi(match[1])
+		end, _ := strconv.Atoi(match[2])
+		rev := "HEAD"
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		repository, err := openRepository()
+		if err != nil {
+			return err
+		}
+		changes, err := repository.FollowRange(rev, match[3], start, end, opts()...)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			_, err := fmt.Printf("commit %s\nDate:   %s\n\n    %s\n\n", change.Commit.SHA, change.Commit.Time.Format("2006-01-02 15:04:05 -0700"), change.Commit.Subject)
+			if err != nil {
+				return err
+			}
+			if change.OldPath == "" {
+				_, err = fmt.Printf("%s:%d-%d introduced\n", change.NewPath, change.NewStart, change.NewEnd)
+			} else {
+				_, err = fmt.Printf("%s:%d-%d (was %s:%d-%d)\n", change.NewPath, change.NewStart, change.NewEnd, change.OldPath, change.OldStart, change.OldEnd)
+			}
+			if err != nil {
+				return err
+			}
+			for _, line := range change.Lines {
+				switch {
+				case line.New == 0:
+					_, err = fmt.Printf("-%s\n", line.OldText)
+				case line.Old == 0:
+					_, err = fmt.Printf("+%s\n", line.NewText)
+				case line.OldText == line.NewText:
+					_, err = fmt.Printf(" %s\n", line.NewText)
+				default:
+					_, err = fmt.Printf("-%s\n+%s\n", line.OldText, line.NewText)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Println(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return cmd
+}
+
+// followLine prints where a line (one-based) at from is at to, or the commit that deleted it,
+// followed by the commits that changed it, using the genealogy database.
+func followLine(openRepository func() (*repo.Repository, error), db string, from string, to string, path string, line int, opts []lhdiff.Option) error {
+	genealogy, err := openGenealogy(openRepository, db, opts)
+	if err != nil {
+		return err
+	}
+	defer genealogy.Close()
+	if _, err := genealogy.Update(to); err != nil {
+		return err
+	}
+	hops, err := genealogy.Follow(from, to, path, line)
+	if err != nil {
+		return err
+	}
+	newPath, newLine := path, line
+	if len(hops) > 0 {
+		last := hops[len(hops)-1]
+		if last.Deleted {
+			_, err = fmt.Printf("%s:%d at %s was deleted by %s %s\t%s\n", path, line, from, last.Commit.Short(), last.Commit.Time.Format("2006-01-02"), last.Commit.Subject)
+			printHops(os.Stdout, hops)
+			return err
+		}
+		newPath, newLine = last.Path, last.Line
+	}
+	_, err = fmt.Printf("%s:%d at %s is %s:%d at %s\n", path, line, from, newPath, newLine, to)
+	printHops(os.Stdout, hops)
+	return err
+}
+
+var /* const */ followRange = regexp.MustCompile(`^(\d+),(\d+):(.+)$`)
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func newHotSpotsCommand() *command {
+	cmd := &command{
+		name:    "hotspots",
+		usage:   "hotspots [options]",
+		summary: "Rank the files and regions whose lines were rewritten the most across a commit range.",
+		flags:   flag.NewFlagSet("hotspots", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	from := cmd.flags.String("from", "", "Start of the commit range (default: the whole history)")
+	to := cmd.flags.String("to", "HEAD", "End of the commit range")
+	top := cmd.flags.Int("n", 10, "Number of files and of regions to print")
+	opts := addOptionFlags(cmd.flags)
+	checkpoint := addCheckpointFlag(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 0 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		repository, err := openRepository()
+		if err != nil {
+			return err
+		}
+		options, closeCheckpoint, err := checkpoint(opts())
+		if err != nil {
+			return err
+		}
+		defer closeCheckpoint()
+		hotSpots, err := repository.HotSpots(*from, *to, options...)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Println("files:")
+		for i, file := range hotSpots.Files {
+			if i == *top {
+				break
+			}
+			_, _ = fmt.Printf("  %d rewrite(s) in %d commit(s)\t%s\n", file.Rewrites, file.Commits, file.Path)
+		}
+		_, _ = fmt.Println("regions:")
+		for i, region := range hotSpots.Regions {
+			if i == *top {
+				break
+			}
+			if _, err := fmt.Printf("  %d rewrite(s)\t%s:%d-%d\n", region.Rewrites, region.Path, region.Start, region.End); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/repo"
+	"os"
+	"path/filepath"
+)
+
+func newIndexCommand() *command {
+	cmd := &command{
+		name:    "index",
+		usage:   "index [options] [rev]",
+		summary: "Store the line mappings of each commit up to rev (default HEAD) in the genealogy database.",
+		flags:   flag.NewFlagSet("index", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	db := addGenealogyFlag(cmd.flags)
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) > 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		rev := "HEAD"
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		genealogy, err := openGenealogy(openRepository, *db, opts())
+		if err != nil {
+			return err
+		}
+		defer genealogy.Close()
+		indexed, err := genealogy.Update(rev)
+		_, _ = fmt.Fprintf(os.Stderr, "indexed %d commit(s)\n", indexed)
+		return err
+	}
+	return cmd
+}
+
+func addGenealogyFlag(flags *flag.FlagSet) *string {
+	return flags.String("db", "", "Genealogy database (default: lhdiff-genealogy.db in the .git directory)")
+}
+
+func openGenealogy(openRepository func() (*repo.Repository, error), db string, opts []lhdiff.Option) (*repo.Genealogy, error) {
+	repository, err := openRepository()
+	if err != nil {
+		return nil, err
+	}
+	if db == "" {
+		gitDir, err := repository.GitDir()
+		if err != nil {
+			return nil, err
+		}
+		db = filepath.Join(gitDir, "lhdiff-genealogy.db")
+	}
+	genealogy, err := repository.OpenGenealogy(db, opts...)
+	if errors.Is(err, repo.ErrOptionsMismatch) {
+		return nil, fmt.Errorf("%w: use the same options, or another -db", err)
+	}
+	return genealogy, err
+}
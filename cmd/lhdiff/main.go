@@ -3,27 +3,93 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/SmartBear/lhdiff"
-	"io/ioutil"
 	"os"
 )
 
-func main() {
-	compact := flag.Bool("compact", false, "Exclude identical lines from output")
-	flag.Parse()
-	leftFile := flag.Arg(0)
-	rightFile := flag.Arg(1)
-	left, _ := ioutil.ReadFile(leftFile)
-	right, _ := ioutil.ReadFile(rightFile)
-	mappings, err := lhdiff.Lhdiff(string(left), string(right), 4, !*compact)
+// command is a lhdiff subcommand. The command without a name is the default one, used when
+// the first argument isn't the name of a subcommand.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	flags   *flag.FlagSet
+	run     func(args []string) error
+}
 
-	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+func commands() []*command {
+	return []*command{
+		newMappingCommand(),
+		newDuplicatesCommand(),
+		newServeCommand(),
+		newBookmarksCommand(),
+		newPermalinksCommand(),
+		newTodosCommand(),
+		newMutationCommand(),
+		newFindingsCommand(),
+		newIndexCommand(),
+		newExportCommand(),
+		newWhereCommand(),
+		newTimelineCommand(),
+		newSurvivalCommand(),
+		newHotSpotsCommand(),
+		newFollowCommand(),
+		newReviewCommand(),
+		newWhyCommand(),
+		newCorrectCommand(),
+		newApplyCommand(),
+		newStatsCommand(),
+		newBenchmarkCommand(),
+		newBatchCommand(),
+		newCompletionCommand(),
+		newManCommand(),
+	}
+}
+
+func main() {
+	cmd := findCommand(os.Args[1:])
+	args := os.Args[1:]
+	if cmd.name != "" {
+		args = args[1:]
+	}
+	profile := addProfileFlags(cmd.flags)
+	cmd.flags.Usage = func() { printUsage(cmd) }
+	_ = cmd.flags.Parse(args)
+	stopProfiles, err := profile()
+	if err == nil {
+		err = cmd.run(cmd.flags.Args())
+		if stopErr := stopProfiles(); err == nil {
+			err = stopErr
+		}
 	}
-	err = lhdiff.PrintMappings(mappings)
 	if err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 }
+
+func findCommand(args []string) *command {
+	all := commands()
+	if len(args) > 0 {
+		for _, cmd := range all {
+			if cmd.name != "" && cmd.name == args[0] {
+				return cmd
+			}
+		}
+	}
+	return all[0]
+}
+
+func printUsage(cmd *command) {
+	out := cmd.flags.Output()
+	_, _ = fmt.Fprintf(out, "Usage: lhdiff %s\n\n%s\n", cmd.usage, cmd.summary)
+	if cmd.name == "" {
+		_, _ = fmt.Fprintln(out, "\nCommands:")
+		for _, sub := range commands() {
+			if sub.name != "" {
+				_, _ = fmt.Fprintf(out, "  %-12s %s\n", sub.name, sub.summary)
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(out, "\nOptions:")
+	cmd.flags.PrintDefaults()
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/repo"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// newTestRepository returns a repository with a commit for each revision, which gives the
+// contents of the files that it changes, with an empty content for a deleted file.
+func newTestRepository(revisions ...map[string]string) *repo.Repository {
+	dir, err := ioutil.TempDir("", "lhdiff-repo")
+	check(err)
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE=2022-01-01T00:00:00Z",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE=2022-01-01T00:00:00Z",
+			"GIT_CONFIG_NOSYSTEM=1",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			panic(fmt.Sprintf("git %v: %s", args, out))
+		}
+	}
+	git("init", "-q")
+	for i, files := range revisions {
+		for path, content := range files {
+			if content == "" {
+				check(os.Remove(filepath.Join(dir, path)))
+			} else {
+				check(ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+			}
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", fmt.Sprintf("revision %d", i))
+	}
+	repository, err := repo.Open(dir)
+	check(err)
+	return repository
+}
+
+func Example_applyMappings() {
+	left := "one\ntwo\nthree\nfour\n"
+	right := "four\none\n2\nthree\n"
+	moved, err := lhdiff.Compare(left, right, lhdiff.WithMonotonic())
+	check(err)
+	files := fileMappings{
+		"moved.txt":   {Result: moved},
+		"renamed.txt": {Path: "new/renamed.txt", Result: &lhdiff.Result{Mappings: []lhdiff.LineMapping{{Left: 0, Right: 1, Similarity: 1}, {Left: 1, Right: -1}}, LeftLineCount: 2, RightLineCount: 2}},
+		"deleted.txt": nil,
+	}
+	fmt.Println(len(moved.Moved) > 0)
+	locations := "moved.txt:4: four\nmoved.txt:1: one\nmoved.txt:2: two\nrenamed.txt:1\nrenamed.txt:2\ndeleted.txt:1\nunchanged.txt:3\nnot a location\n"
+	var orphans strings.Builder
+	check(applyMappings(strings.NewReader(locations), os.Stdout, &orphans, files))
+	fmt.Print("orphans:\n", orphans.String())
+
+	// Output:
+	// true
+	// moved.txt:1: four
+	// moved.txt:2: one
+	// new/renamed.txt:2
+	// unchanged.txt:3
+	// not a location
+	// orphans:
+	// moved.txt:2: two
+	// renamed.txt:2
+	// deleted.txt:1
+}
+
+func Example_remap() {
+	left := "one\ntwo\nthree\nfour\n"
+	right := "four\none\ntwo\nthree\n"
+	result, err := lhdiff.Compare(left, right, lhdiff.WithMonotonic())
+	check(err)
+	// Mapping files are read back the same whether they are delta-encoded or not
+	for _, mapping := range []*fileMapping{{Result: result}, {Delta: result.Delta()}} {
+		data, err := json.Marshal(fileMappings{"f.txt": mapping})
+		check(err)
+		path := filepath.Join(os.TempDir(), "lhdiff-remap.json")
+		check(ioutil.WriteFile(path, data, 0644))
+		files := make(fileMappings)
+		check(files.read(path))
+		check(os.Remove(path))
+		for line := 1; line <= 6; line++ {
+			path, newLine, ok := files.remap("f.txt", line)
+			fmt.Printf("%d -> %s:%d %v\n", line, path, newLine, ok)
+		}
+	}
+
+	// Output:
+	// 1 -> f.txt:2 true
+	// 2 -> f.txt:3 true
+	// 3 -> f.txt:4 true
+	// 4 -> f.txt:1 true
+	// 5 -> f.txt:5 true
+	// 6 -> f.txt:0 false
+	// 1 -> f.txt:2 true
+	// 2 -> f.txt:3 true
+	// 3 -> f.txt:4 true
+	// 4 -> f.txt:1 true
+	// 5 -> f.txt:5 true
+	// 6 -> f.txt:0 false
+}
+
+func Example_correct() {
+	left := "one\ntwo\nthree\nfour\n"
+	right := "four\none\n2\nthree\n"
+	result, err := lhdiff.Compare(left, right, lhdiff.WithMonotonic())
+	check(err)
+	// The deleted line is mapped to the added one, and the moved line stays moved
+	var questions strings.Builder
+	rights := correct(strings.NewReader("3\n"), &questions, left, right, result, 0.8)
+	// The prompts end with a space, which examples can't have
+	for _, line := range strings.Split(questions.String(), "\n") {
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	check(writeMapping(os.Stdout, rights, result.RightLineCount))
+
+	// Output:
+	// left 2: two
+	//   deleted
+	// Enter to accept, a right line number to map it to, d if it was deleted, q to stop:
+	// 1,2
+	// 2,3
+	// 3,4
+	// 4,1
+	// 5,5
+}
+
+func Example_writeMarkdownStats() {
+	old := "package main\n\nvar table = \"a | b\"\nvar raw = `raw`\n"
+	for i := 1; i <= 20; i++ {
+		old += fmt.Sprintf("var removed%d = %d\n", i, i*i)
+	}
+	current := "package main\n\nvar table = \"a | b | c\"\n"
+	result, err := lhdiff.Compare(old, current)
+	check(err)
+	files := []repo.FileStats{repo.NewFileStats("old|name.go", "main.go", old, result)}
+	check(writeMarkdownStats(os.Stdout, files[0].Stats, files))
+
+	// Output:
+	// ### Line tracking
+	//
+	// 1 file(s), 4 line(s) tracked (89.2% similar on average), 88.0% churn.
+	//
+	// | File | Unchanged | Modified | Moved | Deleted | Added | Churn |
+	// |---|---:|---:|---:|---:|---:|---:|
+	// | `old\|name.go` → `main.go` | 3 | 1 | 0 | 21 | 0 | 88.0% |
+	//
+	// #### Lost lines
+	//
+	// | File | Line | Text |
+	// |---|---:|---|
+	// | `old\|name.go` | 4 | `` var raw = `raw` `` |
+	// | `old\|name.go` | 5 | `var removed1 = 1` |
+	// | `old\|name.go` | 6 | `var removed2 = 4` |
+	// | `old\|name.go` | 7 | `var removed3 = 9` |
+	// | `old\|name.go` | 8 | `var removed4 = 16` |
+	// | `old\|name.go` | 9 | `var removed5 = 25` |
+	// | `old\|name.go` | 10 | `var removed6 = 36` |
+	// | `old\|name.go` | 11 | `var removed7 = 49` |
+	// | `old\|name.go` | 12 | `var removed8 = 64` |
+	// | `old\|name.go` | 13 | `var removed9 = 81` |
+	// | `old\|name.go` | 14 | `var removed10 = 100` |
+	// | `old\|name.go` | 15 | `var removed11 = 121` |
+	// | `old\|name.go` | 16 | `var removed12 = 144` |
+	// | `old\|name.go` | 17 | `var removed13 = 169` |
+	// | `old\|name.go` | 18 | `var removed14 = 196` |
+	// | `old\|name.go` | 19 | `var removed15 = 225` |
+	// | `old\|name.go` | 20 | `var removed16 = 256` |
+	// | `old\|name.go` | 21 | `var removed17 = 289` |
+	// | `old\|name.go` | 22 | `var removed18 = 324` |
+	// | `old\|name.go` | 23 | `var removed19 = 361` |
+	//
+	// …and 1 more.
+}
+
+func Example_writeCodeQuality() {
+	old := "lost first\nkeep one\nkeep two\nlost\nkeep three\nlost\nkeep four\nlost last\n"
+	current := "keep one\nkeep two, too\nkeep three\nkeep four\n"
+	result, err := lhdiff.Compare(old, current)
+	check(err)
+	// The same file twice has identical issues
+	file := repo.NewFileStats("notes.txt", "notes.txt", old, result)
+	files := []repo.FileStats{file, file}
+	var report strings.Builder
+	check(writeCodeQuality(&report, files, 0.9))
+	var issues []codeQualityIssue
+	check(json.Unmarshal([]byte(report.String()), &issues))
+	fingerprints := make(map[string]bool)
+	for _, issue := range issues {
+		fmt.Printf("%s %s:%d %s\n", issue.CheckName, issue.Location.Path, issue.Location.Lines.Begin, issue.Description)
+		fingerprints[issue.Fingerprint] = true
+	}
+	// Identical issues have fingerprints of their own, as GitLab requires
+	fmt.Println(len(fingerprints) == len(issues))
+
+	// The fingerprints don't change when other lines shift the issues
+	result, err = lhdiff.Compare("header\n"+old, "header\n\n"+current)
+	check(err)
+	file = repo.NewFileStats("notes.txt", "notes.txt", "header\n"+old, result)
+	report.Reset()
+	check(writeCodeQuality(&report, []repo.FileStats{file, file}, 0.9))
+	var shifted []codeQualityIssue
+	check(json.Unmarshal([]byte(report.String()), &shifted))
+	for _, issue := range shifted {
+		fmt.Printf("%s:%d %t\n", issue.Location.Path, issue.Location.Lines.Begin, fingerprints[issue.Fingerprint])
+	}
+
+	// Output:
+	// lhdiff-lost-line notes.txt:1 Line 1 of notes.txt was deleted: lost first
+	// lhdiff-lost-line notes.txt:2 Line 4 of notes.txt was deleted: lost
+	// lhdiff-lost-line notes.txt:3 Line 6 of notes.txt was deleted: lost
+	// lhdiff-lost-line notes.txt:4 Line 8 of notes.txt was deleted: lost last
+	// lhdiff-low-confidence notes.txt:2 Line 3 of notes.txt was tracked here with a low similarity (0.56)
+	// lhdiff-lost-line notes.txt:1 Line 1 of notes.txt was deleted: lost first
+	// lhdiff-lost-line notes.txt:2 Line 4 of notes.txt was deleted: lost
+	// lhdiff-lost-line notes.txt:3 Line 6 of notes.txt was deleted: lost
+	// lhdiff-lost-line notes.txt:4 Line 8 of notes.txt was deleted: lost last
+	// lhdiff-low-confidence notes.txt:2 Line 3 of notes.txt was tracked here with a low similarity (0.56)
+	// true
+	// notes.txt:1 true
+	// notes.txt:4 true
+	// notes.txt:5 true
+	// notes.txt:6 true
+	// notes.txt:4 true
+	// notes.txt:1 true
+	// notes.txt:4 true
+	// notes.txt:5 true
+	// notes.txt:6 true
+	// notes.txt:4 true
+}
+
+func Example_fetch() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/main.go", "/large.go":
+			_, _ = fmt.Fprint(w, "package main\n")
+		case "/stream.go":
+			// Flushing before the end leaves the length unknown
+			for i := 0; i < 3; i++ {
+				_, _ = fmt.Fprint(w, "package main\n")
+				w.(http.Flusher).Flush()
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, file := range []struct {
+		path    string
+		maxSize int64
+	}{{"/main.go", 13}, {"/missing.go", 13}, {"/large.go", 12}, {"/stream.go", 26}} {
+		data, err := fetch(server.URL+file.path, time.Second, file.maxSize)
+		if err != nil {
+			fmt.Println(strings.ReplaceAll(err.Error(), server.URL, "URL"))
+		} else {
+			fmt.Printf("%q\n", data)
+		}
+	}
+
+	// Output:
+	// "package main\n"
+	// GET URL/missing.go: 404 Not Found
+	// URL/large.go is larger than the limit of 12 bytes (see -fetch-max-size)
+	// URL/stream.go is larger than the limit of 26 bytes (see -fetch-max-size)
+}
+
+// exampleCommands returns commands whose summaries and flags have the characters that the
+// completions and man pages quote.
+func exampleCommands() []*command {
+	mapping := &command{usage: "[options] left right", summary: "Map the lines of left to the lines of right. The mapping is printed.", flags: flag.NewFlagSet("", flag.ContinueOnError)}
+	mapping.flags.Bool("debug", false, "Print why lines [don't] pair")
+	why := &command{name: "why", usage: "why -left n -right n left right", summary: "Explain why two lines are 'paired', or not.", flags: flag.NewFlagSet("why", flag.ContinueOnError)}
+	why.flags.Int("left", 0, "The `line` of left: one-based")
+	why.flags.String("sep", `\`, "The separator, such as \\ or -")
+	return []*command{mapping, why}
+}
+
+func Example_writeBashCompletion() {
+	writeBashCompletion(os.Stdout, exampleCommands())
+
+	// Output:
+	// # bash completion for lhdiff, generated by lhdiff completion bash
+	// _lhdiff() {
+	// 	local cur=${COMP_WORDS[COMP_CWORD]} flags
+	// 	# Like lhdiff, only the first argument can be the name of a command
+	// 	case ${COMP_WORDS[1]} in
+	// 	why) flags="-left -sep" ;;
+	// 	*) flags="-debug" ;;
+	// 	esac
+	// 	if [[ $cur == -* ]]; then
+	// 		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	// 	elif ((COMP_CWORD == 1)); then
+	// 		COMPREPLY=($(compgen -W "why" -- "$cur") $(compgen -f -- "$cur"))
+	// 	else
+	// 		COMPREPLY=($(compgen -f -- "$cur"))
+	// 	fi
+	// }
+	// complete -o filenames -F _lhdiff lhdiff
+}
+
+func Example_writeZshCompletion() {
+	writeZshCompletion(os.Stdout, exampleCommands())
+
+	// Output:
+	// #compdef lhdiff
+	// # zsh completion for lhdiff, generated by lhdiff completion zsh
+	//
+	// _lhdiff() {
+	// 	local -a subcommands
+	// 	subcommands=(
+	// 		'why:Explain why two lines are '\''paired'\'', or not'
+	// 	)
+	// 	# Like lhdiff, only the first argument can be the name of a command
+	// 	if (( CURRENT == 2 )) && [[ $words[2] != -* ]]; then
+	// 		_describe -t commands command subcommands
+	// 		_files
+	// 		return
+	// 	fi
+	// 	case $words[2] in
+	// 	why)
+	// 		shift words
+	// 		(( CURRENT-- ))
+	// 		_arguments -S '-left[The line of left; one-based]:line:_files' '-sep[The separator, such as \\ or -]:string:_files' '*:file:_files'
+	// 		;;
+	// 	*)
+	// 		_arguments -S '-debug[Print why lines (don'\''t) pair]' '*:file:_files'
+	// 		;;
+	// 	esac
+	// }
+	//
+	// _lhdiff "$@"
+}
+
+func Example_writeFishCompletion() {
+	writeFishCompletion(os.Stdout, exampleCommands())
+
+	// Output:
+	// # fish completion for lhdiff, generated by lhdiff completion fish
+	// complete -c lhdiff -n __fish_use_subcommand -a why -d 'Explain why two lines are \'paired\', or not'
+	// complete -c lhdiff -n 'not __fish_seen_subcommand_from why' -o debug -d 'Print why lines [don\'t] pair'
+	// complete -c lhdiff -n '__fish_seen_subcommand_from why' -o left -r -d 'The line of left: one-based'
+	// complete -c lhdiff -n '__fish_seen_subcommand_from why' -o sep -r -d 'The separator, such as \\ or -'
+}
+
+func Example_writeManPage() {
+	all := exampleCommands()
+	writeManPage(os.Stdout, all[1], all)
+
+	// Output:
+	// .TH LHDIFF-WHY 1 "" "lhdiff" "lhdiff Manual"
+	// .SH NAME
+	// lhdiff-why \- Explain why two lines are 'paired', or not
+	// .SH SYNOPSIS
+	// .B lhdiff
+	// why \-left n \-right n left right
+	// .SH DESCRIPTION
+	// Explain why two lines are 'paired', or not.
+	// .SH OPTIONS
+	// .TP
+	// .B \-left \fIline\fR
+	// The line of left: one\-based
+	// .TP
+	// .B \-sep \fIstring\fR
+	// The separator, such as \e or \- (default \e)
+	// .SH SEE ALSO
+	// .BR lhdiff (1)
+}
+
+func Example_remapBookmarks() {
+	dir, err := ioutil.TempDir("", "bookmarks")
+	check(err)
+	defer os.RemoveAll(dir)
+	oldDir, newDir := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+	check(os.MkdirAll(oldDir, 0755))
+	check(os.MkdirAll(newDir, 0755))
+	check(ioutil.WriteFile(filepath.Join(oldDir, "main.go"), []byte("package main\n\nfunc obsolete(a int) int {\n\treturn a * 42\n}\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"), 0644))
+	check(ioutil.WriteFile(filepath.Join(newDir, "main.go"), []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"), 0644))
+	remapper := &bookmarkRemapper{
+		remapper: lhdiff.NewRemapper(lhdiff.DirSnapshot(oldDir), lhdiff.DirSnapshot(newDir)),
+		newDir:   newDir,
+		buffer:   "main.go",
+	}
+
+	bookmarks := "main.go:7:func main\nmain.go:4:return a * 42\ndeleted.go:1\n# not a bookmark\n"
+	check(remapBookmarks(strings.NewReader(bookmarks), os.Stdout, os.Stdout, remapper, remapLinesBookmark))
+	marks := "mark line  col file/text\n a      8    1 println(\"hello\")\n B      4    8 main.go\n"
+	check(remapBookmarks(strings.NewReader(marks), os.Stdout, os.Stdout, remapper, remapVimMark))
+
+	// Output:
+	// main.go:5:func main
+	// dropped: main.go:4:return a * 42
+	// dropped: deleted.go:1
+	// # not a bookmark
+	// 2 bookmark(s) dropped
+	// mark line  col file/text
+	//  a      6    1 println("hello")
+	// dropped:  B      4    8 main.go
+	// 1 bookmark(s) dropped
+}
+
+func Example_where() {
+	repository := newTestRepository(
+		map[string]string{"main.go": "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"},
+		map[string]string{"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"},
+		map[string]string{"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello, world\")\n}\n"},
+	)
+	defer os.RemoveAll(repository.Dir)
+	genealogy, err := repository.OpenGenealogy(filepath.Join(repository.Dir, ".git", "lhdiff.db"))
+	check(err)
+	defer genealogy.Close()
+
+	check(where(os.Stdout, genealogy, "HEAD~2", "HEAD", "main.go", 4))
+	check(where(os.Stdout, genealogy, "HEAD", "HEAD", "main.go", 5))
+
+	// Output:
+	// main.go:4 at HEAD~2
+	// came from:
+	//   f806b4e 2022-01-01 main.go:4 introduced	revision 0
+	// went to (at HEAD):
+	//   b9e8aa5 2022-01-01 main.go:6 0.64	revision 1
+	//   61fb11b 2022-01-01 main.go:6 0.85	revision 2
+	// main.go:5 at HEAD
+	// came from:
+	//   b9e8aa5 2022-01-01 main.go:5 1.00	revision 1
+	//   f806b4e 2022-01-01 main.go:3 introduced	revision 0
+	// went to (at HEAD):
+	//   unchanged main.go:5
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func newManCommand() *command {
+	cmd := &command{
+		name:    "man",
+		usage:   "man [-o dir] [command]",
+		summary: "Print the man page of lhdiff or of one of its commands, or write all of them to a directory.",
+		flags:   flag.NewFlagSet("man", flag.ExitOnError),
+	}
+	output := cmd.flags.String("o", "", "Write the man pages of lhdiff and of all its commands to lhdiff.1 and lhdiff-<command>.1 in this directory")
+	cmd.run = func(args []string) error {
+		all := documentedCommands()
+		if *output != "" {
+			if len(args) > 0 {
+				cmd.flags.Usage()
+				os.Exit(2)
+			}
+			if err := os.MkdirAll(*output, 0755); err != nil {
+				return err
+			}
+			for _, page := range all {
+				if err := writeManFile(filepath.Join(*output, manName(page)+".1"), page, all); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		switch len(args) {
+		case 0:
+			writeManPage(os.Stdout, all[0], all)
+			return nil
+		case 1:
+			for _, page := range all {
+				if page.name != "" && page.name == args[0] {
+					writeManPage(os.Stdout, page, all)
+					return nil
+				}
+			}
+			return fmt.Errorf("unknown command: %s", args[0])
+		default:
+			cmd.flags.Usage()
+			os.Exit(2)
+			return nil
+		}
+	}
+	return cmd
+}
+
+// manName is the name of the man page of a command, lhdiff for the default one.
+func manName(cmd *command) string {
+	if cmd.name == "" {
+		return "lhdiff"
+	}
+	return "lhdiff-" + cmd.name
+}
+
+func writeManFile(path string, cmd *command, all []*command) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writeManPage(f, cmd, all)
+	return f.Close()
+}
+
+// writeManPage writes the man page of a command in roff. The page of the default command lists
+// the other commands too. There is no date, so that generated pages only change with the
+// commands.
+func writeManPage(w io.Writer, cmd *command, all []*command) {
+	name := manName(cmd)
+	_, _ = fmt.Fprintf(w, ".TH %s 1 \"\" \"lhdiff\" \"lhdiff Manual\"\n", strings.ToUpper(name))
+	_, _ = fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", name, roff(firstSentence(cmd.summary)))
+	_, _ = fmt.Fprintf(w, ".SH SYNOPSIS\n.B lhdiff\n%s\n", roff(cmd.usage))
+	_, _ = fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roff(cmd.summary))
+	if cmd.name == "" {
+		_, _ = fmt.Fprintln(w, ".SH COMMANDS")
+		for _, sub := range all {
+			if sub.name != "" {
+				_, _ = fmt.Fprintf(w, ".TP\n.B %s\n%s\n", sub.name, roff(sub.summary))
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(w, ".SH OPTIONS")
+	for _, f := range commandFlags(cmd) {
+		valueName, usage := flag.UnquoteUsage(f)
+		_, _ = fmt.Fprintf(w, ".TP\n.B \\-%s", roff(f.Name))
+		if valueName != "" {
+			_, _ = fmt.Fprintf(w, " \\fI%s\\fR", roff(valueName))
+		}
+		_, _ = fmt.Fprintf(w, "\n%s", roff(usage))
+		if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" {
+			_, _ = fmt.Fprintf(w, " (default %s)", roff(f.DefValue))
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintln(w, ".SH SEE ALSO")
+	if cmd.name != "" {
+		_, _ = fmt.Fprintln(w, ".BR lhdiff (1)")
+		return
+	}
+	var pages []string
+	for _, sub := range all {
+		if sub.name != "" {
+			pages = append(pages, ".BR "+manName(sub)+" (1)")
+		}
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(pages, ",\n"))
+}
+
+// roff escapes text for a roff line, so that backslashes, dashes and leading dots or quotes
+// are printed rather than interpreted.
+func roff(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`, "\n", " ").Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/avro"
+	"github.com/SmartBear/lhdiff/gettext"
+	"github.com/SmartBear/lhdiff/minified"
+	"github.com/SmartBear/lhdiff/notebook"
+	"github.com/SmartBear/lhdiff/structure"
+	"github.com/SmartBear/lhdiff/table"
+	"os"
+	"strings"
+)
+
+func newMappingCommand() *command {
+	cmd := &command{
+		usage:   "[options] left right",
+		summary: "Print the mapping of lines from the left file to the right file.",
+		flags:   flag.NewFlagSet("lhdiff", flag.ExitOnError),
+	}
+	compact := cmd.flags.Bool("compact", false, "Exclude identical lines from output")
+	format := cmd.flags.String("format", "text", "Output format: text, svg or avro (text and yaml/json modes), json (a mapping file for apply, text mode), delta (a mapping file with only the mappings that differ from the identity, text mode), color-moved (a diff colored like git diff --color-moved=zebra, text mode), edit-script (the moves, edits, insertions and deletions turning left into right, text mode), text or fuzzy (po mode)")
+	mode := cmd.flags.String("mode", "text", "Input mode: text, ipynb (track lines within matched notebook cells), po (track gettext entries), csv/tsv (track rows cell by cell) yaml/json (match the parsed structure) or minified (track the statements of minified JS/CSS by column range)")
+	header := cmd.flags.Bool("header", false, "The first row of CSV/TSV files is a header, and columns are matched by name")
+	keys := cmd.flags.String("keys", "", "Comma-separated key columns (names or one-based numbers) identifying CSV/TSV rows")
+	order := cmd.flags.String("sort", "left", "Order of the output: left (left line order), right (right line order) or similarity (least similar first) (text and yaml/json modes)")
+	summary := cmd.flags.Bool("summary", false, "Print only the number of tracked, moved, modified, lost and added lines instead of the mapping (text and yaml/json modes)")
+	diffFile := cmd.flags.String("diff", "", "Write the unified diff that lines are paired from to this file, or to stdout before the mapping if it is - (text mode)")
+	opts := addOptionFlags(cmd.flags)
+	read := addFetchFlags(cmd.flags)
+	pins := addPinsFlag(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 2 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		sortOrder, err := parseOrder(*order)
+		if err != nil {
+			return err
+		}
+		// A file that doesn't exist is compared as empty, like a file that was added or deleted,
+		// but a URL that can't be downloaded is an error
+		left, err := read(args[0])
+		if err != nil && isURL(args[0]) {
+			return err
+		}
+		right, err := read(args[1])
+		if err != nil && isURL(args[1]) {
+			return err
+		}
+		switch *mode {
+		case "text":
+			pinOpts, err := pins()
+			if err != nil {
+				return err
+			}
+			textOpts := append(append([]lhdiff.Option{lhdiff.WithPath(args[1])}, opts()...), pinOpts...)
+			if *diffFile == "-" {
+				textOpts = append(textOpts, lhdiff.WithDiffOutput(os.Stdout))
+			} else if *diffFile != "" {
+				f, err := os.Create(*diffFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				textOpts = append(textOpts, lhdiff.WithDiffOutput(f))
+			}
+			return compareText(args[1], string(left), string(right), *format, *compact, sortOrder, *summary, textOpts)
+		case "ipynb":
+			return compareNotebooks(left, right, *compact, opts())
+		case "minified":
+			return compareMinified(string(left), string(right), *compact, append([]lhdiff.Option{lhdiff.WithPath(args[1])}, opts()...))
+		case "po":
+			return compareCatalogs(left, right, *format, *compact, opts())
+		case "yaml", "json":
+			return compareStructures(string(left), string(right), *format, *compact, sortOrder, *summary, opts())
+		case "csv":
+			return compareTables(left, right, ',', *header, *keys, *compact)
+		case "tsv":
+			return compareTables(left, right, '\t', *header, *keys, *compact)
+		default:
+			return fmt.Errorf("unknown mode: %s", *mode)
+		}
+	}
+	return cmd
+}
+
+func compareText(path string, left string, right string, format string, compact bool, order lhdiff.Order, summary bool, opts []lhdiff.Option) error {
+	if format == "color-moved" && !summary {
+		// The diff is that of the runs of unchanged lines, which only a SparseResult has
+		result, err := lhdiff.CompareSparse(left, right, opts...)
+		if err != nil {
+			return err
+		}
+		redact := lhdiff.Redaction(opts...)
+		return lhdiff.WriteColorMovedDiff(os.Stdout, redactLines(splitLines(left), redact), redactLines(splitLines(right), redact), result)
+	}
+	result, err := lhdiff.Compare(left, right, opts...)
+	if err != nil {
+		return err
+	}
+	if summary {
+		return printSummary(result)
+	}
+	switch format {
+	case "text":
+		leftLines := lhdiff.ConvertToLinesWithoutNewLine(left)
+		rightLines := lhdiff.ConvertToLinesWithoutNewLine(right)
+		var mappings [][]int
+		for _, mapping := range result.SortedMappings(order) {
+			identical := mapping.Left == mapping.Right && mapping.Left != -1 && leftLines[mapping.Left] == rightLines[mapping.Right]
+			if !(compact && identical) {
+				mappings = append(mappings, []int{mapping.Left, mapping.Right})
+			}
+		}
+		return lhdiff.PrintMappings(mappings)
+	case "svg":
+		return lhdiff.WriteSVG(os.Stdout, result)
+	case "avro":
+		return avro.WriteMappings(os.Stdout, result)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(fileMappings{path: {Result: result}})
+	case "delta":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(fileMappings{path: {Delta: result.Delta()}})
+	case "edit-script":
+		script, err := lhdiff.EditScript(left, right, result)
+		if err != nil {
+			return err
+		}
+		redact := lhdiff.Redaction(opts...)
+		for i := range script {
+			script[i].Lines = redactLines(script[i].Lines, redact)
+		}
+		return lhdiff.WriteEditScript(os.Stdout, script)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// splitLines splits text into the lines that Compare compares, without normalizing them.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.SplitAfter(text, "\n")
+}
+
+// redactLines replaces the contents of lines with redact.
+func redactLines(lines []string, redact func(string) string) []string {
+	redacted := make([]string, len(lines))
+	for i, line := range lines {
+		redacted[i] = redact(line)
+	}
+	return redacted
+}
+
+func compareStructures(left string, right string, format string, compact bool, order lhdiff.Order, summary bool, opts []lhdiff.Option) error {
+	result, err := structure.Compare(left, right, opts...)
+	if err != nil {
+		return err
+	}
+	if summary {
+		return printSummary(result)
+	}
+	switch format {
+	case "text":
+		var mappings [][]int
+		for _, mapping := range result.SortedMappings(order) {
+			if !(compact && mapping.Left == mapping.Right && mapping.Similarity == 1) {
+				mappings = append(mappings, []int{mapping.Left, mapping.Right})
+			}
+		}
+		return lhdiff.PrintMappings(mappings)
+	case "svg":
+		return lhdiff.WriteSVG(os.Stdout, result)
+	case "avro":
+		return avro.WriteMappings(os.Stdout, result)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func parseOrder(order string) (lhdiff.Order, error) {
+	switch order {
+	case "left":
+		return lhdiff.LeftOrder, nil
+	case "right":
+		return lhdiff.RightOrder, nil
+	case "similarity":
+		return lhdiff.SimilarityOrder, nil
+	default:
+		return lhdiff.LeftOrder, fmt.Errorf("unknown sort order: %s", order)
+	}
+}
+
+// printSummary prints the counts of a Summary in the terms of someone checking a change before
+// merging it: tracked lines were found in the right file, some of them moved (reordered) or
+// modified (changed), and lost lines were deleted.
+func printSummary(result *lhdiff.Result) error {
+	summary := result.Summary()
+	_, err := fmt.Printf("%d tracked, %d moved, %d modified, %d lost, %d added\n", summary.Tracked(), summary.Reordered, summary.Changed, summary.Deleted, summary.Added)
+	return err
+}
+
+func compareMinified(left string, right string, compact bool, opts []lhdiff.Option) error {
+	result, err := minified.Compare(left, right, opts...)
+	if err != nil {
+		return err
+	}
+	if compact {
+		var mappings []lhdiff.LineMapping
+		for _, mapping := range result.Mappings {
+			if mapping.Left == -1 || mapping.Right == -1 || mapping.Similarity != 1 || result.LeftSegments[mapping.Left] != result.RightSegments[mapping.Right] {
+				mappings = append(mappings, mapping)
+			}
+		}
+		result.Mappings = mappings
+	}
+	return minified.PrintMappings(os.Stdout, result)
+}
+
+func compareNotebooks(left []byte, right []byte, compact bool, opts []lhdiff.Option) error {
+	leftNotebook, err := notebook.Parse(left)
+	if err != nil {
+		return err
+	}
+	rightNotebook, err := notebook.Parse(right)
+	if err != nil {
+		return err
+	}
+	result, err := notebook.Compare(leftNotebook, rightNotebook, opts...)
+	if err != nil {
+		return err
+	}
+	lines := result.Lines
+	if compact {
+		lines = nil
+		for _, line := range result.Lines {
+			if !(line.LeftCell == line.RightCell && line.LeftLine == line.RightLine && line.Similarity == 1) {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return notebook.PrintLineMappings(os.Stdout, lines)
+}
+
+func compareCatalogs(left []byte, right []byte, format string, compact bool, opts []lhdiff.Option) error {
+	leftEntries, err := gettext.Parse(left)
+	if err != nil {
+		return err
+	}
+	rightEntries, err := gettext.Parse(right)
+	if err != nil {
+		return err
+	}
+	mappings, err := gettext.Compare(leftEntries, rightEntries, opts...)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "text":
+		if compact {
+			kept := mappings[:0]
+			for _, mapping := range mappings {
+				if mapping.Left == -1 || mapping.Right == -1 || leftEntries[mapping.Left].Line != rightEntries[mapping.Right].Line || mapping.Similarity != 1 {
+					kept = append(kept, mapping)
+				}
+			}
+			mappings = kept
+		}
+		return gettext.PrintMappings(os.Stdout, leftEntries, rightEntries, mappings)
+	case "fuzzy":
+		return gettext.PrintFuzzy(os.Stdout, leftEntries, rightEntries, mappings)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func compareTables(left []byte, right []byte, comma rune, header bool, keys string, compact bool) error {
+	leftTable, err := table.Parse(bytes.NewReader(left), comma, header)
+	if err != nil {
+		return err
+	}
+	rightTable, err := table.Parse(bytes.NewReader(right), comma, header)
+	if err != nil {
+		return err
+	}
+	var keyColumns []int
+	if keys != "" {
+		for _, key := range strings.Split(keys, ",") {
+			keyColumn, err := leftTable.Column(key)
+			if err != nil {
+				return err
+			}
+			keyColumns = append(keyColumns, keyColumn)
+		}
+	}
+	mappings := table.Compare(leftTable, rightTable, keyColumns)
+	if compact {
+		kept := mappings[:0]
+		for _, mapping := range mappings {
+			if mapping.Left == -1 || mapping.Right == -1 || leftTable.Rows[mapping.Left].Line != rightTable.Rows[mapping.Right].Line || mapping.Similarity != 1 {
+				kept = append(kept, mapping)
+			}
+		}
+		mappings = kept
+	}
+	return table.PrintMappings(os.Stdout, leftTable, rightTable, mappings)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/repo"
+	"io"
+	"strings"
+)
+
+// markdownRows is the number of rows of the tables of moved blocks and lost lines, beyond which
+// the rows are only counted, so that comments stay readable on large changes.
+const markdownRows = 20
+
+// writeMarkdownStats writes the stats as a Markdown summary for a pull request comment: the
+// counts and churn of each file, and tables of the blocks that moved and of the lines that were
+// lost. The churn of a file is the percentage of its old lines that were modified, moved or
+// deleted.
+func writeMarkdownStats(w io.Writer, stats lhdiff.Stats, files []repo.FileStats) error {
+	var md strings.Builder
+	md.WriteString("### Line tracking\n\n")
+	_, _ = fmt.Fprintf(&md, "%d file(s), %d line(s) tracked (%.1f%% similar on average), %.1f%% churn.\n\n", len(files), stats.Tracked(), 100*stats.Similarity, churn(stats))
+	md.WriteString("| File | Unchanged | Modified | Moved | Deleted | Added | Churn |\n")
+	md.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, file := range files {
+		_, _ = fmt.Fprintf(&md, "| %s | %d | %d | %d | %d | %d | %.1f%% |\n", markdownFile(file), file.Unchanged, file.Changed, file.Reordered, file.Deleted, file.Added, churn(file.Stats))
+	}
+	if len(files) > 1 {
+		_, _ = fmt.Fprintf(&md, "| **Total** | %d | %d | %d | %d | %d | %.1f%% |\n", stats.Unchanged, stats.Changed, stats.Reordered, stats.Deleted, stats.Added, churn(stats))
+	}
+
+	var moves []string
+	for _, file := range files {
+		for _, block := range file.Moves {
+			moves = append(moves, fmt.Sprintf("| %s | %d | %s | %s |\n", markdownFile(file), block.Length, lineRange(block.Left, block.Length), lineRange(block.Right, block.Length)))
+		}
+	}
+	if len(moves) > 0 {
+		md.WriteString("\n#### Moved blocks\n\n| File | Lines | From | To |\n|---|---:|---|---|\n")
+		writeMarkdownRows(&md, moves)
+	}
+
+	var lost []string
+	for _, file := range files {
+		for _, line := range file.Lost {
+			lost = append(lost, fmt.Sprintf("| %s | %d | %s |\n", markdownCode(file.Path), line.Line+1, markdownCode(line.Text)))
+		}
+	}
+	if len(lost) > 0 {
+		md.WriteString("\n#### Lost lines\n\n| File | Line | Text |\n|---|---:|---|\n")
+		writeMarkdownRows(&md, lost)
+	}
+	_, err := io.WriteString(w, md.String())
+	return err
+}
+
+func writeMarkdownRows(md *strings.Builder, rows []string) {
+	for i, row := range rows {
+		if i == markdownRows {
+			_, _ = fmt.Fprintf(md, "\n…and %d more.\n", len(rows)-markdownRows)
+			return
+		}
+		md.WriteString(row)
+	}
+}
+
+func churn(stats lhdiff.Stats) float64 {
+	return percentage(stats.Changed+stats.Reordered+stats.Deleted, stats.LeftLineCount)
+}
+
+// markdownFile formats the path of a file, with its new path if it was renamed.
+func markdownFile(file repo.FileStats) string {
+	switch {
+	case file.Path == "":
+		return markdownCode(file.NewPath) + " (added)"
+	case file.NewPath == "":
+		return markdownCode(file.Path) + " (deleted)"
+	case file.Path != file.NewPath:
+		return markdownCode(file.Path) + " → " + markdownCode(file.NewPath)
+	default:
+		return markdownCode(file.Path)
+	}
+}
+
+// markdownCode formats text as inline code in a table cell. Pipes are escaped, since they would
+// end the cell even in code, and text with backticks is delimited with two of them.
+func markdownCode(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "|", `\|`)
+	if text == "" {
+		return ""
+	}
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/mutation"
+	"io/ioutil"
+	"os"
+)
+
+func newMutationCommand() *command {
+	cmd := &command{
+		name:    "mutation",
+		usage:   "mutation [options] report.json",
+		summary: "Remap a mutation-testing report to the current version of the source files.",
+		flags:   flag.NewFlagSet("mutation", flag.ExitOnError),
+	}
+	format := cmd.flags.String("format", "stryker", "Report format: stryker or go-mutesting")
+	oldDir := cmd.flags.String("old", "", "Directory with the source files the report was generated on, if the report doesn't embed them")
+	newDir := cmd.flags.String("new", ".", "Directory with the current source files")
+	output := cmd.flags.String("o", "", "Write the remapped report to this file instead of stdout")
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 1 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		report, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		current := lhdiff.DirSnapshot(*newDir)
+		var remapped []byte
+		var stats mutation.Stats
+		switch *format {
+		case "stryker":
+			remapped, stats, err = mutation.RemapStryker(report, current, opts()...)
+		case "go-mutesting":
+			var old lhdiff.Snapshot
+			if *oldDir != "" {
+				old = lhdiff.DirSnapshot(*oldDir)
+			}
+			remapped, stats, err = mutation.RemapGoMutesting(report, old, current, opts()...)
+		default:
+			return fmt.Errorf("unknown format: %s", *format)
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "mutants: %s\n", stats)
+		if *output != "" {
+			return ioutil.WriteFile(*output, remapped, 0644)
+		}
+		_, err = os.Stdout.Write(remapped)
+		return err
+	}
+	return cmd
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// addOptionFlags adds the flags configuring comparisons to flags, and returns a function
+// returning the options they set once the flags are parsed.
+func addOptionFlags(flags *flag.FlagSet) func() []lhdiff.Option {
+	dir := flags.String("cache-dir", "", "Cache comparison results in this directory, keyed by the hashes of the files and options")
+	calibrate := flags.Bool("calibrate", false, "Calibrate the similarity threshold from the similarities of unrelated lines of the compared files")
+	adaptive := flags.Bool("adaptive", false, "Weigh the context more than the content of short lines, and less for long lines")
+	contentGate := flags.Float64("content-gate", lhdiff.ContentSimilarityGate, "Only pair lines whose content similarity is above this, whatever their context (negative to disable)")
+	monotonic := flags.Bool("monotonic", false, "Only map lines in an order-preserving way, treating lines that moved across others as deleted and added")
+	reference := flags.Bool("reference", false, "Pair changed lines like the original LHDiff paper describes, to compare results with studies using it")
+	noAnchors := flags.Bool("no-anchors", false, "Treat lhdiff:anchor directives like any other text")
+	minimal := flags.Bool("minimal", false, "Find the smallest set of changed lines, even when that is slow")
+	ignoreBlankLines := flags.Bool("ignore-blank-lines", false, "Only take unchanged lines from non-blank lines, and pair blank lines by similarity")
+	debug := flags.Bool("debug", false, "Print the most similar candidates of each added line, and why it was paired or not, to stderr")
+	redact := flags.String("redact", "", "Replace the contents of lines in the outputs with hashes salted with this, keeping line numbers and similarities")
+	var tokenizer lhdiff.Tokenizer
+	flags.Func("tokenizer", "How contexts are split into terms: whitespace (default) or unicode (split non-ASCII text at word boundaries, and Chinese and Japanese into bigrams)", func(name string) (err error) {
+		tokenizer, err = parseTokenizer(name)
+		return err
+	})
+	var stopWords []string
+	flags.Func("stop-words", "File of words, such as language keywords, left out of the terms of contexts (separated by whitespace, # starts a comment)", func(path string) (err error) {
+		stopWords, err = readStopWords(path)
+		return err
+	})
+	var profiles map[string][]lhdiff.Option
+	flags.Func("config", "YAML file of profiles setting the options of the files with each extension", func(path string) (err error) {
+		profiles, err = readConfig(path)
+		return err
+	})
+	return func() []lhdiff.Option {
+		var opts []lhdiff.Option
+		if *dir != "" {
+			opts = append(opts, lhdiff.WithCache(lhdiff.DirCache(*dir)))
+		}
+		if *calibrate {
+			opts = append(opts, lhdiff.WithCalibratedThreshold())
+		}
+		if *adaptive {
+			opts = append(opts, lhdiff.WithAdaptiveWeighting(lhdiff.DefaultShortLineLength, lhdiff.DefaultLongLineLength))
+		}
+		if *contentGate != lhdiff.ContentSimilarityGate {
+			opts = append(opts, lhdiff.WithContentGate(*contentGate))
+		}
+		if *monotonic {
+			opts = append(opts, lhdiff.WithMonotonic())
+		}
+		if *reference {
+			opts = append(opts, lhdiff.WithReferenceMode())
+		}
+		if *noAnchors {
+			opts = append(opts, lhdiff.WithoutAnchors())
+		}
+		if *minimal || *ignoreBlankLines {
+			opts = append(opts, lhdiff.WithDiffEngine(lhdiff.Myers{Minimal: *minimal, IgnoreBlankLines: *ignoreBlankLines}))
+		}
+		if *redact != "" {
+			opts = append(opts, lhdiff.WithRedaction(*redact))
+		}
+		if *debug {
+			opts = append(opts, lhdiff.WithDebug(os.Stderr, 3))
+		}
+		if tokenizer != nil {
+			opts = append(opts, lhdiff.WithTokenizer(tokenizer))
+		}
+		if stopWords != nil {
+			opts = append(opts, lhdiff.WithStopWords(stopWords...))
+		}
+		if profiles != nil {
+			opts = append(opts, lhdiff.WithProfiles(profiles))
+		}
+		return opts
+	}
+}
+
+func parseTokenizer(name string) (lhdiff.Tokenizer, error) {
+	switch name {
+	case "whitespace":
+		return lhdiff.WhitespaceTokenizer{}, nil
+	case "unicode":
+		return lhdiff.UnicodeTokenizer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tokenizer: %s", name)
+	}
+}
+
+// readStopWords reads a file of stop words separated by whitespace. Text from # to the end of
+// a line is a comment.
+func readStopWords(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	return words, nil
+}
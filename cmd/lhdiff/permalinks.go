@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func newPermalinksCommand() *command {
+	cmd := &command{
+		name:    "permalinks",
+		usage:   "permalinks [options] file...",
+		summary: "Rewrite GitHub permalinks with #L line fragments to point at a newer revision.",
+		flags:   flag.NewFlagSet("permalinks", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository the links point into")
+	to := cmd.flags.String("to", "HEAD", "Revision to rewrite the links to")
+	ownerAndName := cmd.flags.String("repo", "", "Only rewrite links to this owner/name repository")
+	write := cmd.flags.Bool("w", false, "Write the rewritten files in place instead of printing them")
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) == 0 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		repository, err := openRepository()
+		if err != nil {
+			return err
+		}
+		dead := 0
+		for _, path := range args {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			fixed, fixes, err := repository.FixPermalinks(string(content), *to, *ownerAndName, opts()...)
+			if err != nil {
+				return err
+			}
+			for _, fix := range fixes {
+				if fix.Problem != "" {
+					dead++
+					_, _ = fmt.Fprintf(os.Stderr, "%s: %s: %s\n", path, fix.Problem, fix.Old)
+				}
+			}
+			if *write {
+				if fixed != string(content) {
+					err = ioutil.WriteFile(path, []byte(fixed), 0644)
+				}
+			} else {
+				_, err = fmt.Print(fixed)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if dead > 0 {
+			return fmt.Errorf("%d link(s) could not be fixed", dead)
+		}
+		return nil
+	}
+	return cmd
+}
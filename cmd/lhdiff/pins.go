@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readPins reads a pins file with one-based left,right pairs of lines, one per line, in the
+// format printed by the mapping command. Blank lines, # comments and pairs with a _ (deleted
+// or added lines) are ignored, so the output of a previous run can be used as is.
+func readPins(path string) ([]lhdiff.Pin, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var pins []lhdiff.Pin
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.Contains(line, "_") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected left,right: %s", path, lineNumber, line)
+		}
+		left, leftErr := strconv.Atoi(strings.TrimSpace(fields[0]))
+		right, rightErr := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if leftErr != nil || rightErr != nil {
+			return nil, fmt.Errorf("%s:%d: expected left,right: %s", path, lineNumber, line)
+		}
+		pins = append(pins, lhdiff.Pin{Left: left - 1, Right: right - 1})
+	}
+	return pins, scanner.Err()
+}
+
+// addPinsFlag adds the -pins flag to flags, and returns a function returning the option it
+// sets once the flags are parsed.
+func addPinsFlag(flags *flag.FlagSet) func() ([]lhdiff.Option, error) {
+	path := flags.String("pins", "", "File of left,right pairs of lines (one-based) known to correspond, paired before the other lines")
+	return func() ([]lhdiff.Option, error) {
+		if *path == "" {
+			return nil, nil
+		}
+		pins, err := readPins(*path)
+		if err != nil {
+			return nil, err
+		}
+		return []lhdiff.Option{lhdiff.WithPins(pins)}, nil
+	}
+}
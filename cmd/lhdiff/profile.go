@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// addProfileFlags adds the -cpuprofile, -memprofile and -trace flags to flags, and returns a
+// function starting the profiles they ask for once the flags are parsed. That function returns
+// a function stopping them and writing them out.
+func addProfileFlags(flags *flag.FlagSet) func() (func() error, error) {
+	cpuProfile := flags.String("cpuprofile", "", "Write a CPU profile to this file")
+	memProfile := flags.String("memprofile", "", "Write a heap profile to this file when the command is done")
+	traceFile := flags.String("trace", "", "Write an execution trace to this file")
+	return func() (func() error, error) {
+		var stops []func() error
+		stop := func() error {
+			var err error
+			for i := len(stops) - 1; i >= 0; i-- {
+				if stopErr := stops[i](); err == nil {
+					err = stopErr
+				}
+			}
+			return err
+		}
+		if *cpuProfile != "" {
+			f, err := os.Create(*cpuProfile)
+			if err != nil {
+				return nil, err
+			}
+			if err := pprof.StartCPUProfile(f); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			stops = append(stops, func() error {
+				pprof.StopCPUProfile()
+				return f.Close()
+			})
+		}
+		if *traceFile != "" {
+			f, err := os.Create(*traceFile)
+			if err != nil {
+				_ = stop()
+				return nil, err
+			}
+			if err := trace.Start(f); err != nil {
+				_ = f.Close()
+				_ = stop()
+				return nil, err
+			}
+			stops = append(stops, func() error {
+				trace.Stop()
+				return f.Close()
+			})
+		}
+		if *memProfile != "" {
+			path := *memProfile
+			stops = append(stops, func() error {
+				f, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				// Get up-to-date statistics of the memory in use
+				runtime.GC()
+				err = pprof.WriteHeapProfile(f)
+				if closeErr := f.Close(); err == nil {
+					err = closeErr
+				}
+				return err
+			})
+		}
+		return stop, nil
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"flag"
+	"github.com/SmartBear/lhdiff/repo"
+)
+
+// addRepositoryFlags adds the flags choosing the git repository and how its files are read to
+// flags, and returns a function opening the repository once the flags are parsed.
+func addRepositoryFlags(flags *flag.FlagSet, usage string) func() (*repo.Repository, error) {
+	dir := flags.String("C", ".", usage)
+	textConv := flags.Bool("textconv", false, "Read files through the textconv drivers of their diff attributes, as git diff shows them")
+	filters := flags.Bool("filters", false, "Read files through their smudge filters and end-of-line conversions, as they are checked out (such as the contents of Git LFS files)")
+	return func() (*repo.Repository, error) {
+		repository, err := repo.Open(*dir)
+		if err != nil {
+			return nil, err
+		}
+		repository.TextConv, repository.Filters = *textConv, *filters
+		return repository, nil
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff/review"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func newReviewCommand() *command {
+	cmd := &command{
+		name:    "review",
+		usage:   "review [options] -provider name -change id -from version -to version",
+		summary: "Remap the inline comments of a code review from one version of the change to another.",
+		flags:   flag.NewFlagSet("review", flag.ExitOnError),
+	}
+	provider := cmd.flags.String("provider", "", "Review provider: gerrit, gitlab or bitbucket")
+	serverURL := cmd.flags.String("url", "", "URL of the server (default: gitlab.com or bitbucket.org)")
+	project := cmd.flags.String("project", "", "GitLab project (ID or path) or Bitbucket workspace/repository")
+	change := cmd.flags.String("change", "", "Gerrit change, GitLab merge request or Bitbucket pull request")
+	from := cmd.flags.String("from", "", "Version the comments were made on: a Gerrit patchset, or a commit SHA")
+	to := cmd.flags.String("to", "", "Version to remap the comments to")
+	write := cmd.flags.Bool("w", false, "Write the remapped comments back to the provider")
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 0 || *change == "" || *from == "" || *to == "" {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		reviewProvider, err := newReviewProvider(*provider, *serverURL, *project, *change)
+		if err != nil {
+			return err
+		}
+		remappings, err := review.Sync(reviewProvider, *from, *to, *write, opts()...)
+		if err != nil {
+			return err
+		}
+		for _, remapping := range remappings {
+			target := "deleted"
+			if !remapping.Deleted {
+				target = fmt.Sprintf("%s:%d", remapping.New.Path, remapping.New.Line)
+			}
+			_, err := fmt.Printf("%s\t%s:%d\t%s\n", remapping.Old.ID, remapping.Old.Path, remapping.Old.Line, target)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return cmd
+}
+
+// newReviewProvider returns the named provider, authenticated with credentials from the
+// environment.
+func newReviewProvider(name string, serverURL string, project string, change string) (review.ReviewProvider, error) {
+	switch name {
+	case "gerrit":
+		return &review.Gerrit{URL: serverURL, Username: os.Getenv("GERRIT_USERNAME"), Password: os.Getenv("GERRIT_PASSWORD"), Change: change}, nil
+	case "gitlab":
+		mergeRequest, err := strconv.Atoi(change)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge request: %s", change)
+		}
+		return &review.GitLab{URL: serverURL, Token: os.Getenv("GITLAB_TOKEN"), Project: project, MergeRequest: mergeRequest}, nil
+	case "bitbucket":
+		pullRequest, err := strconv.Atoi(change)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pull request: %s", change)
+		}
+		parts := strings.SplitN(project, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid Bitbucket repository, expected workspace/repository: %s", project)
+		}
+		return &review.Bitbucket{
+			URL:         serverURL,
+			Username:    os.Getenv("BITBUCKET_USERNAME"),
+			Password:    os.Getenv("BITBUCKET_APP_PASSWORD"),
+			Token:       os.Getenv("BITBUCKET_TOKEN"),
+			Workspace:   parts[0],
+			Repository:  parts[1],
+			PullRequest: pullRequest,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", name)
+	}
+}
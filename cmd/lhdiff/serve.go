@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+	"github.com/SmartBear/lhdiff/server"
+	"os"
+)
+
+func newServeCommand() *command {
+	cmd := &command{
+		name:    "serve",
+		usage:   "serve",
+		summary: "Serve JSON-RPC position translation requests on stdin/stdout.",
+		flags:   flag.NewFlagSet("serve", flag.ExitOnError),
+	}
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		return server.New(opts()...).Serve(os.Stdin, os.Stdout)
+	}
+	return cmd
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/repo"
+	"os"
+	"strings"
+)
+
+func newStatsCommand() *command {
+	cmd := &command{
+		name:    "stats",
+		usage:   "stats [options] left right | from..to",
+		summary: "Print how many lines were unchanged, modified, moved, deleted and added between two files or revisions.",
+		flags:   flag.NewFlagSet("stats", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository, with from..to")
+	format := cmd.flags.String("format", "text", "Output format: text, json, markdown (for a pull request comment) or codequality (a GitLab Code Quality report of the lost lines and of the pairs less similar than -below)")
+	below := cmd.flags.Float64("below", 0.6, "With -format codequality, report the pairs of lines less similar than this")
+	opts := addOptionFlags(cmd.flags)
+	read := addFetchFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		var stats lhdiff.Stats
+		var files []repo.FileStats
+		switch {
+		case len(args) == 2:
+			left, err := read(args[0])
+			if err != nil {
+				return err
+			}
+			right, err := read(args[1])
+			if err != nil {
+				return err
+			}
+			result, err := lhdiff.Compare(string(left), string(right), append([]lhdiff.Option{lhdiff.WithPath(args[1])}, opts()...)...)
+			if err != nil {
+				return err
+			}
+			file := repo.NewFileStats(args[0], args[1], string(left), result)
+			stats, files = file.Stats, []repo.FileStats{file}
+		case len(args) == 1 && strings.Contains(args[0], ".."):
+			revisions := strings.SplitN(args[0], "..", 2)
+			repository, err := openRepository()
+			if err != nil {
+				return err
+			}
+			repositoryStats, err := repository.Stats(revisions[0], revisions[1], opts()...)
+			if err != nil {
+				return err
+			}
+			stats, files = repositoryStats.Total, repositoryStats.Files
+		default:
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		redact := lhdiff.Redaction(opts()...)
+		for _, file := range files {
+			for i := range file.Lost {
+				file.Lost[i].Text = redact(file.Lost[i].Text)
+			}
+			for i := range file.Lines {
+				file.Lines[i] = redact(file.Lines[i])
+			}
+		}
+		switch *format {
+		case "text":
+			printStats(stats, largestMove(files), len(files))
+			return nil
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(newJSONStats(stats, files))
+		case "markdown":
+			return writeMarkdownStats(os.Stdout, stats, files)
+		case "codequality":
+			return writeCodeQuality(os.Stdout, files, *below)
+		default:
+			return fmt.Errorf("unknown format: %s", *format)
+		}
+	}
+	return cmd
+}
+
+// largestMove returns the file with the largest move, or nil if nothing moved.
+func largestMove(files []repo.FileStats) *repo.FileStats {
+	var largest *repo.FileStats
+	for i := range files {
+		if files[i].LargestMove.Length > 0 && (largest == nil || files[i].LargestMove.Length > largest.LargestMove.Length) {
+			largest = &files[i]
+		}
+	}
+	return largest
+}
+
+func printStats(stats lhdiff.Stats, moved *repo.FileStats, files int) {
+	printField("files", fmt.Sprintf("%d", files))
+	printField("lines", fmt.Sprintf("%d left, %d right", stats.LeftLineCount, stats.RightLineCount))
+	printField("unchanged", count(stats.Unchanged, stats.LeftLineCount))
+	printField("modified", count(stats.Changed, stats.LeftLineCount))
+	printField("moved", count(stats.Reordered, stats.LeftLineCount))
+	printField("deleted", count(stats.Deleted, stats.LeftLineCount))
+	printField("added", count(stats.Added, stats.RightLineCount))
+	printField("similarity", fmt.Sprintf("%.3f (average of the tracked lines)", stats.Similarity))
+	printField("displacement", fmt.Sprintf("%.1f line(s) on average, at most %+d", stats.MeanDisplacement, stats.MaxDisplacement))
+	if moved != nil {
+		block := moved.LargestMove
+		printField("largest move", fmt.Sprintf("%d line(s), %s:%s -> %s:%s", block.Length, moved.Path, lineRange(block.Left, block.Length), moved.NewPath, lineRange(block.Right, block.Length)))
+	}
+}
+
+// count formats a count of lines along with its percentage of total lines.
+func count(lines int, total int) string {
+	return fmt.Sprintf("%d (%.1f%%)", lines, percentage(lines, total))
+}
+
+func percentage(lines int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(lines) / float64(total)
+}
+
+// lineRange formats a block of lines starting at a zero-based line as a one-based range.
+func lineRange(start int, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d-%d", start+1, start+length)
+}
+
+// jsonStats is the JSON form of the stats. Percentages are of the left lines, and of the right
+// lines for added lines. Lines are one-based.
+type jsonStats struct {
+	Files             int       `json:"files"`
+	LeftLines         int       `json:"leftLines"`
+	RightLines        int       `json:"rightLines"`
+	Unchanged         int       `json:"unchanged"`
+	Modified          int       `json:"modified"`
+	Moved             int       `json:"moved"`
+	Deleted           int       `json:"deleted"`
+	Added             int       `json:"added"`
+	UnchangedPercent  float64   `json:"unchangedPercent"`
+	ModifiedPercent   float64   `json:"modifiedPercent"`
+	MovedPercent      float64   `json:"movedPercent"`
+	DeletedPercent    float64   `json:"deletedPercent"`
+	AddedPercent      float64   `json:"addedPercent"`
+	AverageSimilarity float64   `json:"averageSimilarity"`
+	MeanDisplacement  float64   `json:"meanDisplacement"`
+	MaxDisplacement   int       `json:"maxDisplacement"`
+	LargestMove       *jsonMove `json:"largestMove,omitempty"`
+}
+
+type jsonMove struct {
+	Path    string `json:"path,omitempty"`
+	NewPath string `json:"newPath,omitempty"`
+	Left    int    `json:"left"`
+	Right   int    `json:"right"`
+	Length  int    `json:"length"`
+}
+
+func newJSONStats(stats lhdiff.Stats, files []repo.FileStats) jsonStats {
+	s := jsonStats{
+		Files:             len(files),
+		LeftLines:         stats.LeftLineCount,
+		RightLines:        stats.RightLineCount,
+		Unchanged:         stats.Unchanged,
+		Modified:          stats.Changed,
+		Moved:             stats.Reordered,
+		Deleted:           stats.Deleted,
+		Added:             stats.Added,
+		UnchangedPercent:  percentage(stats.Unchanged, stats.LeftLineCount),
+		ModifiedPercent:   percentage(stats.Changed, stats.LeftLineCount),
+		MovedPercent:      percentage(stats.Reordered, stats.LeftLineCount),
+		DeletedPercent:    percentage(stats.Deleted, stats.LeftLineCount),
+		AddedPercent:      percentage(stats.Added, stats.RightLineCount),
+		AverageSimilarity: stats.Similarity,
+		MeanDisplacement:  stats.MeanDisplacement,
+		MaxDisplacement:   stats.MaxDisplacement,
+	}
+	if moved := largestMove(files); moved != nil {
+		block := moved.LargestMove
+		s.LargestMove = &jsonMove{Path: moved.Path, NewPath: moved.NewPath, Left: block.Left + 1, Right: block.Right + 1, Length: block.Length}
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff/repo"
+	"os"
+)
+
+func newSurvivalCommand() *command {
+	cmd := &command{
+		name:    "survival",
+		usage:   "survival [options] from to",
+		summary: "Report the fraction of the lines of each file at from that are unchanged, modified, moved and deleted at to.",
+		flags:   flag.NewFlagSet("survival", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	opts := addOptionFlags(cmd.flags)
+	checkpoint := addCheckpointFlag(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 2 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		repository, err := openRepository()
+		if err != nil {
+			return err
+		}
+		options, closeCheckpoint, err := checkpoint(opts())
+		if err != nil {
+			return err
+		}
+		defer closeCheckpoint()
+		survival, err := repository.Survival(args[0], args[1], options...)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Println("survived\tunchanged\tmodified\tmoved\tdeleted\tlines\tfile")
+		for _, file := range survival.Files {
+			name := file.Path
+			if file.NewPath != "" && file.NewPath != file.Path {
+				name = fmt.Sprintf("%s -> %s", file.Path, file.NewPath)
+			}
+			if err := printSurvival(file, name); err != nil {
+				return err
+			}
+		}
+		return printSurvival(survival.Total, "total")
+	}
+	return cmd
+}
+
+func printSurvival(file repo.FileSurvival, name string) error {
+	_, err := fmt.Printf("%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%d\t%s\n",
+		100*file.Survived(), 100*file.Fraction(file.Unchanged), 100*file.Fraction(file.Modified),
+		100*file.Fraction(file.Moved), 100*file.Fraction(file.Deleted), file.Lines, name)
+	return err
+}
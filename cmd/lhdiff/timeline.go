@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strconv"
+)
+
+func newTimelineCommand() *command {
+	cmd := &command{
+		name:    "timeline",
+		usage:   "timeline [options] path:line",
+		summary: "Print the versions of a line as JSON, with its location and content at each commit that changed it.",
+		flags:   flag.NewFlagSet("timeline", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	at := cmd.flags.String("at", "HEAD", "Revision the line number refers to")
+	to := cmd.flags.String("to", "HEAD", "Revision to follow the line to")
+	db := addGenealogyFlag(cmd.flags)
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		var match []string
+		if len(args) == 1 {
+			match = pathAndLine.FindStringSubmatch(args[0])
+		}
+		if match == nil {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		path := match[1]
+		line, _ := strconv.Atoi(match[2])
+		genealogy, err := openGenealogy(openRepository, *db, opts())
+		if err != nil {
+			return err
+		}
+		defer genealogy.Close()
+		for _, rev := range []string{*at, *to} {
+			if _, err := genealogy.Update(rev); err != nil {
+				return err
+			}
+		}
+		entries, err := genealogy.Timeline(*at, *to, path, line)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+	return cmd
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff/repo"
+	"os"
+	"strings"
+	"time"
+)
+
+func newTodosCommand() *command {
+	cmd := &command{
+		name:    "todos",
+		usage:   "todos [options]",
+		summary: "Report the true age and movement history of TODO/FIXME comments across a commit range.",
+		flags:   flag.NewFlagSet("todos", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	from := cmd.flags.String("from", "", "Start of the commit range (default: the whole history)")
+	to := cmd.flags.String("to", "HEAD", "End of the commit range")
+	keywords := cmd.flags.String("keywords", "TODO,FIXME", "Comma-separated marker keywords")
+	all := cmd.flags.Bool("all", false, "Include resolved markers")
+	history := cmd.flags.Bool("history", false, "Print the movement history of each marker")
+	opts := addOptionFlags(cmd.flags)
+	checkpoint := addCheckpointFlag(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 0 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		repository, err := openRepository()
+		if err != nil {
+			return err
+		}
+		options, closeCheckpoint, err := checkpoint(opts())
+		if err != nil {
+			return err
+		}
+		defer closeCheckpoint()
+		markers, err := repository.TrackMarkers(*from, *to, repo.MarkerPattern(strings.Split(*keywords, ",")), options...)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, marker := range markers {
+			if marker.Resolved != nil && !*all {
+				continue
+			}
+			introduced := marker.Introduced()
+			current := marker.Current()
+			end := now
+			status := ""
+			if marker.Resolved != nil {
+				end = marker.Resolved.Time
+				status = fmt.Sprintf(" resolved in %s", marker.Resolved.Short())
+			}
+			age := fmt.Sprintf("%dd", int(end.Sub(introduced.Commit.Time).Hours()/24))
+			if marker.IntroducedBefore {
+				age = ">=" + age
+			}
+			_, err := fmt.Printf("%s:%d\t%s\t%s\tintroduced in %s %s%s, moved %d time(s)\t%s\n",
+				current.Path, current.Line, marker.Keyword, age,
+				introduced.Commit.Short(), introduced.Commit.Time.Format("2006-01-02"), status,
+				len(marker.History)-1, current.Text)
+			if err != nil {
+				return err
+			}
+			if *history {
+				for _, location := range marker.History {
+					_, err := fmt.Printf("\t%s %s %s:%d\t%s\n", location.Commit.Short(), location.Commit.Time.Format("2006-01-02"), location.Path, location.Line, location.Text)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff/repo"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+func newWhereCommand() *command {
+	cmd := &command{
+		name:    "where",
+		usage:   "where [options] path:line",
+		summary: "Print where a line came from and where it went, using the genealogy database.",
+		flags:   flag.NewFlagSet("where", flag.ExitOnError),
+	}
+	openRepository := addRepositoryFlags(cmd.flags, "Directory of the git repository")
+	at := cmd.flags.String("at", "HEAD", "Revision the line number refers to")
+	to := cmd.flags.String("to", "HEAD", "Revision to follow the line to")
+	db := addGenealogyFlag(cmd.flags)
+	opts := addOptionFlags(cmd.flags)
+	cmd.run = func(args []string) error {
+		var match []string
+		if len(args) == 1 {
+			match = pathAndLine.FindStringSubmatch(args[0])
+		}
+		if match == nil {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		path := match[1]
+		line, _ := strconv.Atoi(match[2])
+		genealogy, err := openGenealogy(openRepository, *db, opts())
+		if err != nil {
+			return err
+		}
+		defer genealogy.Close()
+		return where(os.Stdout, genealogy, *at, *to, path, line)
+	}
+	return cmd
+}
+
+// where writes where a one-based line of path at a revision came from, and where it is at
+// another revision.
+func where(w io.Writer, genealogy *repo.Genealogy, at string, to string, path string, line int) error {
+	// The commits up to both revisions must be indexed
+	for _, rev := range []string{at, to} {
+		if _, err := genealogy.Update(rev); err != nil {
+			return err
+		}
+	}
+	origin, err := genealogy.Origin(at, path, line)
+	if err != nil {
+		return err
+	}
+	followed, err := genealogy.Follow(at, to, path, line)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "%s:%d at %s\n", path, line, at)
+	_, _ = fmt.Fprintln(w, "came from:")
+	printHops(w, origin)
+	_, _ = fmt.Fprintf(w, "went to (at %s):\n", to)
+	if len(followed) == 0 {
+		_, err = fmt.Fprintf(w, "  unchanged %s:%d\n", path, line)
+		return err
+	}
+	printHops(w, followed)
+	return nil
+}
+
+var /* const */ pathAndLine = regexp.MustCompile(`^(.+):(\d+)$`)
+
+func printHops(w io.Writer, hops []repo.Hop) {
+	for _, hop := range hops {
+		change := fmt.Sprintf("%.2f", hop.Similarity)
+		if hop.Introduced {
+			change = "introduced"
+		} else if hop.Deleted {
+			change = "deleted"
+		}
+		_, _ = fmt.Fprintf(w, "  %s %s %s:%d %s\t%s\n", hop.Commit.Short(), hop.Commit.Time.Format("2006-01-02"), hop.Path, hop.Line, change, hop.Commit.Subject)
+	}
+}
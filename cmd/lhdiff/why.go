@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"strings"
+)
+
+func newWhyCommand() *command {
+	cmd := &command{
+		name:    "why",
+		usage:   "why [options] -left line -right line left right",
+		summary: "Explain why a left line and a right line were paired or not.",
+		flags:   flag.NewFlagSet("why", flag.ExitOnError),
+	}
+	leftLine := cmd.flags.Int("left", 0, "Line of the left file (one-based)")
+	rightLine := cmd.flags.Int("right", 0, "Line of the right file (one-based)")
+	opts := addOptionFlags(cmd.flags)
+	read := addFetchFlags(cmd.flags)
+	pins := addPinsFlag(cmd.flags)
+	cmd.run = func(args []string) error {
+		if len(args) != 2 || *leftLine == 0 || *rightLine == 0 {
+			cmd.flags.Usage()
+			os.Exit(2)
+		}
+		left, err := read(args[0])
+		if err != nil {
+			return err
+		}
+		right, err := read(args[1])
+		if err != nil {
+			return err
+		}
+		pinOpts, err := pins()
+		if err != nil {
+			return err
+		}
+		explanation, err := lhdiff.Explain(string(left), string(right), *leftLine-1, *rightLine-1, append(opts(), pinOpts...)...)
+		if err != nil {
+			return err
+		}
+		verdict := "not paired"
+		if explanation.Paired {
+			verdict = "paired"
+		}
+		printField(fmt.Sprintf("left %d", *leftLine), fmt.Sprintf("%q", strings.TrimSuffix(explanation.LeftContent, "\n")))
+		printField(fmt.Sprintf("right %d", *rightLine), fmt.Sprintf("%q", strings.TrimSuffix(explanation.RightContent, "\n")))
+		printField("left context", fmt.Sprintf("%q", explanation.LeftContext))
+		printField("right context", fmt.Sprintf("%q", explanation.RightContext))
+		printField("content similarity", fmt.Sprintf("%.3f (weight %.2f, must be above %g)", explanation.ContentSimilarity, explanation.ContentFactor, explanation.ContentGate))
+		printField("context similarity", fmt.Sprintf("%.3f (weight %.2f)", explanation.ContextSimilarity, explanation.ContextFactor))
+		printField("similarity", fmt.Sprintf("%.3f (threshold %.3f)", explanation.Similarity, explanation.Threshold))
+		_, err = fmt.Printf("%s: %s\n", verdict, explanation.Reason)
+		return err
+	}
+	return cmd
+}
+
+func printField(name string, value string) {
+	_, _ = fmt.Printf("%-20s %s\n", name+":", value)
+}
@@ -0,0 +1,88 @@
+// Command lhdiffd is a long-running daemon serving the JSON-RPC methods of the server package
+// on a unix socket, so that editors and hooks comparing files many times a minute don't start
+// a process for each comparison, and share an in-memory cache of results. With -workspace, it
+// watches the files of a directory and translates their positions without loading them first.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/server"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+func main() {
+	socket := flag.String("socket", defaultSocket(), "Path of the unix socket to listen on")
+	cacheSize := flag.Int("cache-size", 1000, "Number of comparison results kept in memory")
+	workspace := flag.String("workspace", "", "Directory whose files are watched, so that their positions are translated from the versions first seen to those on disk")
+	poll := flag.Duration("poll", 500*time.Millisecond, "How often the files of -workspace are checked for changes")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		_, _ = fmt.Fprintf(out, "Usage: lhdiffd [options]\n\nServe JSON-RPC position translation requests on a unix socket.\n\nOptions:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := serve(*socket, *cacheSize, *workspace, *poll); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// defaultSocket returns the socket in the runtime directory of the user, or in the temporary
+// directory if there is none.
+func defaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "lhdiffd.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("lhdiffd-%d.sock", os.Getuid()))
+}
+
+func serve(socket string, cacheSize int, root string, poll time.Duration) error {
+	// A socket left behind by a daemon that didn't stop cleanly is removed, but not one that
+	// another daemon is listening on
+	if conn, err := net.Dial("unix", socket); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("lhdiffd is already listening on %s", socket)
+	}
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	// The buffers of the clients are only for the user running the daemon
+	if err := os.Chmod(socket, 0600); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// Closing the listener removes the socket, and stops ServeListener
+		_ = listener.Close()
+	}()
+	cache := lhdiff.WithCache(lhdiff.NewMemoryCache(cacheSize))
+	if root == "" {
+		return server.ServeListener(listener, cache)
+	}
+	workspace, err := server.NewWorkspace(root, cache)
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	workspace.Watch(poll)
+	defer workspace.Close()
+	return server.ServeWorkspace(listener, workspace, cache)
+}
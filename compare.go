@@ -0,0 +1,128 @@
+package lhdiff
+
+// LineMapping maps a line in the left file to a line in the right file. Line numbers are
+// zero-based, and -1 means that the line has no counterpart (deleted from left or added to right).
+type LineMapping struct {
+	Left       int
+	Right      int
+	Similarity float64
+	// WhitespaceOnly is true if the lines are paired but aren't equal, and only differ in
+	// whitespace. Lines are compared after the normalization of ConvertToLinesWithoutNewLine, so
+	// lines that only differ in indentation or in runs of spaces are unchanged instead.
+	WhitespaceOnly bool `json:",omitempty"`
+}
+
+// Displacement returns how many lines the line moved, down if it is positive and up if it is
+// negative, and 0 for deleted and added lines.
+func (mapping LineMapping) Displacement() int {
+	if mapping.Left == -1 || mapping.Right == -1 {
+		return 0
+	}
+	return mapping.Right - mapping.Left
+}
+
+// Result is the result of comparing two files.
+type Result struct {
+	// Mappings has one mapping for each left line, in left line order, followed by the
+	// right lines that were added.
+	Mappings       []LineMapping
+	LeftLineCount  int
+	RightLineCount int
+	// Moved are the pairs that were left out of Mappings because they crossed other pairs, with
+	// WithMonotonic. They are in left line order.
+	Moved []LineMapping `json:",omitempty"`
+	// Truncated is true if some added lines weren't compared with the deleted ones, because of
+	// WithMaxComparisons or WithDeadline
+	Truncated bool `json:",omitempty"`
+	// Partial is true if some added lines weren't compared with the deleted ones, because the
+	// context of WithContext was done
+	Partial bool `json:",omitempty"`
+	// Anchors are the lines with lhdiff:anchor directives, which are paired by their ids before
+	// anything else. They are in Mappings as well.
+	Anchors []Anchor `json:",omitempty"`
+}
+
+// Compare maps the lines of left to the lines of right, along with the similarity of each
+// mapped pair of lines. Unchanged lines have a similarity of 1.
+func Compare(left string, right string, opts ...Option) (*Result, error) {
+	o := newOptions(opts)
+	if err := checkLimits(lineCount(left), lineCount(right), o); err != nil {
+		return nil, err
+	}
+	var key string
+	if o.cache != nil {
+		key = cacheKey(left, right, o)
+		if result, ok := o.cached(key); ok {
+			return result, nil
+		}
+	}
+	return compareLines(ConvertToLinesWithoutNewLine(left), ConvertToLinesWithoutNewLine(right), key, o)
+}
+
+// LhdiffLines is like Compare, for files that are already split into lines, such as lines read
+// with a bufio.Scanner. Each element of left and right is a line, with or without its line
+// ending, and mustn't contain any other newline. The lines are normalized like
+// ConvertToLinesWithoutNewLine does, without modifying left and right.
+func LhdiffLines(left []string, right []string, opts ...Option) (*Result, error) {
+	o := newOptions(opts)
+	// Normalizing millions of lines takes longer than rejecting them
+	if err := checkLimits(len(left), len(right), o); err != nil {
+		return nil, err
+	}
+	return compareNormalizedLines(normalizeLines(left), normalizeLines(right), o)
+}
+
+// compareNormalizedLines is LhdiffLines for lines that are normalized already.
+func compareNormalizedLines(leftLines []string, rightLines []string, o *options) (*Result, error) {
+	if err := checkLimits(len(leftLines), len(rightLines), o); err != nil {
+		return nil, err
+	}
+	var key string
+	if o.cache != nil {
+		key = linesCacheKey(leftLines, rightLines, o)
+		if result, ok := o.cached(key); ok {
+			return result, nil
+		}
+	}
+	return compareLines(leftLines, rightLines, key, o)
+}
+
+// compareLines compares normalized lines, and caches the result under key if there is a cache.
+func compareLines(leftLines []string, rightLines []string, key string, o *options) (*Result, error) {
+	pairs, err := computePairs(leftLines, rightLines, o)
+	if err != nil {
+		return nil, err
+	}
+	result := newSparseResult(pairs, len(leftLines), len(rightLines)).Expand()
+	if o.monotonic {
+		result.Moved = demoteCrossings(result)
+	}
+	if o.cache != nil && !result.Truncated && !result.Partial {
+		o.cache.Put(key, result)
+	}
+	return result, nil
+}
+
+// RightLine returns the right line that a left line maps to and the similarity of the pair.
+// The boolean is false if the left line was deleted.
+func (result *Result) RightLine(left int) (int, float64, bool) {
+	if left < 0 || left >= result.LeftLineCount || left >= len(result.Mappings) {
+		return -1, 0, false
+	}
+	mapping := result.Mappings[left]
+	return mapping.Right, mapping.Similarity, mapping.Right != -1
+}
+
+// LeftLine returns the left line that a right line maps to and the similarity of the pair.
+// The boolean is false if the right line was added.
+func (result *Result) LeftLine(right int) (int, float64, bool) {
+	if right < 0 || right >= result.RightLineCount {
+		return -1, 0, false
+	}
+	for _, mapping := range result.Mappings {
+		if mapping.Right == right {
+			return mapping.Left, mapping.Similarity, mapping.Left != -1
+		}
+	}
+	return -1, 0, false
+}
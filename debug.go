@@ -0,0 +1,55 @@
+package lhdiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// candidateLog records the most similar candidates of each added right line for WithDebug.
+type candidateLog struct {
+	w      io.Writer
+	count  int
+	redact func(string) string
+	// top[i] are the best candidates of the i-th added right line, most similar first
+	top          [][]LinePair
+	similarities [][]float64
+}
+
+func (log *candidateLog) add(candidates bySimilarity) {
+	n := log.count
+	if n > len(candidates.pairs) {
+		n = len(candidates.pairs)
+	}
+	log.top = append(log.top, append([]LinePair(nil), candidates.pairs[:n]...))
+	log.similarities = append(log.similarities, append([]float64(nil), candidates.similarities[:n]...))
+}
+
+// write writes the decision made for each added right line, in right line order.
+func (log *candidateLog) write(rightLineInfos []*LineInfo, threshold float64, pairs *pairing) {
+	// Pairs made for a right line can be replaced by the pair of a later right line
+	selected := make(map[int]bool)
+	for _, pair := range pairs.similar {
+		selected[pair.right.lineNumber] = true
+	}
+	for i, rightLineInfo := range rightLineInfos {
+		_, _ = fmt.Fprintf(log.w, "right %d %s: ", rightLineInfo.lineNumber+1, quote(log.redact(rightLineInfo.content)))
+		switch {
+		case len(log.top[i]) == 0:
+			_, _ = fmt.Fprintln(log.w, "added, no candidates")
+		case selected[rightLineInfo.lineNumber]:
+			_, _ = fmt.Fprintf(log.w, "paired with left %d\n", log.top[i][0].left.lineNumber+1)
+		case log.similarities[i][0] > threshold:
+			_, _ = fmt.Fprintf(log.w, "added, left %d was paired with a later right line\n", log.top[i][0].left.lineNumber+1)
+		default:
+			_, _ = fmt.Fprintf(log.w, "added, best similarity %.3f isn't above the threshold %.3f\n", log.similarities[i][0], threshold)
+		}
+		for j, pair := range log.top[i] {
+			_, _ = fmt.Fprintf(log.w, "  left %d %s: similarity %.3f, content %.3f, context %.3f\n", pair.left.lineNumber+1, quote(log.redact(pair.left.content)), log.similarities[i][j], pair.contentNormalizedLevenshteinSimilarity(), pair.contextTfIdfCosineSimilarity())
+		}
+	}
+}
+
+func quote(content string) string {
+	return fmt.Sprintf("%q", strings.TrimSuffix(content, "\n"))
+}
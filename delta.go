@@ -0,0 +1,75 @@
+package lhdiff
+
+// Delta is a Result that only records the mappings that differ from the identity, so that its
+// size is proportional to the size of the changes, and it stays small for the common case of
+// small changes to big files. A left line whose mapping isn't in Changes maps, with a
+// similarity of 1, to the right line after the one that the closest mapped left line above it
+// maps to (or to the first right line). The added lines are the right lines that no left line
+// maps to.
+type Delta struct {
+	// Changes are the mappings of the left lines that don't follow from the mappings above
+	// them, in left line order: the lines that were edited, deleted or moved, and the first
+	// lines after added lines.
+	Changes        []LineMapping `json:",omitempty"`
+	LeftLineCount  int
+	RightLineCount int
+	// Moved, Truncated, Partial and Anchors are those of Result
+	Moved     []LineMapping `json:",omitempty"`
+	Truncated bool          `json:",omitempty"`
+	Partial   bool          `json:",omitempty"`
+	Anchors   []Anchor      `json:",omitempty"`
+}
+
+// Delta returns the delta encoding of the result.
+func (result *Result) Delta() *Delta {
+	delta := &Delta{
+		LeftLineCount:  result.LeftLineCount,
+		RightLineCount: result.RightLineCount,
+		Moved:          result.Moved,
+		Truncated:      result.Truncated,
+		Partial:        result.Partial,
+		Anchors:        result.Anchors,
+	}
+	next := 0
+	for _, mapping := range result.Mappings[:result.LeftLineCount] {
+		if mapping != (LineMapping{Left: mapping.Left, Right: next, Similarity: 1}) {
+			delta.Changes = append(delta.Changes, mapping)
+		}
+		if mapping.Right != -1 {
+			next = mapping.Right + 1
+		}
+	}
+	return delta
+}
+
+// Expand returns the Result that the delta encodes.
+func (delta *Delta) Expand() *Result {
+	result := &Result{
+		Mappings:       make([]LineMapping, 0, delta.LeftLineCount+len(delta.Changes)),
+		LeftLineCount:  delta.LeftLineCount,
+		RightLineCount: delta.RightLineCount,
+		Moved:          delta.Moved,
+		Truncated:      delta.Truncated,
+		Partial:        delta.Partial,
+		Anchors:        delta.Anchors,
+	}
+	mapped := make([]bool, delta.RightLineCount)
+	changes, next := delta.Changes, 0
+	for leftLineNumber := 0; leftLineNumber < delta.LeftLineCount; leftLineNumber++ {
+		mapping := LineMapping{Left: leftLineNumber, Right: next, Similarity: 1}
+		if len(changes) > 0 && changes[0].Left == leftLineNumber {
+			mapping, changes = changes[0], changes[1:]
+		}
+		if mapping.Right >= 0 && mapping.Right < delta.RightLineCount {
+			mapped[mapping.Right] = true
+			next = mapping.Right + 1
+		}
+		result.Mappings = append(result.Mappings, mapping)
+	}
+	for rightLineNumber, isMapped := range mapped {
+		if !isMapped {
+			result.Mappings = append(result.Mappings, LineMapping{Left: -1, Right: rightLineNumber})
+		}
+	}
+	return result
+}
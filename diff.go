@@ -0,0 +1,357 @@
+package lhdiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DiffEngine finds the lines that two files have in common. Those lines are unchanged, and the
+// other lines are paired by similarity.
+type DiffEngine interface {
+	// Diff returns the runs of left lines that are equal to right lines, in increasing order of
+	// both their left and right lines.
+	Diff(leftLines []string, rightLines []string) []Run
+}
+
+// Myers is the default DiffEngine, which implements Myers' O(ND) difference algorithm.
+type Myers struct {
+	// Minimal makes Diff find the smallest difference even when that is expensive. Otherwise
+	// the search is cut short on files that have little in common, and the difference may be
+	// larger than necessary.
+	Minimal bool
+	// IgnoreBlankLines makes Diff only use non-blank lines as unchanged lines, so that blank
+	// lines are paired by similarity, and adding or removing blank lines doesn't split runs.
+	IgnoreBlankLines bool
+}
+
+// minTooExpensive is the lowest number of edits after which a non-minimal diff is cut short.
+const minTooExpensive = 256
+
+// Diff implements DiffEngine.
+func (myers Myers) Diff(leftLines []string, rightLines []string) []Run {
+	leftIndexes, rightIndexes := allLines(len(leftLines)), allLines(len(rightLines))
+	if myers.IgnoreBlankLines {
+		leftIndexes, rightIndexes = nonBlankLines(leftLines), nonBlankLines(rightLines)
+	}
+	ids := make(map[string]int)
+	intern := func(lines []string, indexes []int) []int {
+		interned := make([]int, len(indexes))
+		for i, index := range indexes {
+			id, ok := ids[lines[index]]
+			if !ok {
+				id = len(ids)
+				ids[lines[index]] = id
+			}
+			interned[i] = id
+		}
+		return interned
+	}
+	d := &myersDiff{a: intern(leftLines, leftIndexes), b: intern(rightLines, rightIndexes)}
+	size := len(d.a) + len(d.b) + 2
+	d.forward, d.backward = make([]int, 2*size+1), make([]int, 2*size+1)
+	d.tooExpensive = -1
+	if !myers.Minimal {
+		d.tooExpensive = minTooExpensive
+		for d.tooExpensive*d.tooExpensive < size {
+			d.tooExpensive *= 2
+		}
+	}
+	d.compare(0, len(d.a), 0, len(d.b))
+	var runs []Run
+	for _, match := range d.slide() {
+		for i := 0; i < match.Length; i++ {
+			left, right := leftIndexes[match.Left+i], rightIndexes[match.Right+i]
+			if n := len(runs); n > 0 && runs[n-1].Left+runs[n-1].Length == left && runs[n-1].Right+runs[n-1].Length == right {
+				runs[n-1].Length++
+			} else {
+				runs = append(runs, Run{Left: left, Right: right, Length: 1})
+			}
+		}
+	}
+	return runs
+}
+
+func allLines(count int) []int {
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+func nonBlankLines(lines []string) []int {
+	var indexes []int
+	for i, line := range lines {
+		if line != "\n" && line != "" {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// myersDiff is the state of a comparison of the sequences a and b. forward and backward hold
+// the furthest reaching paths of each diagonal, and are shared by the recursive calls.
+type myersDiff struct {
+	a, b              []int
+	forward, backward []int
+	// tooExpensive is the number of edits after which the search is cut short, or -1
+	tooExpensive int
+	matches      []Run
+}
+
+func (d *myersDiff) match(left int, right int, length int) {
+	if length > 0 {
+		d.matches = append(d.matches, Run{Left: left, Right: right, Length: length})
+	}
+}
+
+// compare finds the matches of a[aLo:aHi] and b[bLo:bHi], in order.
+func (d *myersDiff) compare(aLo int, aHi int, bLo int, bHi int) {
+	prefix := 0
+	for aLo+prefix < aHi && bLo+prefix < bHi && d.a[aLo+prefix] == d.b[bLo+prefix] {
+		prefix++
+	}
+	d.match(aLo, bLo, prefix)
+	aLo, bLo = aLo+prefix, bLo+prefix
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+	if aLo < aHi && bLo < bHi {
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		d.match(x, y, u-x)
+		d.compare(u, aHi, v, bHi)
+	}
+	d.match(aHi, bHi, suffix)
+}
+
+// slide moves the lines that were only deleted or only added down as far as the unchanged lines
+// after them allow, and returns the matches that are left. When a function is inserted after
+// another one that ends with the same line, the whole function is then added, rather than the
+// end of the previous function and the beginning of the inserted one.
+func (d *myersDiff) slide() []Run {
+	matches := append([]Run{{}}, d.matches...)
+	for i := 0; i+1 < len(matches); i++ {
+		previous, next := &matches[i], &matches[i+1]
+		for next.Length > 0 {
+			left, right := previous.Left+previous.Length, previous.Right+previous.Length
+			deleted := right == next.Right && d.a[left] == d.a[next.Left]
+			added := left == next.Left && d.b[right] == d.b[next.Right]
+			if !deleted && !added {
+				break
+			}
+			previous.Length++
+			next.Left, next.Right, next.Length = next.Left+1, next.Right+1, next.Length-1
+		}
+	}
+	kept := matches[:0]
+	for _, match := range matches {
+		if match.Length > 0 {
+			kept = append(kept, match)
+		}
+	}
+	return kept
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the middle snake of the shortest edit
+// script of a[aLo:aHi] and b[bLo:bHi], which must both be non-empty and differ at both ends. If
+// the search is cut short, the snake is empty and splits the sequences at the furthest point
+// reached from the start.
+func (d *myersDiff) middleSnake(aLo int, aHi int, bLo int, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	// The paths are indexed by diagonal k = x - y, offset to be non-negative
+	offset := len(d.forward) / 2
+	d.forward[offset+1], d.backward[offset+1] = 0, 0
+	for cost := 0; ; cost++ {
+		for k := -cost; k <= cost; k += 2 {
+			x := d.forward[offset+k+1]
+			if k != -cost && (k == cost || d.forward[offset+k-1] >= x) {
+				x = d.forward[offset+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x, y = x+1, y+1
+			}
+			d.forward[offset+k] = x
+			// The backward path on the same diagonal has diagonal delta - k in reverse
+			if odd && k >= delta-(cost-1) && k <= delta+(cost-1) && x+d.backward[offset+delta-k] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+		for k := -cost; k <= cost; k += 2 {
+			x := d.backward[offset+k+1]
+			if k != -cost && (k == cost || d.backward[offset+k-1] >= x) {
+				x = d.backward[offset+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x, y = x+1, y+1
+			}
+			d.backward[offset+k] = x
+			if !odd && k >= delta-cost && k <= delta+cost && x+d.forward[offset+delta-k] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+		if cost == d.tooExpensive {
+			bestX, bestY := 0, 0
+			for k := -cost; k <= cost; k += 2 {
+				x := d.forward[offset+k]
+				if y := x - k; x <= n && y <= m && x+y > bestX+bestY {
+					bestX, bestY = x, y
+				}
+			}
+			return aLo + bestX, bLo + bestY, aLo + bestX, bLo + bestY
+		}
+	}
+}
+
+// writeUnifiedDiff writes the difference between leftLines and rightLines, whose unchanged lines
+// are runs, as a unified diff with 3 lines of context.
+func writeUnifiedDiff(w io.Writer, leftLines []string, rightLines []string, runs []Run) error {
+	return writeDiff(w, leftLines, rightLines, runs, nil)
+}
+
+// diffColors are the ANSI escape sequences that the parts of a colored diff start with, or "" for
+// the parts that aren't colored.
+type diffColors struct {
+	meta    string
+	frag    string
+	deleted func(left int) string
+	added   func(right int) string
+}
+
+// writeDiff writes a unified diff like writeUnifiedDiff, colored with colors unless it is nil.
+func writeDiff(w io.Writer, leftLines []string, rightLines []string, runs []Run, colors *diffColors) error {
+	hunks := diffHunks(runs, len(leftLines), len(rightLines), 3)
+	if len(hunks) == 0 {
+		return nil
+	}
+	if colors == nil {
+		colors = &diffColors{
+			deleted: func(int) string { return "" },
+			added:   func(int) string { return "" },
+		}
+	}
+	if err := writeDiffLine(w, colors.meta, "--- ", "left\n"); err != nil {
+		return err
+	}
+	if err := writeDiffLine(w, colors.meta, "+++ ", "right\n"); err != nil {
+		return err
+	}
+	for _, h := range hunks {
+		header := fmt.Sprintf("-%s +%s @@\n", unifiedRange(h.leftStart, h.leftEnd), unifiedRange(h.rightStart, h.rightEnd))
+		if err := writeDiffLine(w, colors.frag, "@@ ", header); err != nil {
+			return err
+		}
+		left := h.leftStart
+		for _, c := range h.changes {
+			for ; left < c.left; left++ {
+				if err := writeDiffLine(w, "", " ", leftLines[left]); err != nil {
+					return err
+				}
+			}
+			for i, line := range leftLines[c.left:c.leftEnd] {
+				if err := writeDiffLine(w, colors.deleted(c.left+i), "-", line); err != nil {
+					return err
+				}
+			}
+			for i, line := range rightLines[c.right:c.rightEnd] {
+				if err := writeDiffLine(w, colors.added(c.right+i), "+", line); err != nil {
+					return err
+				}
+			}
+			left = c.leftEnd
+		}
+		for ; left < h.leftEnd; left++ {
+			if err := writeDiffLine(w, "", " ", leftLines[left]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeDiffLine writes a line of a diff, starting with prefix, and ends it with a newline if it
+// has none. A colored line is reset before its newline, like git does.
+func writeDiffLine(w io.Writer, color string, prefix string, line string) error {
+	line = strings.TrimSuffix(line, "\n")
+	if color != "" {
+		line = color + prefix + line + "\x1b[m"
+	} else {
+		line = prefix + line
+	}
+	_, err := io.WriteString(w, line+"\n")
+	return err
+}
+
+// change is the lines from left up to leftEnd that were replaced by the lines from right up to
+// rightEnd, between two runs of unchanged lines.
+type change struct{ left, leftEnd, right, rightEnd int }
+
+// hunk is a hunk of a unified diff: the lines from leftStart up to leftEnd and from rightStart up
+// to rightEnd, which are its changes and the unchanged lines around them.
+type hunk struct {
+	leftStart, leftEnd, rightStart, rightEnd int
+	changes                                  []change
+}
+
+// diffHunks groups the changes between the runs of unchanged lines into hunks, with context
+// unchanged lines around each change.
+func diffHunks(runs []Run, leftLineCount int, rightLineCount int, context int) []hunk {
+	var changes []change
+	left, right := 0, 0
+	for i := 0; i <= len(runs); i++ {
+		r := Run{Left: leftLineCount, Right: rightLineCount}
+		if i < len(runs) {
+			r = runs[i]
+		}
+		if r.Left > left || r.Right > right {
+			changes = append(changes, change{left, r.Left, right, r.Right})
+		}
+		left, right = r.Left+r.Length, r.Right+r.Length
+	}
+	var hunks []hunk
+	for i := 0; i < len(changes); {
+		// Changes that are at most 2 * context lines apart are in the same hunk
+		j := i + 1
+		for j < len(changes) && changes[j].left-changes[j-1].leftEnd <= 2*context {
+			j++
+		}
+		before := changes[i].left
+		if before > context {
+			before = context
+		}
+		after := leftLineCount - changes[j-1].leftEnd
+		if after > context {
+			after = context
+		}
+		hunks = append(hunks, hunk{
+			leftStart:  changes[i].left - before,
+			leftEnd:    changes[j-1].leftEnd + after,
+			rightStart: changes[i].right - before,
+			rightEnd:   changes[j-1].rightEnd + after,
+			changes:    changes[i:j],
+		})
+		i = j
+	}
+	return hunks
+}
+
+// unifiedRange formats the lines from start up to end as the range of a unified diff hunk.
+func unifiedRange(start int, end int) string {
+	switch end - start {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, end-start)
+	}
+}
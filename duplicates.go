@@ -0,0 +1,97 @@
+package lhdiff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DuplicateBlock is a run of consecutive lines that duplicates (or nearly duplicates) an earlier
+// run of lines in the same file. Line numbers are zero-based.
+type DuplicateBlock struct {
+	Original   int
+	Duplicate  int
+	Length     int
+	Similarity float64
+}
+
+// Duplicates runs the line similarity over text against itself and reports lines that are
+// duplicated or near-duplicated elsewhere in the file. Each line is compared with the lines
+// above it, so that every duplicate points to its most similar earlier occurrence. Insignificant
+// lines (see IsSignificant) are ignored, and consecutive duplicates are merged into blocks.
+func Duplicates(text string, contextSize int) []DuplicateBlock {
+	lines := ConvertToLinesWithoutNewLine(text)
+	lineInfos := make([]*LineInfo, len(lines))
+	for lineNumber := range lines {
+		lineInfos[lineNumber] = MakeLineInfo(lineNumber, lines, contextSize)
+	}
+
+	// originals[duplicate] is the most similar earlier line, if any
+	originals := make(map[int]LinePair)
+	for duplicate := range lines {
+		if !IsSignificant(lines[duplicate]) {
+			continue
+		}
+		var similarPairCandidates []LinePair
+		for original := 0; original < duplicate; original++ {
+			if !IsSignificant(lines[original]) {
+				continue
+			}
+			similarPairCandidates = append(similarPairCandidates, LinePair{
+				left:  lineInfos[original],
+				right: lineInfos[duplicate],
+			})
+		}
+		sort.Stable(ByCombinedSimilarity(similarPairCandidates))
+		if len(similarPairCandidates) > 0 {
+			mostSimilarPair := similarPairCandidates[0]
+			if mostSimilarPair.combinedSimilarity() > SimilarityThreshold {
+				originals[duplicate] = mostSimilarPair
+			}
+		}
+	}
+	return duplicateBlocks(originals, len(lines))
+}
+
+func duplicateBlocks(originals map[int]LinePair, lineCount int) []DuplicateBlock {
+	blocks := make([]DuplicateBlock, 0)
+	var block *DuplicateBlock
+	for duplicate := 0; duplicate < lineCount; duplicate++ {
+		pair, exists := originals[duplicate]
+		if !exists {
+			block = nil
+			continue
+		}
+		original := pair.left.lineNumber
+		similarity := pair.combinedSimilarity()
+		if block != nil && block.Original+block.Length == original && original < block.Duplicate {
+			block.Similarity = (block.Similarity*float64(block.Length) + similarity) / float64(block.Length+1)
+			block.Length++
+			continue
+		}
+		blocks = append(blocks, DuplicateBlock{
+			Original:   original,
+			Duplicate:  duplicate,
+			Length:     1,
+			Similarity: similarity,
+		})
+		block = &blocks[len(blocks)-1]
+	}
+	return blocks
+}
+
+func PrintDuplicates(blocks []DuplicateBlock) error {
+	for _, block := range blocks {
+		_, err := fmt.Printf("%s,%s,%.2f\n", rangeString(block.Original, block.Length), rangeString(block.Duplicate, block.Length), block.Similarity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rangeString(start int, length int) string {
+	if length == 1 {
+		return toString(start)
+	}
+	return fmt.Sprintf("%s-%s", toString(start), toString(start+length-1))
+}
@@ -0,0 +1,204 @@
+package lhdiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EditOp is the operation of an Edit.
+type EditOp string
+
+const (
+	// EditMove moves left lines, unchanged, to other right lines
+	EditMove EditOp = "move"
+	// EditEdit replaces the contents of left lines, which go to the given right lines
+	EditEdit EditOp = "edit"
+	// EditInsert inserts right lines that no left line maps to
+	EditInsert EditOp = "insert"
+	// EditDelete deletes left lines that map to no right line
+	EditDelete EditOp = "delete"
+)
+
+// Edit is an operation of an edit script, on Length consecutive lines, from the zero-based line
+// Left of the left file to the zero-based line Right of the right file. Left is -1 for inserted
+// lines and Right is -1 for deleted lines.
+type Edit struct {
+	Op     EditOp
+	Left   int
+	Right  int
+	Length int
+	// Lines are the contents of the right lines of edited and inserted lines, with their line
+	// endings
+	Lines []string `json:",omitempty"`
+}
+
+// EditScript returns the edit script turning left into right, as mapped by result: the lines
+// that were moved, edited, inserted and deleted, in consecutive runs. The lines that aren't in
+// the script are kept, in order, as the right lines that aren't in the script either, so the
+// script is as short as the change, and ApplyEditScript turns left into right with it.
+//
+// A line paired with a line whose order was inverted (see Result.Reordered) is moved, or edited
+// if its contents changed too. Lines that only differ in whitespace are edited, so that the
+// script reproduces right exactly. The moves, edits and deletions are in left line order,
+// followed by the insertions, in right line order.
+func EditScript(left string, right string, result *Result) ([]Edit, error) {
+	leftLines, rightLines := splitRawLines(left), splitRawLines(right)
+	if len(leftLines) != result.LeftLineCount || len(rightLines) != result.RightLineCount {
+		return nil, fmt.Errorf("the result is of %d left and %d right line(s), not %d and %d", result.LeftLineCount, result.RightLineCount, len(leftLines), len(rightLines))
+	}
+	pairs := make(map[int]int)
+	for _, mapping := range append(append([]LineMapping(nil), result.Mappings...), result.Moved...) {
+		if mapping.Left != -1 && mapping.Right != -1 {
+			pairs[mapping.Left] = mapping.Right
+		}
+	}
+	reordered := make(map[int]bool)
+	for _, mapping := range result.Reordered() {
+		reordered[mapping.Left] = true
+	}
+	var script []Edit
+	// add appends a line to the last edit when it continues it, and starts a new edit otherwise
+	add := func(op EditOp, leftLineNumber int, rightLineNumber int) {
+		var lines []string
+		if op == EditEdit || op == EditInsert {
+			lines = []string{rightLines[rightLineNumber]}
+		}
+		if last := len(script) - 1; last >= 0 && script[last].Op == op && continues(script[last].Left, leftLineNumber, script[last].Length) && continues(script[last].Right, rightLineNumber, script[last].Length) {
+			script[last].Length++
+			script[last].Lines = append(script[last].Lines, lines...)
+			return
+		}
+		script = append(script, Edit{Op: op, Left: leftLineNumber, Right: rightLineNumber, Length: 1, Lines: lines})
+	}
+	pairedRights := make(map[int]bool, len(pairs))
+	for leftLineNumber := range leftLines {
+		rightLineNumber, paired := pairs[leftLineNumber]
+		switch {
+		case !paired:
+			add(EditDelete, leftLineNumber, -1)
+		case leftLines[leftLineNumber] != rightLines[rightLineNumber]:
+			add(EditEdit, leftLineNumber, rightLineNumber)
+		case reordered[leftLineNumber]:
+			add(EditMove, leftLineNumber, rightLineNumber)
+		}
+		if paired {
+			pairedRights[rightLineNumber] = true
+		}
+	}
+	for rightLineNumber := range rightLines {
+		if !pairedRights[rightLineNumber] {
+			add(EditInsert, -1, rightLineNumber)
+		}
+	}
+	return script, nil
+}
+
+// continues returns true if line is the line after a run of length lines from start, or if
+// both are -1.
+func continues(start int, line int, length int) bool {
+	if start == -1 || line == -1 {
+		return start == line
+	}
+	return line == start+length
+}
+
+// ApplyEditScript returns the right file that an edit script of EditScript turns left into.
+func ApplyEditScript(left string, script []Edit) (string, error) {
+	leftLines := splitRawLines(left)
+	scripted := make(map[int]bool)
+	var rightLineCount int
+	for _, edit := range script {
+		if edit.Left != -1 {
+			if edit.Left < 0 || edit.Left+edit.Length > len(leftLines) {
+				return "", fmt.Errorf("%s of left line(s) %d-%d of %d", edit.Op, edit.Left+1, edit.Left+edit.Length, len(leftLines))
+			}
+			for line := edit.Left; line < edit.Left+edit.Length; line++ {
+				scripted[line] = true
+			}
+		}
+		if edit.Right != -1 {
+			rightLineCount += edit.Length
+		}
+	}
+	kept := make([]string, 0, len(leftLines)-len(scripted))
+	for line, text := range leftLines {
+		if !scripted[line] {
+			kept = append(kept, text)
+		}
+	}
+	rightLines := make([]*string, rightLineCount+len(kept))
+	for _, edit := range script {
+		if edit.Right == -1 {
+			continue
+		}
+		if edit.Right < 0 || edit.Right+edit.Length > len(rightLines) {
+			return "", fmt.Errorf("%s to right line(s) %d-%d of %d", edit.Op, edit.Right+1, edit.Right+edit.Length, len(rightLines))
+		}
+		if edit.Op != EditMove && len(edit.Lines) != edit.Length {
+			return "", fmt.Errorf("%s of %d line(s) with %d line(s) of contents", edit.Op, edit.Length, len(edit.Lines))
+		}
+		for i := 0; i < edit.Length; i++ {
+			var text string
+			if edit.Op == EditMove {
+				text = leftLines[edit.Left+i]
+			} else {
+				text = edit.Lines[i]
+			}
+			if rightLines[edit.Right+i] != nil {
+				return "", fmt.Errorf("right line %d is the target of several edits", edit.Right+i+1)
+			}
+			rightLines[edit.Right+i] = &text
+		}
+	}
+	var builder strings.Builder
+	for _, text := range rightLines {
+		if text == nil {
+			text, kept = &kept[0], kept[1:]
+		}
+		builder.WriteString(*text)
+	}
+	return builder.String(), nil
+}
+
+// WriteEditScript writes an edit script with one-based line numbers, one edit per line, such as
+// "move 3..5 -> 10". The contents of edited and inserted lines follow their edit, each
+// prefixed with "+".
+func WriteEditScript(w io.Writer, script []Edit) error {
+	for _, edit := range script {
+		var line string
+		switch edit.Op {
+		case EditDelete:
+			line = fmt.Sprintf("delete %s", editRange(edit.Left, edit.Length))
+		case EditInsert:
+			line = fmt.Sprintf("insert -> %s", editRange(edit.Right, edit.Length))
+		default:
+			line = fmt.Sprintf("%s %s -> %d", edit.Op, editRange(edit.Left, edit.Length), edit.Right+1)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		for _, text := range edit.Lines {
+			if _, err := fmt.Fprintf(w, "+%s\n", strings.TrimSuffix(text, "\n")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// editRange formats a run of lines starting at a zero-based line as a one-based range.
+func editRange(start int, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d..%d", start+1, start+length)
+}
+
+// splitRawLines splits text into the lines that Compare compares, without normalizing them.
+func splitRawLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.SplitAfter(text, "\n")
+}
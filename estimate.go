@@ -0,0 +1,52 @@
+package lhdiff
+
+import (
+	"unsafe"
+)
+
+// Cost is the expected cost of a comparison, see Estimate.
+type Cost struct {
+	// Comparisons is the number of pairs of lines whose similarity is computed
+	Comparisons int
+	// Memory is the approximate number of bytes allocated for the lines, their contexts, the
+	// candidates of each added line and the result
+	Memory int
+}
+
+// Estimate returns the cost of comparing left and right with opts, without comparing the lines
+// by similarity, so that callers can send the comparisons that are too expensive elsewhere. Only
+// the unchanged lines, pins and equal lines are paired, which is fast next to the rest of the
+// comparison. Estimate returns the errors that Compare would, such as a *LimitError.
+func Estimate(left string, right string, opts ...Option) (Cost, error) {
+	o := newOptions(opts)
+	o.diffOutput = nil
+	leftLines := ConvertToLinesWithoutNewLine(left)
+	rightLines := ConvertToLinesWithoutNewLine(right)
+	_, leftLineNumbers, rightLineNumbers, err := pairCertainLines(leftLines, rightLines, o)
+	if err != nil {
+		return Cost{}, err
+	}
+	cost := Cost{Comparisons: len(leftLineNumbers) * len(rightLineNumbers)}
+	if o.maxComparisons > 0 && cost.Comparisons > o.maxComparisons {
+		cost.Comparisons = o.maxComparisons
+	}
+	averageLength := 0
+	if lines := len(leftLines) + len(rightLines); lines > 0 {
+		averageLength = (len(left) + len(right)) / lines
+	}
+	const (
+		lineInfoSize  = int(unsafe.Sizeof(LineInfo{}))
+		candidateSize = int(unsafe.Sizeof(LinePair{})) + 8
+		mappingSize   = int(unsafe.Sizeof(LineMapping{}))
+	)
+	contextSize := 0
+	if o.contextSize > 0 {
+		contextSize = 2 * o.contextSize
+	}
+	lineInfos := len(leftLineNumbers) + len(rightLineNumbers)
+	cost.Memory = 2*(len(left)+len(right)) +
+		lineInfos*(lineInfoSize+(contextSize+1)*averageLength) +
+		len(leftLineNumbers)*candidateSize +
+		(len(leftLines)+len(rightLines))*mappingSize
+	return cost, nil
+}
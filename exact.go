@@ -0,0 +1,149 @@
+package lhdiff
+
+import (
+	"sort"
+)
+
+// pairEqualLines pairs the deleted and added lines that have the same significant content, and
+// returns the deleted and added lines that are left to be paired by similarity. When a content
+// was deleted as many times as it was added, its lines are paired in order. Otherwise, they are
+// paired by their positions once the others are (see nearestDuplicates), and the lines in excess
+// are left to the similarity pass. Insignificant lines such as blank lines and braces can only be
+// told apart by their contexts, so they are left to the similarity pass too.
+func (pairs *pairing) pairEqualLines(leftLines []string, rightLines []string, leftLineNumbers []int, rightLineNumbers []int, contextSize int) ([]int, []int) {
+	deleted := make(map[string][]int)
+	for _, left := range leftLineNumbers {
+		if IsSignificant(leftLines[left]) {
+			deleted[leftLines[left]] = append(deleted[leftLines[left]], left)
+		}
+	}
+	added := make(map[string][]int)
+	for _, right := range rightLineNumbers {
+		if IsSignificant(rightLines[right]) {
+			added[rightLines[right]] = append(added[rightLines[right]], right)
+		}
+	}
+	pairedLefts := make(map[int]bool)
+	pairedRights := make(map[int]bool)
+	pair := func(lefts []int, rights []int) {
+		for i, left := range lefts {
+			pairedLefts[left], pairedRights[rights[i]] = true, true
+			pairs.similar[left] = LinePair{
+				left:  MakeLineInfo(left, leftLines, contextSize),
+				right: MakeLineInfo(rights[i], rightLines, contextSize),
+			}
+			pairs.similarities[left] = 1
+		}
+	}
+	var duplicates []string
+	for content, lefts := range deleted {
+		switch rights := added[content]; {
+		case len(rights) == len(lefts):
+			pair(lefts, rights)
+		case len(rights) > 0:
+			duplicates = append(duplicates, content)
+		}
+	}
+	if len(duplicates) > 0 {
+		certain := pairs.certainRuns()
+		for _, content := range duplicates {
+			pair(nearestDuplicates(certain, deleted[content], added[content]))
+		}
+	}
+	if len(pairedLefts) == 0 {
+		return leftLineNumbers, rightLineNumbers
+	}
+	return unpaired(leftLineNumbers, pairedLefts), unpaired(rightLineNumbers, pairedRights)
+}
+
+// certainRuns returns the runs of unchanged lines along with the lines paired so far, as runs of
+// one line, in left line order.
+func (pairs *pairing) certainRuns() []Run {
+	runs := append([]Run(nil), pairs.identical...)
+	for left, pair := range pairs.similar {
+		runs = append(runs, Run{Left: left, Right: pair.right.lineNumber, Length: 1})
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Left < runs[j].Left
+	})
+	return runs
+}
+
+// nearestDuplicates pairs lines with the same content that were deleted and added a different
+// number of times, returning the paired left and right lines. The lines are paired in order, and
+// the lines in excess are those that would take the pairs furthest from where the left lines
+// would be if they were at the same offset from the last of the certain runs above them as in
+// the left file, such as the first line of a moved function.
+func nearestDuplicates(certain []Run, lefts []int, rights []int) ([]int, []int) {
+	if len(lefts)*len(rights) > maxDuplicatePairs {
+		return nil, nil
+	}
+	return pairInOrder(lefts, rights, func(left int, right int) int {
+		i := sort.Search(len(certain), func(i int) bool {
+			return certain[i].Left > left
+		})
+		expected := left
+		if i > 0 {
+			expected += certain[i-1].Right - certain[i-1].Left
+		}
+		if right < expected {
+			return expected - right
+		}
+		return right - expected
+	})
+}
+
+// maxDuplicatePairs bounds the pairs of duplicates that nearestDuplicates weighs, beyond which
+// they are left to the similarity pass.
+var /* const */ maxDuplicatePairs = 1 << 20
+
+// pairInOrder pairs as many lefts with as many rights as there are lines on the shorter side,
+// keeping their order, such that the sum of the distances of the pairs is the lowest.
+func pairInOrder(lefts []int, rights []int, distance func(left int, right int) int) ([]int, []int) {
+	short, long := lefts, rights
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	cost := func(i int, j int) int {
+		if len(lefts) > len(rights) {
+			return distance(long[j], short[i])
+		}
+		return distance(short[i], long[j])
+	}
+	// costs[i][j] is the lowest sum of the distances of the pairs of the first i lines of the
+	// shorter side with i of the first j lines of the longer side
+	costs := make([][]int, len(short)+1)
+	for i := range costs {
+		costs[i] = make([]int, len(long)+1)
+	}
+	for i := 1; i <= len(short); i++ {
+		for j := i; j <= len(long); j++ {
+			costs[i][j] = costs[i-1][j-1] + cost(i-1, j-1)
+			if j > i && costs[i][j-1] < costs[i][j] {
+				costs[i][j] = costs[i][j-1]
+			}
+		}
+	}
+	pairedShort, pairedLong := make([]int, len(short)), make([]int, len(short))
+	for i, j := len(short), len(long); i > 0; j-- {
+		if j > i && costs[i][j-1] == costs[i][j] {
+			continue
+		}
+		i--
+		pairedShort[i], pairedLong[i] = short[i], long[j-1]
+	}
+	if len(lefts) > len(rights) {
+		return pairedLong, pairedShort
+	}
+	return pairedShort, pairedLong
+}
+
+func unpaired(lineNumbers []int, paired map[int]bool) []int {
+	var kept []int
+	for _, lineNumber := range lineNumbers {
+		if !paired[lineNumber] {
+			kept = append(kept, lineNumber)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,125 @@
+package lhdiff
+
+import (
+	"fmt"
+)
+
+// ContentSimilarityGate is the content similarity that two lines must exceed to be paired,
+// unless another gate is set with WithContentGate. Pairs below it have a similarity of 0,
+// whatever their context.
+const ContentSimilarityGate = 0.5
+
+// Explanation explains why a left line and a right line were paired or not. Contents and
+// contexts are normalized, as they were compared, and redacted with WithRedaction.
+type Explanation struct {
+	LeftContent  string
+	RightContent string
+	LeftContext  string
+	RightContext string
+	// ContentSimilarity and ContextSimilarity are weighted by ContentFactor and ContextFactor
+	ContentSimilarity float64
+	ContextSimilarity float64
+	ContentFactor     float64
+	ContextFactor     float64
+	// Similarity is 0 if ContentSimilarity isn't above ContentGate
+	Similarity  float64
+	ContentGate float64
+	Threshold   float64
+	// Compared is true if the left line was deleted and the right line added by the diff, so
+	// that they were compared by similarity. Unchanged lines are only paired with each other,
+	// and pinned lines (see WithPins) with the lines they are pinned to.
+	Compared bool
+	// Paired is true if the lines were paired
+	Paired bool
+	// Reason says why the lines were paired or not
+	Reason string
+}
+
+// Explain explains why the zero-based leftLine and rightLine were paired or not when comparing
+// left and right with opts.
+func Explain(left string, right string, leftLine int, rightLine int, opts ...Option) (*Explanation, error) {
+	o := newOptions(opts)
+	leftLines := ConvertToLinesWithoutNewLine(left)
+	rightLines := ConvertToLinesWithoutNewLine(right)
+	if leftLine < 0 || leftLine >= len(leftLines) {
+		return nil, fmt.Errorf("left line %d is out of range: the left file has %d lines", leftLine+1, len(leftLines))
+	}
+	if rightLine < 0 || rightLine >= len(rightLines) {
+		return nil, fmt.Errorf("right line %d is out of range: the right file has %d lines", rightLine+1, len(rightLines))
+	}
+	pairs, err := computePairs(leftLines, rightLines, o)
+	if err != nil {
+		return nil, err
+	}
+	result := newSparseResult(pairs, len(leftLines), len(rightLines))
+
+	pair := LinePair{
+		left:  MakeLineInfo(leftLine, leftLines, o.contextSize),
+		right: MakeLineInfo(rightLine, rightLines, o.contextSize),
+	}
+	o.vectorize(pair.left, pair.right)
+	redact := o.redaction()
+	explanation := &Explanation{
+		LeftContent:       redact(pair.left.content),
+		RightContent:      redact(pair.right.content),
+		LeftContext:       redact(pair.left.context),
+		RightContext:      redact(pair.right.context),
+		ContentSimilarity: pair.contentNormalizedLevenshteinSimilarity(),
+		ContextSimilarity: pair.contextTfIdfCosineSimilarity(),
+		Similarity:        o.similarity(pair),
+		ContentGate:       o.contentGate,
+		Threshold:         pairs.threshold,
+	}
+	explanation.ContentFactor, explanation.ContextFactor = o.factors(pair)
+
+	leftMapsTo, _, leftMapped := result.RightLine(leftLine)
+	rightMapsTo, similarity, rightMapped := result.LeftLine(rightLine)
+	leftUnchanged := inRun(pairs.identical, leftLine, func(run Run) int { return run.Left })
+	rightUnchanged := inRun(pairs.identical, rightLine, func(run Run) int { return run.Right })
+	leftPinned, rightPinned := false, false
+	for _, pin := range o.pins {
+		leftPinned = leftPinned || pin.Left == leftLine
+		rightPinned = rightPinned || pin.Right == rightLine
+	}
+	explanation.Compared = !leftUnchanged && !rightUnchanged && !leftPinned && !rightPinned
+	explanation.Paired = leftMapped && leftMapsTo == rightLine
+	switch {
+	case explanation.Paired && (leftPinned || rightPinned):
+		explanation.Reason = "the lines are pinned"
+	case leftPinned:
+		explanation.Reason = fmt.Sprintf("the left line is pinned to right line %d", leftMapsTo+1)
+	case rightPinned:
+		explanation.Reason = fmt.Sprintf("the right line is pinned to left line %d", rightMapsTo+1)
+	case explanation.Paired && !explanation.Compared:
+		explanation.Reason = "the lines are unchanged"
+	case explanation.Paired && similarity == 1 && pair.left.content == pair.right.content:
+		explanation.Reason = "the lines have the same content, and were paired before comparing the other lines by similarity"
+	case explanation.Paired:
+		explanation.Reason = fmt.Sprintf("the similarity %.3f is above the threshold %.3f, and no other left line is more similar to the right line", explanation.Similarity, explanation.Threshold)
+	case leftUnchanged:
+		explanation.Reason = fmt.Sprintf("the left line is unchanged, paired with right line %d", leftMapsTo+1)
+	case rightUnchanged:
+		explanation.Reason = fmt.Sprintf("the right line is unchanged, paired with left line %d", rightMapsTo+1)
+	case explanation.ContentSimilarity <= explanation.ContentGate:
+		explanation.Reason = fmt.Sprintf("the content similarity %.3f isn't above %g", explanation.ContentSimilarity, explanation.ContentGate)
+	case explanation.Similarity <= explanation.Threshold:
+		explanation.Reason = fmt.Sprintf("the similarity %.3f isn't above the threshold %.3f", explanation.Similarity, explanation.Threshold)
+	case rightMapped:
+		explanation.Reason = fmt.Sprintf("left line %d is more similar to the right line (%.3f)", rightMapsTo+1, similarity)
+	case leftMapped:
+		explanation.Reason = fmt.Sprintf("the left line was paired with right line %d", leftMapsTo+1)
+	default:
+		explanation.Reason = "another left line is more similar to the right line, but was paired with a later right line"
+	}
+	return explanation, nil
+}
+
+// inRun returns true if line is in one of the runs, where start returns the first line of a run.
+func inRun(runs []Run, line int, start func(Run) int) bool {
+	for _, run := range runs {
+		if line >= start(run) && line < start(run)+run.Length {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+// Package finding remaps the locations of static-analysis findings (e.g. a SAST baseline)
+// from the version of the source they were reported on to a newer version.
+//
+// Each report format is supported by a FindingAdapter, which only needs to know how to read
+// the locations of the findings in a report and how to write them back. Adapters are
+// registered by name with Register.
+package finding
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Location is the location of a finding. Lines and columns are one-based. EndLine and
+// Column are 0 if the report doesn't have them.
+type Location struct {
+	Path    string
+	Line    int
+	EndLine int
+	Column  int
+}
+
+// Remapped is a Location after remapping. Deleted is true if the line of the finding no
+// longer exists, and Modified is true if it exists but was changed.
+type Remapped struct {
+	Location
+	Deleted  bool
+	Modified bool
+}
+
+// FindingAdapter reads and writes the locations of findings in one report format.
+type FindingAdapter interface {
+	// Locations returns the locations of the findings in report.
+	Locations(report []byte) ([]Location, error)
+	// Write returns report with the location at each index replaced by remapped[index].
+	// Findings at deleted locations are removed.
+	Write(report []byte, remapped []Remapped) ([]byte, error)
+}
+
+var (
+	mu       sync.RWMutex
+	adapters = make(map[string]FindingAdapter)
+)
+
+// Register makes an adapter available by name. It panics if the name is already registered.
+func Register(name string, adapter FindingAdapter) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := adapters[name]; exists {
+		panic("finding: adapter registered twice: " + name)
+	}
+	adapters[name] = adapter
+}
+
+// Lookup returns the adapter registered under name.
+func Lookup(name string) (FindingAdapter, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	adapter, ok := adapters[name]
+	return adapter, ok
+}
+
+// Names returns the names of the registered adapters, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats counts what happened to the findings of a report.
+type Stats struct {
+	Unchanged int
+	Modified  int
+	Deleted   int
+}
+
+func (stats Stats) String() string {
+	return fmt.Sprintf("%d unchanged, %d modified, %d deleted", stats.Unchanged, stats.Modified, stats.Deleted)
+}
+
+// Remap remaps the findings in report with remapper, using adapter to read and write it.
+// Findings in files that no longer exist are deleted.
+func Remap(adapter FindingAdapter, report []byte, remapper *lhdiff.Remapper) ([]byte, Stats, error) {
+	var stats Stats
+	locations, err := adapter.Locations(report)
+	if err != nil {
+		return nil, stats, err
+	}
+	remapped := make([]Remapped, len(locations))
+	for i, location := range locations {
+		remapped[i], err = remapLocation(location, remapper)
+		if err != nil {
+			return nil, stats, err
+		}
+		switch {
+		case remapped[i].Deleted:
+			stats.Deleted++
+		case remapped[i].Modified:
+			stats.Modified++
+		default:
+			stats.Unchanged++
+		}
+	}
+	out, err := adapter.Write(report, remapped)
+	return out, stats, err
+}
+
+// checkRemapped returns an error unless remapped has one element per location of report, as
+// Write expects.
+func checkRemapped(adapter FindingAdapter, report []byte, remapped []Remapped) error {
+	locations, err := adapter.Locations(report)
+	if err != nil {
+		return err
+	}
+	if len(locations) != len(remapped) {
+		return fmt.Errorf("the report has %d finding(s), but %d remapped location(s) were given", len(locations), len(remapped))
+	}
+	return nil
+}
+
+func remapLocation(location Location, remapper *lhdiff.Remapper) (Remapped, error) {
+	line, similarity, ok, err := remapper.Remap(location.Path, location.Line-1)
+	if errors.Is(err, os.ErrNotExist) {
+		return Remapped{Location: location, Deleted: true}, nil
+	}
+	if err != nil || !ok {
+		return Remapped{Location: location, Deleted: true}, err
+	}
+	remapped := Remapped{Location: location, Modified: similarity != 1}
+	remapped.Line = line + 1
+	if location.EndLine != 0 {
+		endLine, _, ok, err := remapper.Remap(location.Path, location.EndLine-1)
+		if err != nil {
+			return remapped, err
+		}
+		if !ok || endLine+1 < remapped.Line {
+			endLine = remapped.Line - 1 + location.EndLine - location.Line
+			remapped.Modified = true
+		}
+		remapped.EndLine = endLine + 1
+	}
+	return remapped, nil
+}
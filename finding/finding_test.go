@@ -0,0 +1,121 @@
+package finding
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+)
+
+var old = lhdiff.MapSnapshot{
+	"main.go": `package main
+
+import "os"
+
+func main() {
+	f, _ := os.Open("x")
+	defer f.Close()
+	panic("boom")
+}
+`,
+}
+
+var current = lhdiff.MapSnapshot{
+	"main.go": `package main
+
+import (
+	"os"
+)
+
+func main() {
+	f, _ := os.Open("x.txt")
+	defer f.Close()
+}
+`,
+}
+
+func ExampleRemap() {
+	report := `main.go:6:10: error return value not checked
+main.go:8:2: panic in main
+`
+	adapter, _ := Lookup("text")
+	remapped, stats, err := Remap(adapter, []byte(report), lhdiff.NewRemapper(old, current))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(stats)
+	fmt.Print(string(remapped))
+
+	// Output:
+	// 0 unchanged, 1 modified, 1 deleted
+	// main.go:8:10: error return value not checked
+}
+
+func ExampleRemap_sarif() {
+	report := `{
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {"driver": {"name": "gosec"}},
+      "results": [
+        {"ruleId": "G104", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 6, "startColumn": 10}}}]},
+        {"ruleId": "X1", "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 8}}}]}
+      ]
+    }
+  ]
+}`
+	adapter, _ := Lookup("sarif")
+	remapped, stats, err := Remap(adapter, []byte(report), lhdiff.NewRemapper(old, current))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(stats)
+	fmt.Print(string(remapped))
+
+	// Output:
+	// 0 unchanged, 1 modified, 1 deleted
+	// {
+	//   "runs": [
+	//     {
+	//       "results": [
+	//         {
+	//           "locations": [
+	//             {
+	//               "physicalLocation": {
+	//                 "artifactLocation": {
+	//                   "uri": "main.go"
+	//                 },
+	//                 "region": {
+	//                   "startColumn": 10,
+	//                   "startLine": 8
+	//                 }
+	//               }
+	//             }
+	//           ],
+	//           "ruleId": "G104"
+	//         }
+	//       ],
+	//       "tool": {
+	//         "driver": {
+	//           "name": "gosec"
+	//         }
+	//       }
+	//     }
+	//   ],
+	//   "version": "2.1.0"
+	// }
+}
+
+func ExampleFindingAdapter_Write() {
+	adapter, _ := Lookup("text")
+	_, err := adapter.Write([]byte("main.go:6:10: error return value not checked\n"), nil)
+	fmt.Println(err)
+
+	// Output:
+	// the report has 1 finding(s), but 0 remapped location(s) were given
+}
+
+func ExampleNames() {
+	fmt.Println(Names())
+
+	// Output:
+	// [golangci-lint sarif text]
+}
@@ -0,0 +1,58 @@
+package finding
+
+import (
+	"encoding/json"
+)
+
+func init() {
+	Register("golangci-lint", golangciAdapter{})
+}
+
+// golangciAdapter reads and writes the JSON output of golangci-lint (--out-format json).
+type golangciAdapter struct{}
+
+func (golangciAdapter) Locations(report []byte) ([]Location, error) {
+	var doc interface{}
+	if err := json.Unmarshal(report, &doc); err != nil {
+		return nil, err
+	}
+	var locations []Location
+	for _, issue := range array(doc, "Issues") {
+		pos := object(issue, "Pos")
+		filename, _ := pos["Filename"].(string)
+		location := Location{Path: filename, Line: number(pos, "Line"), Column: number(pos, "Column")}
+		if lineRange := object(issue, "LineRange"); lineRange != nil {
+			location.EndLine = number(lineRange, "To")
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+func (adapter golangciAdapter) Write(report []byte, remapped []Remapped) ([]byte, error) {
+	if err := checkRemapped(adapter, report, remapped); err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(report, &doc); err != nil {
+		return nil, err
+	}
+	issues := array(doc, "Issues")
+	kept := make([]interface{}, 0, len(issues))
+	for i, issue := range issues {
+		r := remapped[i]
+		if r.Deleted {
+			continue
+		}
+		object(issue, "Pos")["Line"] = r.Line
+		if lineRange := object(issue, "LineRange"); lineRange != nil {
+			lineRange["From"] = r.Line
+			lineRange["To"] = r.EndLine
+		}
+		kept = append(kept, issue)
+	}
+	if issues != nil {
+		doc.(map[string]interface{})["Issues"] = kept
+	}
+	return marshal(doc)
+}
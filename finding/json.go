@@ -0,0 +1,33 @@
+package finding
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// marshal encodes doc like the tools that write these reports do, without escaping HTML.
+func marshal(doc interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(doc)
+	return buf.Bytes(), err
+}
+
+func object(v interface{}, key string) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	o, _ := m[key].(map[string]interface{})
+	return o
+}
+
+func array(v interface{}, key string) []interface{} {
+	m, _ := v.(map[string]interface{})
+	a, _ := m[key].([]interface{})
+	return a
+}
+
+func number(m map[string]interface{}, key string) int {
+	n, _ := m[key].(float64)
+	return int(n)
+}
@@ -0,0 +1,94 @@
+package finding
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+func init() {
+	Register("sarif", sarifAdapter{})
+}
+
+// sarifAdapter reads and writes SARIF 2.1.0 logs. The physical locations of each result are
+// remapped. A result is removed if any of them is deleted.
+type sarifAdapter struct{}
+
+func (sarifAdapter) Locations(report []byte) ([]Location, error) {
+	var locations []Location
+	err := sarifVisit(report, func(physicalLocation map[string]interface{}) {
+		locations = append(locations, sarifLocation(physicalLocation))
+	})
+	return locations, err
+}
+
+func (adapter sarifAdapter) Write(report []byte, remapped []Remapped) ([]byte, error) {
+	if err := checkRemapped(adapter, report, remapped); err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(report, &doc); err != nil {
+		return nil, err
+	}
+	i := 0
+	for _, run := range array(doc, "runs") {
+		results := array(run, "results")
+		kept := make([]interface{}, 0, len(results))
+		for _, result := range results {
+			deleted := false
+			for _, location := range array(result, "locations") {
+				physicalLocation := object(location, "physicalLocation")
+				region := object(physicalLocation, "region")
+				if region == nil {
+					continue
+				}
+				r := remapped[i]
+				i++
+				if r.Deleted {
+					deleted = true
+					continue
+				}
+				region["startLine"] = r.Line
+				if r.EndLine != 0 {
+					region["endLine"] = r.EndLine
+				}
+			}
+			if !deleted {
+				kept = append(kept, result)
+			}
+		}
+		if results != nil {
+			run.(map[string]interface{})["results"] = kept
+		}
+	}
+	return marshal(doc)
+}
+
+// sarifVisit calls visit for each physical location with a region of each result.
+func sarifVisit(report []byte, visit func(map[string]interface{})) error {
+	var doc interface{}
+	if err := json.Unmarshal(report, &doc); err != nil {
+		return err
+	}
+	for _, run := range array(doc, "runs") {
+		for _, result := range array(run, "results") {
+			for _, location := range array(result, "locations") {
+				physicalLocation := object(location, "physicalLocation")
+				if physicalLocation != nil && object(physicalLocation, "region") != nil {
+					visit(physicalLocation)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func sarifLocation(physicalLocation map[string]interface{}) Location {
+	uri, _ := object(physicalLocation, "artifactLocation")["uri"].(string)
+	region := object(physicalLocation, "region")
+	return Location{
+		Path:    strings.TrimPrefix(uri, "file://"),
+		Line:    number(region, "startLine"),
+		EndLine: number(region, "endLine"),
+		Column:  number(region, "startColumn"),
+	}
+}
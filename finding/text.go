@@ -0,0 +1,57 @@
+package finding
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+func init() {
+	Register("text", textAdapter{})
+}
+
+var /* const */ textFinding = regexp.MustCompile(`^([^:\s][^:]*):(\d+):(?:(\d+):)?(.*)$`)
+
+// textAdapter reads and writes the path:line[:column]: message lines printed by compilers and
+// linters such as go vet. Other lines are kept as-is.
+type textAdapter struct{}
+
+func (textAdapter) Locations(report []byte) ([]Location, error) {
+	var locations []Location
+	for _, line := range bytes.SplitAfter(report, []byte("\n")) {
+		if match := textFinding.FindSubmatch(bytes.TrimRight(line, "\r\n")); match != nil {
+			lineNumber, _ := strconv.Atoi(string(match[2]))
+			column, _ := strconv.Atoi(string(match[3]))
+			locations = append(locations, Location{Path: string(match[1]), Line: lineNumber, Column: column})
+		}
+	}
+	return locations, nil
+}
+
+func (adapter textAdapter) Write(report []byte, remapped []Remapped) ([]byte, error) {
+	if err := checkRemapped(adapter, report, remapped); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	i := 0
+	for _, line := range bytes.SplitAfter(report, []byte("\n")) {
+		match := textFinding.FindSubmatch(bytes.TrimRight(line, "\r\n"))
+		if match == nil {
+			out.Write(line)
+			continue
+		}
+		r := remapped[i]
+		i++
+		if r.Deleted {
+			continue
+		}
+		if len(match[3]) > 0 {
+			_, _ = fmt.Fprintf(&out, "%s:%d:%s:%s", match[1], r.Line, match[3], match[4])
+		} else {
+			_, _ = fmt.Fprintf(&out, "%s:%d:%s", match[1], r.Line, match[4])
+		}
+		out.Write(line[len(bytes.TrimRight(line, "\r\n")):])
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+package lhdiff
+
+// Frame is a stack frame from a crash report. Line is one-based, and Function is carried
+// over unchanged.
+type Frame struct {
+	Path     string
+	Line     int
+	Function string
+}
+
+// FrameRemapping is the result of remapping a frame to a new build.
+type FrameRemapping struct {
+	Old Frame
+	// New is the equivalent frame in the new build. It is the zero Frame if Deleted is true.
+	New        Frame
+	Deleted    bool
+	Similarity float64
+}
+
+// RemapFrames returns the frames in the new snapshot that are equivalent to frames of crash
+// reports from the old snapshot, so that crashes can still be grouped after a refactor.
+// Frames whose lines (or files) were deleted are flagged as Deleted.
+func RemapFrames(old Snapshot, new Snapshot, frames []Frame, opts ...Option) ([]FrameRemapping, error) {
+	remapper := NewRemapper(old, new, opts...)
+	remappings := make([]FrameRemapping, len(frames))
+	for i, frame := range frames {
+		line, similarity, ok, err := remapper.Remap(frame.Path, frame.Line-1)
+		if err != nil {
+			return nil, err
+		}
+		remappings[i] = FrameRemapping{Old: frame, Deleted: !ok}
+		if ok {
+			remappings[i].New = Frame{Path: frame.Path, Line: line + 1, Function: frame.Function}
+			remappings[i].Similarity = similarity
+		}
+	}
+	return remappings, nil
+}
@@ -0,0 +1,209 @@
+// Package gettext tracks translation entries across versions of a gettext PO catalog, so that
+// translators can see which old entries fuzzy entries correspond to.
+package gettext
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Entry is an entry of a PO file. Line is the zero-based line number of the entry's msgid.
+type Entry struct {
+	Line     int
+	Context  string
+	ID       string
+	IDPlural string
+	Strings  []string
+	Flags    []string
+	Obsolete bool
+}
+
+// Fuzzy returns true if the entry has the fuzzy flag.
+func (entry *Entry) Fuzzy() bool {
+	for _, flag := range entry.Flags {
+		if flag == "fuzzy" {
+			return true
+		}
+	}
+	return false
+}
+
+// key is the entry's context and msgid on a single line, used to compare entries.
+func (entry *Entry) key() string {
+	key := entry.ID
+	if entry.Context != "" {
+		key = entry.Context + " " + key
+	}
+	return strings.ReplaceAll(key, "\n", " ")
+}
+
+// Parse parses the entries of a PO file.
+func Parse(data []byte) ([]*Entry, error) {
+	var entries []*Entry
+	var entry *Entry
+	// target is the string that continuation lines are appended to
+	var target *string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		obsolete := strings.HasPrefix(line, "#~")
+		if obsolete {
+			line = strings.TrimSpace(line[2:])
+		}
+		if line == "" {
+			entry, target = nil, nil
+			continue
+		}
+		if entry == nil {
+			entry = &Entry{Line: -1}
+			entries = append(entries, entry)
+		}
+		entry.Obsolete = entry.Obsolete || obsolete
+		switch {
+		case strings.HasPrefix(line, "#,"):
+			for _, flag := range strings.Split(line[2:], ",") {
+				entry.Flags = append(entry.Flags, strings.TrimSpace(flag))
+			}
+		case strings.HasPrefix(line, "#"):
+			// Other comments
+		case strings.HasPrefix(line, `"`):
+			if target == nil {
+				return nil, fmt.Errorf("line %d: unexpected string", lineNumber+1)
+			}
+			s, err := unquote(line, lineNumber)
+			if err != nil {
+				return nil, err
+			}
+			*target += s
+		default:
+			keyword, value := line, ""
+			if i := strings.IndexByte(line, ' '); i != -1 {
+				keyword, value = line[:i], strings.TrimSpace(line[i+1:])
+			}
+			s, err := unquote(value, lineNumber)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case keyword == "msgctxt":
+				entry.Context = s
+				target = &entry.Context
+			case keyword == "msgid":
+				entry.ID = s
+				entry.Line = lineNumber
+				target = &entry.ID
+			case keyword == "msgid_plural":
+				entry.IDPlural = s
+				target = &entry.IDPlural
+			case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+				entry.Strings = append(entry.Strings, s)
+				target = &entry.Strings[len(entry.Strings)-1]
+			default:
+				return nil, fmt.Errorf("line %d: unexpected keyword: %s", lineNumber+1, keyword)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// Blocks of comments without a msgid aren't entries
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.Line != -1 {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
+
+func unquote(s string, lineNumber int) (string, error) {
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("line %d: invalid string: %s", lineNumber+1, s)
+	}
+	return unquoted, nil
+}
+
+// EntryMapping maps an entry of the left catalog to an entry of the right catalog. The
+// indexes are zero-based indexes into the entries, and -1 means the entry has no counterpart.
+type EntryMapping struct {
+	Left       int
+	Right      int
+	Similarity float64
+}
+
+// Compare tracks entries between two catalogs. Each entry is treated as a line made of its
+// context and msgid, so entries are matched by the similarity of their msgids and of the
+// msgids around them.
+func Compare(left []*Entry, right []*Entry, opts ...lhdiff.Option) ([]EntryMapping, error) {
+	result, err := lhdiff.Compare(keys(left), keys(right), opts...)
+	if err != nil {
+		return nil, err
+	}
+	mappings := make([]EntryMapping, 0, len(result.Mappings))
+	for _, mapping := range result.Mappings {
+		mappings = append(mappings, EntryMapping{Left: mapping.Left, Right: mapping.Right, Similarity: mapping.Similarity})
+	}
+	return mappings, nil
+}
+
+func keys(entries []*Entry) string {
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.key()
+	}
+	return strings.Join(keys, "\n")
+}
+
+// PrintMappings prints entry mappings as left,right one-based line numbers of the msgids,
+// or _ for entries that have no counterpart.
+func PrintMappings(w io.Writer, left []*Entry, right []*Entry, mappings []EntryMapping) error {
+	for _, mapping := range mappings {
+		_, err := fmt.Fprintf(w, "%s,%s\n", entryLine(left, mapping.Left), entryLine(right, mapping.Right))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintFuzzy prints the fuzzy entries of the right catalog along with the left entry they
+// correspond to and its translation.
+func PrintFuzzy(w io.Writer, left []*Entry, right []*Entry, mappings []EntryMapping) error {
+	for _, mapping := range mappings {
+		if mapping.Right == -1 || !right[mapping.Right].Fuzzy() {
+			continue
+		}
+		entry := right[mapping.Right]
+		_, err := fmt.Fprintf(w, "%s %q\n", entryLine(right, mapping.Right), entry.ID)
+		if err != nil {
+			return err
+		}
+		if mapping.Left == -1 {
+			_, err = fmt.Fprintln(w, "  no previous entry")
+		} else {
+			old := left[mapping.Left]
+			_, err = fmt.Fprintf(w, "  was %s %q (%.2f)\n", entryLine(left, mapping.Left), old.ID, mapping.Similarity)
+			for i := 0; err == nil && i < len(old.Strings); i++ {
+				_, err = fmt.Fprintf(w, "  msgstr %q\n", old.Strings[i])
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func entryLine(entries []*Entry, index int) string {
+	if index == -1 {
+		return "_"
+	}
+	return strconv.Itoa(entries[index].Line + 1)
+}
@@ -0,0 +1,74 @@
+package gettext
+
+import (
+	"os"
+)
+
+func ExamplePrintFuzzy() {
+	left, err := Parse([]byte(`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: main.c:10
+msgid "Open file"
+msgstr "Ouvrir le fichier"
+
+#: main.c:20
+msgid "Save the current file"
+msgstr "Enregistrer le fichier courant"
+
+#: main.c:30
+msgid "Quit"
+msgstr "Quitter"
+`))
+	if err != nil {
+		panic(err)
+	}
+	right, err := Parse([]byte(`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: main.c:10
+msgid "Open file"
+msgstr "Ouvrir le fichier"
+
+#: main.c:15
+msgid "Close all windows"
+msgstr ""
+
+#: main.c:20
+#, fuzzy
+msgid "Save the current document"
+msgstr "Enregistrer le fichier courant"
+
+#: main.c:30
+msgid "Quit"
+msgstr "Quitter"
+`))
+	if err != nil {
+		panic(err)
+	}
+
+	mappings, err := Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	err = PrintMappings(os.Stdout, left, right, mappings)
+	if err != nil {
+		panic(err)
+	}
+	err = PrintFuzzy(os.Stdout, left, right, mappings)
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// 1,1
+	// 6,6
+	// 10,15
+	// 14,19
+	// _,10
+	// 15 "Save the current document"
+	//   was 10 "Save the current file" (0.56)
+	//   msgstr "Enregistrer le fichier courant"
+}
@@ -3,8 +3,13 @@ module github.com/SmartBear/lhdiff
 go 1.17
 
 require (
-	github.com/ianbruene/go-difflib v1.2.0
 	github.com/ka-weihe/fast-levenshtein v0.0.0-20201227151214-4c99ee36a1ba
 	github.com/rexsimiloluwah/distance_metrics v0.0.0-20211020112549-67979eee6077
-	github.com/sourcegraph/go-diff v0.6.1
 )
+
+require (
+	go.etcd.io/bbolt v1.3.6
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.0.0-20200923182605-d9f96fdee20d // indirect
@@ -0,0 +1,47 @@
+package lhdiff
+
+// HunkResult is a hunk of the diff that a SparseResult was computed from, along with the pairs
+// of its changed lines, for review tools that show changes hunk by hunk. Lines are zero-based,
+// and the hunk is the lines from LeftStart up to LeftEnd and from RightStart up to RightEnd,
+// including its unchanged context lines.
+type HunkResult struct {
+	LeftStart  int
+	LeftEnd    int
+	RightStart int
+	RightEnd   int
+	// Mappings are the mappings of the deleted lines of the hunk, in left line order, followed by
+	// those of its added lines that weren't paired with one of its deleted lines, in right line
+	// order. A pair of lines that moved from a hunk to another is in both.
+	Mappings []LineMapping
+}
+
+// Hunks groups the mappings of the changed lines by the hunks of the unified diff with context
+// lines of context that the result was computed from, as written by WithDiffOutput.
+func (result *SparseResult) Hunks(context int) []HunkResult {
+	// Every right line that isn't unchanged has a mapping
+	byRight := make(map[int]LineMapping)
+	for _, mapping := range result.Mappings {
+		if mapping.Right != -1 {
+			byRight[mapping.Right] = mapping
+		}
+	}
+	// The mappings of the changed left lines are in left line order, and so are the hunks
+	mappings := result.Mappings
+	var hunks []HunkResult
+	for _, h := range diffHunks(result.Identical, result.LeftLineCount, result.RightLineCount, context) {
+		hunkResult := HunkResult{LeftStart: h.leftStart, LeftEnd: h.leftEnd, RightStart: h.rightStart, RightEnd: h.rightEnd}
+		for len(mappings) > 0 && mappings[0].Left != -1 && mappings[0].Left < h.leftEnd {
+			hunkResult.Mappings = append(hunkResult.Mappings, mappings[0])
+			mappings = mappings[1:]
+		}
+		for _, c := range h.changes {
+			for right := c.right; right < c.rightEnd; right++ {
+				if mapping := byRight[right]; mapping.Left < h.leftStart || mapping.Left >= h.leftEnd {
+					hunkResult.Mappings = append(hunkResult.Mappings, mapping)
+				}
+			}
+		}
+		hunks = append(hunks, hunkResult)
+	}
+	return hunks
+}
@@ -1,22 +1,24 @@
 package lhdiff
 
 import (
-	"bytes"
 	"fmt"
-	"github.com/ianbruene/go-difflib/difflib"
 	levenshtein "github.com/ka-weihe/fast-levenshtein"
-	"github.com/sourcegraph/go-diff/diff"
 	"math"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type LineInfo struct {
 	lineNumber int
 	content    string
 	context    string
+	// vector is the TF-IDF vector of the context with WithVectorizer, and nil otherwise
+	vector *tfidfVector
+	// tokens are the terms of the context with WithTokenizer, and nil otherwise
+	tokens []string
 }
 
 type LinePair struct {
@@ -31,26 +33,71 @@ func (linePair LinePair) contentNormalizedLevenshteinSimilarity() float64 {
 }
 
 func (linePair LinePair) contextTfIdfCosineSimilarity() float64 {
+	if linePair.left.vector != nil && linePair.right.vector != nil {
+		return linePair.left.vector.cosineSimilarity(linePair.right.vector)
+	}
+	if linePair.left.tokens != nil && linePair.right.tokens != nil {
+		return tfIdfCosineSimilarity(linePair.left.tokens, linePair.right.tokens)
+	}
 	return TfIdfCosineSimilarity(linePair.left.context, linePair.right.context)
 }
 
 func (linePair LinePair) combinedSimilarity() float64 {
+	return linePair.weightedSimilarity(ContentSimilarityFactor, ContextSimilarityFactor, ContentSimilarityGate)
+}
+
+func (linePair LinePair) weightedSimilarity(contentFactor float64, contextFactor float64, contentGate float64) float64 {
 	contentSimilarity := linePair.contentNormalizedLevenshteinSimilarity()
-	if contentSimilarity <= 0.5 {
+	if contentSimilarity <= contentGate {
 		return 0.0
 	}
+	if contextFactor == 0 {
+		return contentSimilarity
+	}
 	contextSimilarity := linePair.contextTfIdfCosineSimilarity()
-	return ContentSimilarityFactor*contentSimilarity + ContextSimilarityFactor*contextSimilarity
+	return contentFactor*contentSimilarity + contextFactor*contextSimilarity
+}
+
+func (linePair LinePair) distance() int {
+	distance := linePair.left.lineNumber - linePair.right.lineNumber
+	if distance < 0 {
+		return -distance
+	}
+	return distance
 }
 
 type ByCombinedSimilarity []LinePair
 
 func (a ByCombinedSimilarity) Len() int { return len(a) }
 func (a ByCombinedSimilarity) Less(i, j int) bool {
-	return a[j].combinedSimilarity() < a[i].combinedSimilarity()
+	similarityI, similarityJ := a[i].combinedSimilarity(), a[j].combinedSimilarity()
+	if similarityI != similarityJ {
+		return similarityJ < similarityI
+	}
+	// Equally similar candidates are ranked by how close they are to each other, so that ties
+	// don't depend on the sort algorithm.
+	return a[i].distance() < a[j].distance()
 }
 func (a ByCombinedSimilarity) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
+// bySimilarity sorts pairs like ByCombinedSimilarity, by similarities computed up front.
+type bySimilarity struct {
+	pairs        []LinePair
+	similarities []float64
+}
+
+func (a bySimilarity) Len() int { return len(a.pairs) }
+func (a bySimilarity) Less(i, j int) bool {
+	if a.similarities[i] != a.similarities[j] {
+		return a.similarities[j] < a.similarities[i]
+	}
+	return a.pairs[i].distance() < a.pairs[j].distance()
+}
+func (a bySimilarity) Swap(i, j int) {
+	a.pairs[i], a.pairs[j] = a.pairs[j], a.pairs[i]
+	a.similarities[i], a.similarities[j] = a.similarities[j], a.similarities[i]
+}
+
 const ContextSimilarityFactor = 0.4
 const ContentSimilarityFactor = 0.6
 const SimilarityThreshold = 0.45
@@ -58,88 +105,249 @@ const SimilarityThreshold = 0.45
 func Lhdiff(left string, right string, contextSize int, includeIdenticalLines bool) ([][]int, error) {
 	leftLines := ConvertToLinesWithoutNewLine(left)
 	rightLines := ConvertToLinesWithoutNewLine(right)
-
-	mappedRightLines := make(map[int]bool)
-	allPairs := make(map[int]LinePair, 0)
-
-	diffScript, err := difflib.GetUnifiedDiffString(difflib.LineDiffParams{
-		A:        leftLines,
-		B:        rightLines,
-		FromFile: "left",
-		ToFile:   "right",
-		Context:  3,
-	})
-	//fmt.Println(diffScript)
+	pairs, err := computePairs(leftLines, rightLines, newOptions([]Option{WithContextSize(contextSize)}))
 	if err != nil {
 		return nil, err
 	}
-	if diffScript != "" {
-		fileDiff, err := diff.ParseFileDiff([]byte(diffScript))
-		if err != nil {
-			return nil, err
-		}
+	return lineMappings(pairs, leftLines, rightLines, includeIdenticalLines), nil
+}
+
+// pairing is the result of computePairs. The unchanged lines are represented as runs, so that
+// its size is proportional to the size of the changes rather than of the files.
+type pairing struct {
+	// identical are the runs of unchanged lines, in order
+	identical []Run
+	// similar are the pairs of changed lines indexed by left line number, with their similarities
+	similar      map[int]LinePair
+	similarities map[int]float64
+	// added are the right line numbers that aren't mapped, in order
+	added []int
+	// threshold is the similarity threshold that was used
+	threshold float64
+	// truncated is true if some added lines weren't compared, because of WithMaxComparisons or
+	// WithDeadline, and partial is true if they weren't because of WithContext
+	truncated bool
+	partial   bool
+	// anchors are the lines with lhdiff:anchor directives
+	anchors []Anchor
+}
+
+// pairCertainLines pairs the unchanged lines, the anchors, the pins and the lines that are equal,
+// and returns the left and right line numbers that are left to compare by similarity.
+func pairCertainLines(leftLines []string, rightLines []string, o *options) (*pairing, []int, []int, error) {
+	if err := checkLimits(len(leftLines), len(rightLines), o); err != nil {
+		return nil, nil, nil, err
+	}
+	contextSize := o.contextSize
+	pairs := &pairing{
+		similar:      make(map[int]LinePair),
+		similarities: make(map[int]float64),
+		added:        make([]int, 0),
+		threshold:    o.threshold,
+	}
 
-		unchangedDiffPairs, leftLineNumbers, rightLineNumbers := LineNumbersFromDiff(fileDiff, leftLines, rightLines, contextSize)
-		for _, unchangedDiffPair := range unchangedDiffPairs {
-			allPairs[unchangedDiffPair.left.lineNumber] = unchangedDiffPair
-			mappedRightLines[unchangedDiffPair.right.lineNumber] = true
+	var leftLineNumbers, rightLineNumbers []int
+	pairs.identical = o.diffEngine.Diff(leftLines, rightLines)
+	leftLineNumbers, rightLineNumbers = changedLines(pairs.identical, len(leftLines), len(rightLines))
+	if o.diffOutput != nil {
+		if err := writeUnifiedDiff(o.diffOutput, o.redactLines(leftLines), o.redactLines(rightLines), pairs.identical); err != nil {
+			return nil, nil, nil, err
 		}
+	}
+	pins := o.pins
+	if !o.ignoreAnchors {
+		pairs.anchors = findAnchors(leftLines, rightLines)
+		pins = anchorPins(pairs.anchors, pairs.identical, pins)
+	}
+	if len(pins) > 0 {
+		if err := checkPins(pins, len(leftLines), len(rightLines)); err != nil {
+			return nil, nil, nil, err
+		}
+		pairs.identical, leftLineNumbers, rightLineNumbers = applyPins(pins, pairs.identical, leftLineNumbers, rightLineNumbers)
+		for _, pin := range pins {
+			pair := LinePair{
+				left:  MakeLineInfo(pin.Left, leftLines, contextSize),
+				right: MakeLineInfo(pin.Right, rightLines, contextSize),
+			}
+			o.vectorize(pair.left, pair.right)
+			similarity := 1.0
+			if pair.left.content != pair.right.content {
+				similarity = o.similarity(pair)
+			}
+			pairs.similar[pin.Left] = pair
+			pairs.similarities[pin.Left] = similarity
+		}
+	}
+	if !o.reference {
+		leftLineNumbers, rightLineNumbers = pairs.pairEqualLines(leftLines, rightLines, leftLineNumbers, rightLineNumbers, contextSize)
+	}
+	return pairs, leftLineNumbers, rightLineNumbers, nil
+}
 
-		leftLineInfos := MakeLineInfos(leftLineNumbers, leftLines, contextSize)
-		rightLineInfos := MakeLineInfos(rightLineNumbers, rightLines, contextSize)
+// computePairs pairs the lines of left with the lines of right.
+func computePairs(leftLines []string, rightLines []string, o *options) (*pairing, error) {
+	if o.cancelled() {
+		return nil, o.ctx.Err()
+	}
+	var deadline time.Time
+	if o.deadline > 0 {
+		deadline = time.Now().Add(o.deadline)
+	}
+	pairs, leftLineNumbers, rightLineNumbers, err := pairCertainLines(leftLines, rightLines, o)
+	if err != nil {
+		return nil, err
+	}
+	var certain map[int]bool
+	if o.visitor != nil {
+		certain = pairs.visitCertain(o.visitor)
+	}
+	if !o.reference && o.cancelled() {
+		pairs.partial = true
+		pairs.added = append(pairs.added, rightLineNumbers...)
+		return pairs, nil
+	}
+	contextSize := o.contextSize
+	leftLineInfos := MakeLineInfos(leftLineNumbers, leftLines, contextSize)
+	rightLineInfos := MakeLineInfos(rightLineNumbers, rightLines, contextSize)
+	if o.reference {
+		mappedRightLines := pairs.pairReference(leftLineInfos, rightLineInfos)
+		for _, rightLineNumber := range rightLineNumbers {
+			if !mappedRightLines[rightLineNumber] {
+				pairs.added = append(pairs.added, rightLineNumber)
+			}
+		}
+		pairs.visitSimilar(o.visitor, certain)
+		return pairs, nil
+	}
+	o.vectorize(leftLineInfos...)
+	o.vectorize(rightLineInfos...)
 
-		for _, rightLineInfo := range rightLineInfos {
-			var similarPairCandidates []LinePair
-			for _, leftLineInfo := range leftLineInfos {
-				pair := LinePair{
-					left:  leftLineInfo,
-					right: rightLineInfo,
-				}
-				similarPairCandidates = append(similarPairCandidates, pair)
+	var debugLog *candidateLog
+	if o.debug != nil {
+		debugLog = &candidateLog{w: o.debug, count: o.debugCandidates, redact: o.redaction()}
+	}
+	var candidates []LinePair
+	var candidateSimilarities []float64
+	// The similarities of the second most similar candidates, used to calibrate the threshold
+	var runnerUpSimilarities []float64
+	comparisons := 0
+	for _, rightLineInfo := range rightLineInfos {
+		// The right lines that are left out of budget are added
+		comparisons += len(leftLineInfos)
+		if (o.maxComparisons > 0 && comparisons > o.maxComparisons) || (!deadline.IsZero() && time.Now().After(deadline)) {
+			pairs.truncated = true
+			break
+		}
+		if o.cancelled() {
+			pairs.partial = true
+			break
+		}
+		similarPairCandidates := bySimilarity{
+			pairs:        make([]LinePair, 0, len(leftLineInfos)),
+			similarities: make([]float64, 0, len(leftLineInfos)),
+		}
+		for _, leftLineInfo := range leftLineInfos {
+			pair := LinePair{
+				left:  leftLineInfo,
+				right: rightLineInfo,
 			}
-			sort.Sort(ByCombinedSimilarity(similarPairCandidates))
-			if len(similarPairCandidates) > 0 {
-				mostSimilarPair := similarPairCandidates[0]
-				if mostSimilarPair.combinedSimilarity() > SimilarityThreshold {
-					allPairs[mostSimilarPair.left.lineNumber] = mostSimilarPair
-					mappedRightLines[mostSimilarPair.right.lineNumber] = true
+			similarPairCandidates.pairs = append(similarPairCandidates.pairs, pair)
+			similarPairCandidates.similarities = append(similarPairCandidates.similarities, o.similarity(pair))
+		}
+		sort.Stable(similarPairCandidates)
+		if debugLog != nil {
+			debugLog.add(similarPairCandidates)
+		}
+		if len(similarPairCandidates.pairs) > 0 {
+			candidates = append(candidates, similarPairCandidates.pairs[0])
+			candidateSimilarities = append(candidateSimilarities, similarPairCandidates.similarities[0])
+		}
+		if len(similarPairCandidates.pairs) > 1 && o.calibrate {
+			runnerUpSimilarities = append(runnerUpSimilarities, similarPairCandidates.similarities[1])
+		}
+	}
+	if o.calibrate {
+		pairs.threshold = calibrateThreshold(runnerUpSimilarities)
+	}
+	threshold := pairs.threshold
+	mappedRightLines := make(map[int]bool)
+	for i, mostSimilarPair := range candidates {
+		similarity := candidateSimilarities[i]
+		if similarity > threshold {
+			// A later right line takes the left line over, and the earlier one is added instead
+			if previous, exists := pairs.similar[mostSimilarPair.left.lineNumber]; exists {
+				delete(mappedRightLines, previous.right.lineNumber)
+				if o.visitor != nil {
+					o.visitor.Rejected(LineMapping{Left: previous.left.lineNumber, Right: previous.right.lineNumber, Similarity: pairs.similarities[previous.left.lineNumber]})
 				}
 			}
+			pairs.similar[mostSimilarPair.left.lineNumber] = mostSimilarPair
+			pairs.similarities[mostSimilarPair.left.lineNumber] = similarity
+			mappedRightLines[mostSimilarPair.right.lineNumber] = true
+		} else if o.visitor != nil {
+			o.visitor.Rejected(LineMapping{Left: mostSimilarPair.left.lineNumber, Right: mostSimilarPair.right.lineNumber, Similarity: similarity})
 		}
-	} else {
-		// The files are identical
-		for leftLineNumber := range leftLines {
-			lineInfo := MakeLineInfo(leftLineNumber, leftLines, 4)
-			allPairs[leftLineNumber] = LinePair{
-				left:  lineInfo,
-				right: lineInfo,
-			}
-			mappedRightLines[leftLineNumber] = true
+	}
+	for _, rightLineNumber := range rightLineNumbers {
+		if !mappedRightLines[rightLineNumber] {
+			pairs.added = append(pairs.added, rightLineNumber)
 		}
 	}
-	rightLineNumbers := make([]int, 0)
-	for rightLineNumber := range rightLines {
-		_, mapped := mappedRightLines[rightLineNumber]
-		if !mapped {
-			rightLineNumbers = append(rightLineNumbers, rightLineNumber)
+	if debugLog != nil {
+		debugLog.write(rightLineInfos, threshold, pairs)
+	}
+	pairs.visitSimilar(o.visitor, certain)
+	return pairs, nil
+}
+
+// changedLines returns the left line numbers that aren't in runs, which were deleted, and the
+// right line numbers that aren't in runs, which were added.
+func changedLines(runs []Run, leftLineCount int, rightLineCount int) ([]int, []int) {
+	var leftLineNumbers []int
+	var rightLineNumbers []int
+	left, right := 0, 0
+	for i := 0; i <= len(runs); i++ {
+		r := Run{Left: leftLineCount, Right: rightLineCount}
+		if i < len(runs) {
+			r = runs[i]
+		}
+		for ; left < r.Left; left++ {
+			leftLineNumbers = append(leftLineNumbers, left)
 		}
+		for ; right < r.Right; right++ {
+			rightLineNumbers = append(rightLineNumbers, right)
+		}
+		left, right = r.Left+r.Length, r.Right+r.Length
 	}
-	return lineMappings(allPairs, len(leftLines), rightLineNumbers, includeIdenticalLines), nil
+	return leftLineNumbers, rightLineNumbers
 }
 
-func lineMappings(linePairs map[int]LinePair, leftLineCount int, newRightLines []int, includeIdenticalLines bool) [][]int {
-	lines := make([][]int, 0)
+// forEachMapping calls f with each left line number in order, the right line number it maps to
+// (or -1) and the similarity of the pair.
+func (pairs *pairing) forEachMapping(leftLineCount int, f func(left int, right int, similarity float64)) {
+	runs := pairs.identical
 	for leftLineNumber := 0; leftLineNumber < leftLineCount; leftLineNumber++ {
-		pair, exists := linePairs[leftLineNumber]
-		if !exists {
-			lines = append(lines, []int{leftLineNumber, -1})
+		for len(runs) > 0 && runs[0].Left+runs[0].Length <= leftLineNumber {
+			runs = runs[1:]
+		}
+		if len(runs) > 0 && runs[0].Left <= leftLineNumber {
+			f(leftLineNumber, runs[0].Right+leftLineNumber-runs[0].Left, 1)
+		} else if pair, exists := pairs.similar[leftLineNumber]; exists {
+			f(leftLineNumber, pair.right.lineNumber, pairs.similarities[leftLineNumber])
 		} else {
-			if includeIdenticalLines || !(pair.left.content == pair.right.content && leftLineNumber == pair.right.lineNumber) {
-				lines = append(lines, []int{leftLineNumber, pair.right.lineNumber})
-			}
+			f(leftLineNumber, -1, 0)
 		}
 	}
-	for _, rightLine := range newRightLines {
+}
+
+func lineMappings(pairs *pairing, leftLines []string, rightLines []string, includeIdenticalLines bool) [][]int {
+	lines := make([][]int, 0)
+	pairs.forEachMapping(len(leftLines), func(left int, right int, _ float64) {
+		if right == -1 || includeIdenticalLines || !(leftLines[left] == rightLines[right] && left == right) {
+			lines = append(lines, []int{left, right})
+		}
+	})
+	for _, rightLine := range pairs.added {
 		lines = append(lines, []int{-1, rightLine})
 	}
 	return lines
@@ -176,8 +384,7 @@ func GetContext(lineNumber int, lines []string, contextSize int) string {
 
 	for j := 0; i >= 0 && j < contextSize; {
 		line := lines[i]
-		trimmed := strings.TrimSpace(line)
-		if len(trimmed) != 0 && !brackets.MatchString(trimmed) {
+		if IsSignificant(line) {
 			context = append([]string{line}, context...)
 			j++
 		}
@@ -187,8 +394,7 @@ func GetContext(lineNumber int, lines []string, contextSize int) string {
 	i = lineNumber + 1
 	for j := 0; i < len(lines) && j < contextSize; {
 		line := lines[i]
-		trimmed := strings.TrimSpace(line)
-		if len(trimmed) != 0 && !brackets.MatchString(trimmed) {
+		if IsSignificant(line) {
 			context = append(context, line)
 			j++
 		}
@@ -198,92 +404,26 @@ func GetContext(lineNumber int, lines []string, contextSize int) string {
 	return strings.Join(context, "")
 }
 
-// LineNumbersFromDiff returns two slices:
-// 1: a slice of removed line numbers in left
-// 2: a slice of added line numbers in right
-// 3:
-func LineNumbersFromDiff(fileDiff *diff.FileDiff, leftLines []string, rightLines []string, contextSize int) ([]LinePair, []int, []int) {
-	var unchangedPairs []LinePair
-	// Deleted from left
-	var leftLineNumbers []int
-	// Added to right
-	var rightLineNumbers []int
-
-	previousLeftLineNumber := 0
-	previousRightLineNumber := 0
-	for _, hunk := range fileDiff.Hunks {
-		unchangedHunkPairs, leftLineNumbersHunk, rightLineNumbersHunk := LineNumbersFromHunk(hunk, leftLines, rightLines, previousLeftLineNumber, previousRightLineNumber, contextSize)
-		leftLineNumbers = append(leftLineNumbers, leftLineNumbersHunk...)
-		rightLineNumbers = append(rightLineNumbers, rightLineNumbersHunk...)
-		unchangedPairs = append(unchangedPairs, unchangedHunkPairs...)
-		previousLeftLineNumber = int(hunk.OrigStartLine - 1 + hunk.OrigLines)
-		previousRightLineNumber = int(hunk.NewStartLine - 1 + hunk.NewLines)
-	}
-	// Add unchanged lines after last hunk
-	leftLineNumber := previousLeftLineNumber
-	rightLineNumber := previousRightLineNumber
-	for leftLineNumber >= 0 && leftLineNumber < len(leftLines) {
-		leftLineInfo := MakeLineInfo(leftLineNumber, leftLines, contextSize)
-		rightLineInfo := MakeLineInfo(rightLineNumber, rightLines, contextSize)
-		unchangedPairs = append(unchangedPairs, LinePair{
-			left:  leftLineInfo,
-			right: rightLineInfo,
-		})
-		leftLineNumber++
-		rightLineNumber++
-	}
-	return unchangedPairs, leftLineNumbers, rightLineNumbers
-}
-
-func LineNumbersFromHunk(hunk *diff.Hunk, leftLines []string, rightLines []string, previousLeftLineNumber int, previousRightLineNumber int, contextSize int) ([]LinePair, []int, []int) {
-	var unchangedPairs []LinePair
-	leftLineNumbers := make([]int, 0)
-	rightLineNumbers := make([]int, 0)
-
-	leftLineNumber := previousLeftLineNumber
-	rightLineNumber := previousRightLineNumber
-	for leftLineNumber < int(hunk.OrigStartLine)-1 {
-		leftLineInfo := MakeLineInfo(leftLineNumber, leftLines, contextSize)
-		rightLineInfo := MakeLineInfo(rightLineNumber, rightLines, contextSize)
-		unchangedPairs = append(unchangedPairs, LinePair{
-			left:  leftLineInfo,
-			right: rightLineInfo,
-		})
-		leftLineNumber++
-		rightLineNumber++
-	}
-
-	lines := bytes.Split(hunk.Body, []byte{'\n'})
-
-	for _, line := range lines {
-		if len(line) == 0 {
-			continue
-		}
-		switch line[0] {
-		case '-':
-			leftLineNumbers = append(leftLineNumbers, leftLineNumber)
-			leftLineNumber++
-		case '+':
-			rightLineNumbers = append(rightLineNumbers, rightLineNumber)
-			rightLineNumber++
-		default:
-			unchangedPairs = append(unchangedPairs, LinePair{
-				left:  MakeLineInfo(leftLineNumber, leftLines, contextSize),
-				right: MakeLineInfo(rightLineNumber, rightLines, contextSize),
-			})
-			leftLineNumber++
-			rightLineNumber++
-		}
-	}
-	return unchangedPairs, leftLineNumbers, rightLineNumbers
+// IsSignificant returns false for "insignificant" lines, i.e. lines that are either blank
+// or just a curly brace or parenthesis (whitespace trimmed).
+func IsSignificant(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) != 0 && !brackets.MatchString(trimmed)
 }
 
+// ConvertToLinesWithoutNewLine splits text into lines normalized with RemoveMultipleSpaceAndTrim.
+// The lines are views of text, and only the lines that aren't normalized already are copied.
 func ConvertToLinesWithoutNewLine(text string) []string {
 	if text == "" {
 		return make([]string, 0)
 	}
 	lines := strings.SplitAfter(text, "\n")
-	return Map(lines, RemoveMultipleSpaceAndTrim)
+	for i, line := range lines {
+		if !isNormalized(line) {
+			lines[i] = RemoveMultipleSpaceAndTrim(line)
+		}
+	}
+	return lines
 }
 
 func Map(vs []string, f func(string) string) []string {
@@ -294,9 +434,10 @@ func Map(vs []string, f func(string) string) []string {
 	return vsm
 }
 
+var /* const */ spaces = regexp.MustCompile("[ \t]+")
+
 func RemoveMultipleSpaceAndTrim(s string) string {
-	re := regexp.MustCompile("[ \t]+")
-	return strings.TrimSpace(re.ReplaceAllString(s, " ")) + "\n"
+	return strings.TrimSpace(spaces.ReplaceAllString(s, " ")) + "\n"
 }
 
 func PrintMappings(mappings [][]int) error {
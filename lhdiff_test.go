@@ -296,7 +296,7 @@ func ExampleLhdiff_withDataFromPaper() {
 	//4,3
 	//5,_
 	//6,6
-	//7,10
+	//7,_
 	//8,_
 	//9,_
 	//10,_
@@ -304,7 +304,7 @@ func ExampleLhdiff_withDataFromPaper() {
 	//12,_
 	//13,_
 	//14,15
-	//15,_
+	//15,10
 	//16,16
 	//17,17
 	//_,4
@@ -983,7 +983,7 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//34,22
 	//35,23
 	//36,24
-	//37,_
+	//37,25
 	//38,_
 	//39,_
 	//40,_
@@ -995,7 +995,7 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//46,_
 	//47,_
 	//48,_
-	//49,25
+	//49,_
 	//50,26
 	//51,27
 	//52,28
@@ -1025,7 +1025,7 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//76,52
 	//77,53
 	//78,54
-	//79,57
+	//79,55
 	//80,58
 	//81,59
 	//82,60
@@ -1047,28 +1047,28 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//98,_
 	//99,145
 	//100,_
-	//101,120
+	//101,_
 	//102,122
-	//103,146
+	//103,73
 	//104,124
 	//105,_
 	//106,_
 	//107,_
 	//108,130
 	//109,_
-	//110,132
+	//110,129
 	//111,_
 	//112,_
-	//113,_
+	//113,132
 	//114,_
 	//115,_
-	//116,134
-	//117,_
+	//116,_
+	//117,137
 	//118,_
 	//119,_
 	//120,114
 	//121,72
-	//122,73
+	//122,146
 	//123,74
 	//124,75
 	//125,76
@@ -1105,9 +1105,9 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//156,107
 	//157,108
 	//158,133
-	//159,135
-	//160,136
-	//161,137
+	//159,134
+	//160,135
+	//161,136
 	//162,138
 	//163,139
 	//164,140
@@ -1120,7 +1120,7 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//171,153
 	//172,154
 	//173,_
-	//174,155
+	//174,144
 	//175,156
 	//176,157
 	//177,158
@@ -1173,21 +1173,21 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//224,203
 	//225,204
 	//226,_
-	//227,_
-	//228,_
+	//227,209
+	//228,210
 	//229,_
 	//230,_
 	//231,_
 	//232,_
 	//233,_
 	//234,_
-	//235,_
-	//236,_
+	//235,211
+	//236,243
 	//237,213
 	//238,214
 	//239,215
 	//240,216
-	//241,243
+	//241,217
 	//242,218
 	//243,_
 	//244,_
@@ -1237,18 +1237,30 @@ func RemoveMultipleSpaceAndTrim(s string) string {
 	//288,266
 	//289,267
 	//290,268
+	//_,56
+	//_,57
 	//_,69
+	//_,71
 	//_,109
+	//_,110
+	//_,111
 	//_,115
 	//_,116
 	//_,117
+	//_,118
+	//_,119
+	//_,120
+	//_,121
+	//_,123
 	//_,125
 	//_,126
 	//_,127
 	//_,128
 	//_,131
 	//_,142
+	//_,143
 	//_,147
+	//_,155
 	//_,205
 	//_,206
 	//_,207
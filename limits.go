@@ -0,0 +1,52 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooLarge is matched by errors.Is for the errors returned for files that are too large to
+// compare, which are *LimitError.
+var ErrTooLarge = errors.New("file too large")
+
+// LimitError is returned when a file exceeds the limit set with WithMaxLines.
+type LimitError struct {
+	// Side is "left" or "right"
+	Side  string
+	Lines int
+	Limit int
+}
+
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("the %s file has %d lines, more than the limit of %d", err.Side, err.Lines, err.Limit)
+}
+
+// Is makes errors.Is(err, ErrTooLarge) true.
+func (err *LimitError) Is(target error) bool {
+	return target == ErrTooLarge
+}
+
+// checkLimits returns a *LimitError if left or right has more lines than WithMaxLines allows.
+// It is checked before the cache is, since the limit isn't part of the key of the cache.
+func checkLimits(leftLineCount int, rightLineCount int, o *options) error {
+	limit := o.maxLines
+	if limit <= 0 {
+		return nil
+	}
+	if leftLineCount > limit {
+		return &LimitError{Side: "left", Lines: leftLineCount, Limit: limit}
+	}
+	if rightLineCount > limit {
+		return &LimitError{Side: "right", Lines: rightLineCount, Limit: limit}
+	}
+	return nil
+}
+
+// lineCount returns the number of lines that ConvertToLinesWithoutNewLine splits text into.
+func lineCount(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
@@ -0,0 +1,51 @@
+package lhdiff
+
+// isNormalized returns true if RemoveMultipleSpaceAndTrim(line) == line, without allocating.
+// It may return false for some lines that are normalized, such as lines starting or ending
+// with a non-ASCII character, which are then normalized the slow way.
+func isNormalized(line string) bool {
+	n := len(line)
+	if n == 0 || line[n-1] != '\n' {
+		return false
+	}
+	content := line[:n-1]
+	if content == "" {
+		return true
+	}
+	if isSpaceOrNonASCII(content[0]) || isSpaceOrNonASCII(content[len(content)-1]) {
+		return false
+	}
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\t', '\n':
+			return false
+		case ' ':
+			if content[i+1] == ' ' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isSpaceOrNonASCII(b byte) bool {
+	switch b {
+	case '\t', '\n', '\v', '\f', '\r', ' ':
+		return true
+	}
+	return b >= 0x80
+}
+
+// normalizeLines returns lines normalized with RemoveMultipleSpaceAndTrim, like
+// ConvertToLinesWithoutNewLine, without modifying lines.
+func normalizeLines(lines []string) []string {
+	normalized := make([]string, len(lines))
+	for i, line := range lines {
+		if isNormalized(line) {
+			normalized[i] = line
+		} else {
+			normalized[i] = RemoveMultipleSpaceAndTrim(line)
+		}
+	}
+	return normalized
+}
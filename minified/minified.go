@@ -0,0 +1,132 @@
+// Package minified tracks minified JavaScript and CSS, where a whole file is on one or a few
+// long lines that can't be paired with anything, by splitting the lines into pseudo-lines at
+// statement boundaries. The pseudo-lines are tracked with lhdiff, and mapped back to the column
+// ranges of the original lines.
+package minified
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"strings"
+)
+
+// Segment is a pseudo-line: the bytes from Start up to End (zero-based columns) of the
+// zero-based line Line.
+type Segment struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// Result is the result of comparing two minified files. Mappings map the indexes of the
+// segments of the left file to those of the right file, like the line numbers of lhdiff.Result.
+type Result struct {
+	LeftSegments  []Segment
+	RightSegments []Segment
+	Mappings      []lhdiff.LineMapping
+}
+
+// Split splits text into segments, ending each segment after a ; or a } and at the end of
+// each line. Semicolons and braces in strings, template literals and /* */ comments don't end
+// segments, and strings continued by a backslash at the end of a line go on on the next line.
+// Lines without any boundary, such as the lines of files that aren't minified, are a segment
+// each.
+func Split(text string) []Segment {
+	segments, _ := split(text)
+	return segments
+}
+
+// split returns the segments of text along with their texts.
+func split(text string) ([]Segment, []string) {
+	var segments []Segment
+	var texts []string
+	line, lineStart, start := 0, 0, 0
+	var quote byte
+	// continued is true after a backslash ending a line in a string, which continues the string
+	comment, continued := false, false
+	end := func(i int) {
+		// Segments start at their first non-blank character, and blank ones are left out
+		for start < i && (text[start] == ' ' || text[start] == '\t') {
+			start++
+		}
+		if i > start {
+			segments = append(segments, Segment{Line: line, Start: start - lineStart, End: i - lineStart})
+			texts = append(texts, text[start:i])
+		}
+		start = i
+	}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\n':
+			if i > start && text[i-1] == '\r' {
+				end(i - 1)
+			} else {
+				end(i)
+			}
+			line, lineStart, start = line+1, i+1, i+1
+			// Strings end with their line unless it is continued, but template literals and
+			// comments may span lines
+			if quote != '`' && !continued {
+				quote = 0
+			}
+			continued = false
+		case comment:
+			if c == '*' && i+1 < len(text) && text[i+1] == '/' {
+				comment = false
+				i++
+			}
+		case quote != 0:
+			if c == '\\' && (strings.HasPrefix(text[i+1:], "\n") || strings.HasPrefix(text[i+1:], "\r\n")) {
+				// The line ends all the same
+				continued = true
+			} else if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '/' && i+1 < len(text) && text[i+1] == '*':
+			comment = true
+			i++
+		case c == ';' || c == '}':
+			end(i + 1)
+		}
+	}
+	end(len(text))
+	return segments, texts
+}
+
+// Compare splits left and right into segments with Split, and maps the segments of left to
+// those of right with lhdiff using opts.
+func Compare(left string, right string, opts ...lhdiff.Option) (*Result, error) {
+	leftSegments, leftTexts := split(left)
+	rightSegments, rightTexts := split(right)
+	lines, err := lhdiff.LhdiffLines(leftTexts, rightTexts, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{LeftSegments: leftSegments, RightSegments: rightSegments, Mappings: lines.Mappings}, nil
+}
+
+// PrintMappings prints the mappings of a result as left,right where each side is the one-based
+// line:start-end range of columns (inclusive) of a segment, or _ if it has no counterpart.
+func PrintMappings(w io.Writer, result *Result) error {
+	for _, mapping := range result.Mappings {
+		_, err := fmt.Fprintf(w, "%s,%s\n", columns(result.LeftSegments, mapping.Left), columns(result.RightSegments, mapping.Right))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func columns(segments []Segment, i int) string {
+	if i == -1 {
+		return "_"
+	}
+	segment := segments[i]
+	return fmt.Sprintf("%d:%d-%d", segment.Line+1, segment.Start+1, segment.End)
+}
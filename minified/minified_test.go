@@ -0,0 +1,51 @@
+package minified
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func ExampleSplit() {
+	text := `var a="x;y";function f(){return a}/* ; */f();` + "\n" + `body{color:red; margin:0}`
+	lines := strings.Split(text, "\n")
+	for _, segment := range Split(text) {
+		fmt.Printf("%d:%d-%d %s\n", segment.Line+1, segment.Start+1, segment.End, lines[segment.Line][segment.Start:segment.End])
+	}
+
+	// Output:
+	// 1:1-12 var a="x;y";
+	// 1:13-34 function f(){return a}
+	// 1:35-45 /* ; */f();
+	// 2:1-15 body{color:red;
+	// 2:17-25 margin:0}
+}
+
+func ExampleCompare() {
+	left := `function add(a,b){return a+b}function sub(a,b){return a-b}var x=add(1,2);console.log("x=",x);`
+	right := `function sub(a,b){return a-b}function add(a,b){return a+b}var x=add(1,2),y=sub(3,1);console.log("x=",x);console.log("y=",y);`
+
+	result, err := Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	err = PrintMappings(os.Stdout, result)
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// 1:1-29,1:30-58
+	// 1:30-58,1:1-29
+	// 1:59-73,1:59-84
+	// 1:74-93,1:85-104
+	// _,1:105-124
+}
+
+func ExampleSplit_continuedString() {
+	// The string goes on on the next line, where its ; doesn't end a segment either
+	fmt.Println(Split("var s='a\\\nb;';x();\ny();"))
+
+	// Output:
+	// [{0 0 9} {1 0 4} {1 4 8} {2 0 4}]
+}
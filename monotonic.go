@@ -0,0 +1,66 @@
+package lhdiff
+
+import (
+	"sort"
+)
+
+// increasingPairs returns the indexes of the longest subsequence of mappings whose right lines
+// increase. The mappings are in left line order, and only those with a right line are considered.
+func increasingPairs(mappings []LineMapping) map[int]bool {
+	// tails[k] is the index of the mapping ending the best subsequence of length k+1 found so far
+	var tails []int
+	previous := make([]int, len(mappings))
+	for i, mapping := range mappings {
+		if mapping.Left == -1 || mapping.Right == -1 {
+			continue
+		}
+		k := sort.Search(len(tails), func(k int) bool {
+			return mappings[tails[k]].Right >= mapping.Right
+		})
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	increasing := make(map[int]bool, len(tails))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i != -1; i = previous[i] {
+			increasing[i] = true
+		}
+	}
+	return increasing
+}
+
+// demoteCrossings unpairs the mappings that cross the longest non-crossing alignment, and
+// returns them. The right lines of the demoted mappings are added to the added lines.
+func demoteCrossings(result *Result) []LineMapping {
+	increasing := increasingPairs(result.Mappings)
+	var moved []LineMapping
+	var added []int
+	for i := range result.Mappings {
+		mapping := &result.Mappings[i]
+		if mapping.Left == -1 {
+			added = append(added, mapping.Right)
+			continue
+		}
+		if mapping.Right != -1 && !increasing[i] {
+			moved = append(moved, *mapping)
+			added = append(added, mapping.Right)
+			mapping.Right, mapping.Similarity = -1, 0
+		}
+	}
+	if moved == nil {
+		return nil
+	}
+	sort.Ints(added)
+	result.Mappings = result.Mappings[:result.LeftLineCount]
+	for _, right := range added {
+		result.Mappings = append(result.Mappings, LineMapping{Left: -1, Right: right})
+	}
+	return moved
+}
@@ -0,0 +1,77 @@
+package lhdiff
+
+import (
+	"io"
+)
+
+// The default colors of git diff
+const (
+	colorMeta                = "\x1b[1m"
+	colorFrag                = "\x1b[36m"
+	colorOld                 = "\x1b[31m"
+	colorNew                 = "\x1b[32m"
+	colorOldMoved            = "\x1b[1;35m"
+	colorOldMovedAlternative = "\x1b[1;34m"
+	colorNewMoved            = "\x1b[1;36m"
+	colorNewMovedAlternative = "\x1b[1;33m"
+)
+
+// WriteColorMovedDiff writes the unified diff that result was computed from with the colors of
+// git diff --color-moved=zebra, so that pagers and review tools that render git's move
+// annotations show the moves that lhdiff found. leftLines and rightLines are the lines that were
+// compared, with their line endings.
+//
+// A deleted line that is paired with an added line of another change is moved, including when it
+// was modified as well, unlike with git, which only detects lines that moved unchanged. Lines
+// that moved together, to consecutive lines, are a block, and a block that directly follows
+// another one has the alternative color of moved lines.
+func WriteColorMovedDiff(w io.Writer, leftLines []string, rightLines []string, result *SparseResult) error {
+	pairedLefts := make(map[int]int)
+	pairedRights := make(map[int]int)
+	for _, mapping := range result.Mappings {
+		if mapping.Left != -1 && mapping.Right != -1 {
+			pairedRights[mapping.Left] = mapping.Right
+			pairedLefts[mapping.Right] = mapping.Left
+		}
+	}
+	deleted := make(map[int]string)
+	added := make(map[int]string)
+	for _, h := range diffHunks(result.Identical, result.LeftLineCount, result.RightLineCount, 3) {
+		for _, c := range h.changes {
+			colorMoved(deleted, c.left, c.leftEnd, c.right, c.rightEnd, pairedRights, colorOld, colorOldMoved, colorOldMovedAlternative)
+			colorMoved(added, c.right, c.rightEnd, c.left, c.leftEnd, pairedLefts, colorNew, colorNewMoved, colorNewMovedAlternative)
+		}
+	}
+	return writeDiff(w, leftLines, rightLines, result.Identical, &diffColors{
+		meta:    colorMeta,
+		frag:    colorFrag,
+		deleted: func(left int) string { return deleted[left] },
+		added:   func(right int) string { return added[right] },
+	})
+}
+
+// colorMoved colors the lines from start up to end of one side of a change, whose other side is
+// the lines from otherStart up to otherEnd. paired are the lines of the other file that lines are
+// paired with.
+func colorMoved(colors map[int]string, start int, end int, otherStart int, otherEnd int, paired map[int]int, color string, moved string, alternative string) {
+	previous, previousColor := -1, ""
+	for line := start; line < end; line++ {
+		other, ok := paired[line]
+		if !ok || (other >= otherStart && other < otherEnd) {
+			colors[line] = color
+			previousColor = ""
+			continue
+		}
+		// A line that doesn't continue the block of the previous line starts a new block
+		if previousColor == "" || other != previous+1 {
+			switch previousColor {
+			case moved:
+				previousColor = alternative
+			default:
+				previousColor = moved
+			}
+		}
+		colors[line] = previousColor
+		previous = other
+	}
+}
@@ -0,0 +1,102 @@
+package mutation
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SmartBear/lhdiff"
+	"os"
+)
+
+// goMutestingCategories are the lists of mutants in a go-mutesting report.json
+var goMutestingCategories = []string{"escaped", "timeouted", "killed", "errored"}
+
+// RemapGoMutesting remaps a go-mutesting report.json. The old version of each file is the
+// originalSourceCode embedded in the report (or read from old if it is missing), and the new
+// version is read from current. Stale mutants are moved to a "stale" list, since go-mutesting
+// has no status for mutants that need to run again.
+func RemapGoMutesting(report []byte, old lhdiff.Snapshot, current lhdiff.Snapshot, opts ...lhdiff.Option) ([]byte, Stats, error) {
+	var stats Stats
+	var doc map[string]interface{}
+	if err := json.Unmarshal(report, &doc); err != nil {
+		return nil, stats, err
+	}
+	results := make(map[string]*lhdiff.Result)
+	var stale []interface{}
+	for _, category := range goMutestingCategories {
+		mutants, _ := doc[category].([]interface{})
+		if mutants == nil {
+			continue
+		}
+		remapped := make([]interface{}, 0, len(mutants))
+		for _, m := range mutants {
+			mutant, _ := m.(map[string]interface{})
+			mutator, _ := mutant["mutator"].(map[string]interface{})
+			if mutator == nil {
+				continue
+			}
+			path, _ := mutator["originalFilePath"].(string)
+			result, newSource, err := goMutestingResult(results, path, mutator, old, current, opts)
+			if err != nil {
+				return nil, stats, err
+			}
+			if result == nil {
+				stats.Dropped++
+				continue
+			}
+			line, _ := mutator["originalStartLine"].(float64)
+			newLine, unchanged, ok := remapLine(result, int(line))
+			if !ok {
+				stats.Dropped++
+				continue
+			}
+			mutator["originalStartLine"] = newLine
+			if _, embedded := mutator["originalSourceCode"].(string); embedded {
+				mutator["originalSourceCode"] = newSource
+			}
+			if !unchanged {
+				stats.Stale++
+				stale = append(stale, mutant)
+				continue
+			}
+			stats.Remapped++
+			remapped = append(remapped, mutant)
+		}
+		doc[category] = remapped
+	}
+	if len(stale) > 0 {
+		doc["stale"] = stale
+	}
+	out, err := marshal(doc)
+	return out, stats, err
+}
+
+// goMutestingResult compares the old and new versions of path, once per path. The result is
+// nil if the file no longer exists.
+func goMutestingResult(results map[string]*lhdiff.Result, path string, mutator map[string]interface{}, old lhdiff.Snapshot, current lhdiff.Snapshot, opts []lhdiff.Option) (*lhdiff.Result, string, error) {
+	newSource, err := current.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if result, ok := results[path]; ok {
+		return result, newSource, nil
+	}
+	source, embedded := mutator["originalSourceCode"].(string)
+	if !embedded {
+		if old == nil {
+			return nil, "", errors.New("the report has no originalSourceCode, and no old snapshot was given: " + path)
+		}
+		source, err = old.ReadFile(path)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	result, err := lhdiff.Compare(source, newSource, append([]lhdiff.Option{lhdiff.WithPath(path)}, opts...)...)
+	if err != nil {
+		return nil, "", err
+	}
+	results[path] = result
+	return result, newSource, nil
+}
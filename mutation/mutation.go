@@ -0,0 +1,131 @@
+// Package mutation remaps mutation-testing reports from the revision they were generated on
+// to the current revision, so that expensive mutation results can be reused across small edits.
+//
+// Mutants on unchanged lines keep their result. Mutants on modified lines are marked as stale,
+// since their result may no longer hold, and mutants on deleted lines are dropped.
+package mutation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+)
+
+// Stats counts what happened to the mutants of a report.
+type Stats struct {
+	Remapped int
+	Stale    int
+	Dropped  int
+}
+
+func (stats Stats) String() string {
+	return fmt.Sprintf("%d remapped, %d stale, %d dropped", stats.Remapped, stats.Stale, stats.Dropped)
+}
+
+// StaleStatus is the Stryker status given to mutants on modified lines, telling Stryker
+// to run them again.
+const StaleStatus = "Pending"
+
+// RemapStryker remaps a report in the Stryker mutation-testing-report-schema format. The
+// old version of each file is the source embedded in the report, the new version is read from
+// current and replaces the embedded source. Files that no longer exist are dropped.
+func RemapStryker(report []byte, current lhdiff.Snapshot, opts ...lhdiff.Option) ([]byte, Stats, error) {
+	var stats Stats
+	var doc map[string]interface{}
+	if err := json.Unmarshal(report, &doc); err != nil {
+		return nil, stats, err
+	}
+	files, _ := doc["files"].(map[string]interface{})
+	for path, f := range files {
+		file, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		mutants, _ := file["mutants"].([]interface{})
+		source, _ := file["source"].(string)
+		newSource, err := current.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			stats.Dropped += len(mutants)
+			delete(files, path)
+			continue
+		}
+		if err != nil {
+			return nil, stats, err
+		}
+		result, err := lhdiff.Compare(source, newSource, append([]lhdiff.Option{lhdiff.WithPath(path)}, opts...)...)
+		if err != nil {
+			return nil, stats, err
+		}
+		remapped := make([]interface{}, 0, len(mutants))
+		for _, m := range mutants {
+			mutant, ok := m.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			location, _ := mutant["location"].(map[string]interface{})
+			start, _ := location["start"].(map[string]interface{})
+			end, _ := location["end"].(map[string]interface{})
+			if start == nil || end == nil {
+				continue
+			}
+			unchanged, ok := remapPositions(result, start, end)
+			if !ok {
+				stats.Dropped++
+				continue
+			}
+			if unchanged {
+				stats.Remapped++
+			} else {
+				stats.Stale++
+				mutant["status"] = StaleStatus
+			}
+			remapped = append(remapped, mutant)
+		}
+		file["mutants"] = remapped
+		file["source"] = newSource
+	}
+	out, err := marshal(doc)
+	return out, stats, err
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(doc)
+	return buf.Bytes(), err
+}
+
+// remapPositions remaps the one-based "line" of the start and end positions of a location.
+// It returns whether all lines of the location are unchanged, and false if its first or last
+// line was deleted.
+func remapPositions(result *lhdiff.Result, start map[string]interface{}, end map[string]interface{}) (bool, bool) {
+	startLine, _ := start["line"].(float64)
+	endLine, _ := end["line"].(float64)
+	newStart, unchanged, ok := remapLine(result, int(startLine))
+	if !ok {
+		return false, false
+	}
+	newEnd, _, ok := remapLine(result, int(endLine))
+	if !ok || newEnd < newStart {
+		return false, false
+	}
+	unchanged = unchanged && newEnd-newStart == int(endLine-startLine)
+	for line := int(startLine) + 1; line <= int(endLine); line++ {
+		_, lineUnchanged, ok := remapLine(result, line)
+		unchanged = unchanged && lineUnchanged && ok
+	}
+	start["line"] = newStart
+	end["line"] = newEnd
+	return unchanged, true
+}
+
+// remapLine remaps a one-based line, returning whether the line is unchanged.
+func remapLine(result *lhdiff.Result, line int) (int, bool, bool) {
+	newLine, similarity, ok := result.RightLine(line - 1)
+	return newLine + 1, similarity == 1, ok
+}
@@ -0,0 +1,88 @@
+package mutation
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+)
+
+func ExampleRemapStryker() {
+	report := `{
+  "schemaVersion": "1",
+  "thresholds": {"high": 80, "low": 60},
+  "files": {
+    "src/add.js": {
+      "language": "javascript",
+      "source": "function add(a, b) {\n  return a + b;\n}\n\nfunction isPositive(n) {\n  return n > 0;\n}\n",
+      "mutants": [
+        {"id": "1", "mutatorName": "ArithmeticOperator", "status": "Killed", "location": {"start": {"line": 2, "column": 10}, "end": {"line": 2, "column": 15}}},
+        {"id": "2", "mutatorName": "EqualityOperator", "status": "Survived", "location": {"start": {"line": 6, "column": 10}, "end": {"line": 6, "column": 15}}}
+      ]
+    },
+    "src/gone.js": {
+      "language": "javascript",
+      "source": "x++;\n",
+      "mutants": [
+        {"id": "3", "mutatorName": "UpdateOperator", "status": "Killed", "location": {"start": {"line": 1, "column": 1}, "end": {"line": 1, "column": 4}}}
+      ]
+    }
+  }
+}`
+	current := lhdiff.MapSnapshot{
+		"src/add.js": "// Math helpers\n\nfunction add(a, b) {\n  return a + b;\n}\n\nfunction isPositive(n) {\n  return n >= 1;\n}\n",
+	}
+
+	remapped, stats, err := RemapStryker([]byte(report), current)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(stats)
+	fmt.Print(string(remapped))
+
+	// Output:
+	// 1 remapped, 1 stale, 1 dropped
+	// {
+	//   "files": {
+	//     "src/add.js": {
+	//       "language": "javascript",
+	//       "mutants": [
+	//         {
+	//           "id": "1",
+	//           "location": {
+	//             "end": {
+	//               "column": 15,
+	//               "line": 4
+	//             },
+	//             "start": {
+	//               "column": 10,
+	//               "line": 4
+	//             }
+	//           },
+	//           "mutatorName": "ArithmeticOperator",
+	//           "status": "Killed"
+	//         },
+	//         {
+	//           "id": "2",
+	//           "location": {
+	//             "end": {
+	//               "column": 15,
+	//               "line": 8
+	//             },
+	//             "start": {
+	//               "column": 10,
+	//               "line": 8
+	//             }
+	//           },
+	//           "mutatorName": "EqualityOperator",
+	//           "status": "Pending"
+	//         }
+	//       ],
+	//       "source": "// Math helpers\n\nfunction add(a, b) {\n  return a + b;\n}\n\nfunction isPositive(n) {\n  return n >= 1;\n}\n"
+	//     }
+	//   },
+	//   "schemaVersion": "1",
+	//   "thresholds": {
+	//     "high": 80,
+	//     "low": 60
+	//   }
+	// }
+}
@@ -0,0 +1,227 @@
+// Package notebook tracks lines between versions of a Jupyter notebook (.ipynb). Cells are
+// matched first, by content similarity, and lines are then tracked within matched cells, so
+// that the notebook's JSON isn't treated as flat text.
+package notebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CellSimilarityThreshold is the similarity two cells must exceed to be matched.
+const CellSimilarityThreshold = 0.5
+
+// Notebook is the part of a Jupyter notebook that lines are tracked in.
+type Notebook struct {
+	Cells []Cell
+}
+
+// Cell is a notebook cell. Source is the cell's source as a single string.
+type Cell struct {
+	Type   string
+	Source string
+}
+
+// Parse parses the JSON of a notebook. Cell sources can be either a string or a list of
+// strings, as allowed by the nbformat schema.
+func Parse(data []byte) (*Notebook, error) {
+	var doc struct {
+		Cells []struct {
+			CellType string          `json:"cell_type"`
+			Source   json.RawMessage `json:"source"`
+		} `json:"cells"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	notebook := &Notebook{}
+	for i, cell := range doc.Cells {
+		var source string
+		if len(cell.Source) > 0 && cell.Source[0] == '[' {
+			var lines []string
+			if err := json.Unmarshal(cell.Source, &lines); err != nil {
+				return nil, fmt.Errorf("cell %d: %w", i+1, err)
+			}
+			source = strings.Join(lines, "")
+		} else if len(cell.Source) > 0 {
+			if err := json.Unmarshal(cell.Source, &source); err != nil {
+				return nil, fmt.Errorf("cell %d: %w", i+1, err)
+			}
+		}
+		notebook.Cells = append(notebook.Cells, Cell{Type: cell.CellType, Source: source})
+	}
+	return notebook, nil
+}
+
+// CellMapping maps a cell in the left notebook to a cell in the right notebook. Cell numbers
+// are zero-based, and -1 means that the cell has no counterpart.
+type CellMapping struct {
+	Left       int
+	Right      int
+	Similarity float64
+}
+
+// LineMapping maps a line in a cell of the left notebook to a line in a cell of the right
+// notebook. Numbers are zero-based, and -1 means that the line has no counterpart.
+type LineMapping struct {
+	LeftCell   int
+	LeftLine   int
+	RightCell  int
+	RightLine  int
+	Similarity float64
+}
+
+// Result is the result of comparing two notebooks.
+type Result struct {
+	// Cells has a mapping for each left cell, in order, followed by the added right cells.
+	Cells []CellMapping
+	// Lines has the line mappings of each left cell, in order, followed by the lines of
+	// the right cells that were added.
+	Lines []LineMapping
+}
+
+// Compare matches the cells of two notebooks, and then the lines within matched cells.
+func Compare(left *Notebook, right *Notebook, opts ...lhdiff.Option) (*Result, error) {
+	cellMappings, err := matchCells(left.Cells, right.Cells, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Cells: cellMappings}
+	var added []LineMapping
+	for _, cellMapping := range cellMappings {
+		switch {
+		case cellMapping.Right == -1:
+			for line := range lhdiff.ConvertToLinesWithoutNewLine(left.Cells[cellMapping.Left].Source) {
+				result.Lines = append(result.Lines, LineMapping{LeftCell: cellMapping.Left, LeftLine: line, RightCell: -1, RightLine: -1})
+			}
+		case cellMapping.Left == -1:
+			for line := range lhdiff.ConvertToLinesWithoutNewLine(right.Cells[cellMapping.Right].Source) {
+				added = append(added, LineMapping{LeftCell: -1, LeftLine: -1, RightCell: cellMapping.Right, RightLine: line})
+			}
+		default:
+			lines, err := lhdiff.Compare(left.Cells[cellMapping.Left].Source, right.Cells[cellMapping.Right].Source, opts...)
+			if err != nil {
+				return nil, err
+			}
+			for _, mapping := range lines.Mappings {
+				lineMapping := LineMapping{LeftCell: cellMapping.Left, LeftLine: mapping.Left, RightCell: cellMapping.Right, RightLine: mapping.Right, Similarity: mapping.Similarity}
+				if mapping.Left == -1 {
+					lineMapping.LeftCell = -1
+					added = append(added, lineMapping)
+					continue
+				}
+				if mapping.Right == -1 {
+					lineMapping.RightCell = -1
+				}
+				result.Lines = append(result.Lines, lineMapping)
+			}
+		}
+	}
+	result.Lines = append(result.Lines, added...)
+	return result, nil
+}
+
+type cellPair struct {
+	left       int
+	right      int
+	similarity float64
+}
+
+// matchCells greedily matches the most similar cells of the same type first. Equally similar
+// pairs are matched in order of how close the cells are to each other.
+func matchCells(left []Cell, right []Cell, opts []lhdiff.Option) ([]CellMapping, error) {
+	var pairs []cellPair
+	for l, leftCell := range left {
+		for r, rightCell := range right {
+			if leftCell.Type != rightCell.Type {
+				continue
+			}
+			similarity, err := cellSimilarity(leftCell.Source, rightCell.Source, opts)
+			if err != nil {
+				return nil, err
+			}
+			if similarity > CellSimilarityThreshold {
+				pairs = append(pairs, cellPair{left: l, right: r, similarity: similarity})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].similarity != pairs[j].similarity {
+			return pairs[i].similarity > pairs[j].similarity
+		}
+		return abs(pairs[i].left-pairs[i].right) < abs(pairs[j].left-pairs[j].right)
+	})
+	leftMatches := make(map[int]cellPair)
+	rightMatched := make(map[int]bool)
+	for _, pair := range pairs {
+		if _, matched := leftMatches[pair.left]; matched || rightMatched[pair.right] {
+			continue
+		}
+		leftMatches[pair.left] = pair
+		rightMatched[pair.right] = true
+	}
+	mappings := make([]CellMapping, 0, len(left)+len(right))
+	for l := range left {
+		pair, matched := leftMatches[l]
+		if matched {
+			mappings = append(mappings, CellMapping{Left: l, Right: pair.right, Similarity: pair.similarity})
+		} else {
+			mappings = append(mappings, CellMapping{Left: l, Right: -1})
+		}
+	}
+	for r := range right {
+		if !rightMatched[r] {
+			mappings = append(mappings, CellMapping{Left: -1, Right: r})
+		}
+	}
+	return mappings, nil
+}
+
+// cellSimilarity is the Dice coefficient of the lines of two cells, weighted by the similarity
+// of the mapped lines.
+func cellSimilarity(left string, right string, opts []lhdiff.Option) (float64, error) {
+	if left == right {
+		return 1, nil
+	}
+	result, err := lhdiff.Compare(left, right, opts...)
+	if err != nil || result.LeftLineCount+result.RightLineCount == 0 {
+		return 0, err
+	}
+	total := 0.0
+	for _, mapping := range result.Mappings {
+		if mapping.Left != -1 && mapping.Right != -1 {
+			total += mapping.Similarity
+		}
+	}
+	return 2 * total / float64(result.LeftLineCount+result.RightLineCount), nil
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// PrintLineMappings prints line mappings as left,right where each side is a one-based
+// cell:line, or _ if the line has no counterpart.
+func PrintLineMappings(w io.Writer, mappings []LineMapping) error {
+	for _, mapping := range mappings {
+		_, err := fmt.Fprintf(w, "%s,%s\n", position(mapping.LeftCell, mapping.LeftLine), position(mapping.RightCell, mapping.RightLine))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func position(cell int, line int) string {
+	if cell == -1 {
+		return "_"
+	}
+	return fmt.Sprintf("%d:%d", cell+1, line+1)
+}
@@ -0,0 +1,59 @@
+package notebook
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleCompare() {
+	left, err := Parse([]byte(`{
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Analysis\n", "Load the data and plot it."]},
+    {"cell_type": "code", "source": ["import pandas as pd\n", "df = pd.read_csv('data.csv')\n", "df.head()"]},
+    {"cell_type": "code", "source": "df.plot()"}
+  ],
+  "nbformat": 4
+}`))
+	if err != nil {
+		panic(err)
+	}
+	right, err := Parse([]byte(`{
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Analysis\n", "Load the data and plot it."]},
+    {"cell_type": "code", "source": ["import matplotlib.pyplot as plt\n"]},
+    {"cell_type": "code", "source": ["import pandas as pd\n", "df = pd.read_csv('data.csv', sep=';')\n", "df.head()"]},
+    {"cell_type": "code", "source": "df.plot()\nplt.show()"}
+  ],
+  "nbformat": 4
+}`))
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	for _, cell := range result.Cells {
+		fmt.Printf("cell %d -> %d\n", cell.Left+1, cell.Right+1)
+	}
+	err = PrintLineMappings(os.Stdout, result.Lines)
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// cell 1 -> 1
+	// cell 2 -> 3
+	// cell 3 -> 4
+	// cell 0 -> 2
+	// 1:1,1:1
+	// 1:2,1:2
+	// 2:1,3:1
+	// 2:2,3:2
+	// 2:3,3:3
+	// 3:1,4:1
+	// _,4:2
+	// _,2:1
+	// _,2:2
+}
@@ -0,0 +1,310 @@
+// Package objectstore reads and writes the objects of s3:// and gs:// references, so that
+// pipelines can compare snapshots stored in Amazon S3 or Google Cloud Storage without copying
+// them first. It talks to the REST APIs of the stores directly:
+//
+//   - s3://bucket/key is signed with the credentials of AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+//     and AWS_SESSION_TOKEN, in the region of AWS_REGION or AWS_DEFAULT_REGION (us-east-1 by
+//     default). Public objects can be read without credentials.
+//   - gs://bucket/object is authorized with the OAuth2 access token of GOOGLE_OAUTH_ACCESS_TOKEN,
+//     or else of the default service account of the metadata server, when running on Google
+//     Cloud. Public objects can be read without either.
+//
+// AWS_ENDPOINT_URL and STORAGE_EMULATOR_HOST point the clients at compatible stores, such as
+// MinIO or an emulator, instead.
+package objectstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IsReference returns true if ref is an s3:// or gs:// reference rather than a local path.
+func IsReference(ref string) bool {
+	return strings.HasPrefix(ref, "s3://") || strings.HasPrefix(ref, "gs://")
+}
+
+// Client reads and writes objects. The zero Client uses the environment variables of the
+// package documentation and http.DefaultClient.
+type Client struct {
+	HTTPClient *http.Client
+	// S3Endpoint and GCSEndpoint override AWS_ENDPOINT_URL and STORAGE_EMULATOR_HOST. Objects
+	// are addressed by path (endpoint/bucket/key) on them.
+	S3Endpoint  string
+	GCSEndpoint string
+
+	// token is the access token of the metadata server, until expiry
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// DefaultClient is the Client of Get and Put.
+var DefaultClient = &Client{}
+
+// Get returns the contents of the object of ref with DefaultClient.
+func Get(ref string) ([]byte, error) {
+	return DefaultClient.Get(ref)
+}
+
+// Put writes data to the object of ref with DefaultClient.
+func Put(ref string, data []byte) error {
+	return DefaultClient.Put(ref, data)
+}
+
+// Get returns the contents of the object of ref. An error satisfying
+// errors.Is(err, os.ErrNotExist) means the object doesn't exist.
+func (client *Client) Get(ref string) ([]byte, error) {
+	response, err := client.do(http.MethodGet, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", ref, err)
+	}
+	return data, nil
+}
+
+// Put writes data to the object of ref, replacing it if it exists.
+func (client *Client) Put(ref string, data []byte) error {
+	response, err := client.do(http.MethodPut, ref, data)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// Snapshot is a lhdiff.Snapshot of the objects below a reference, such as
+// s3://bucket/snapshots/v1. Paths are keys relative to it.
+type Snapshot struct {
+	Client *Client
+	Prefix string
+}
+
+// ReadFile implements lhdiff.Snapshot.
+func (snapshot Snapshot) ReadFile(path string) (string, error) {
+	client := snapshot.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	data, err := client.Get(Join(snapshot.Prefix, path))
+	return string(data), err
+}
+
+// Join returns the reference of path below the reference prefix.
+func Join(prefix string, path string) string {
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func (client *Client) do(method string, ref string, body []byte) (*http.Response, error) {
+	request, err := client.newRequest(method, ref, body)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode/100 != 2 {
+		defer response.Body.Close()
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		if response.StatusCode == http.StatusNotFound {
+			return nil, &os.PathError{Op: strings.ToLower(method), Path: ref, Err: os.ErrNotExist}
+		}
+		return nil, fmt.Errorf("%s %s: %s: %s", strings.ToLower(method), ref, response.Status, bytes.TrimSpace(message))
+	}
+	return response, nil
+}
+
+func (client *Client) newRequest(method string, ref string, body []byte) (*http.Request, error) {
+	scheme, bucket, key, err := parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case "s3":
+		return client.newS3Request(method, bucket, key, body)
+	default:
+		return client.newGCSRequest(method, bucket, key, body)
+	}
+}
+
+// parse splits ref into its scheme, bucket and key.
+func parse(ref string) (string, string, string, error) {
+	parts := strings.SplitN(ref, "://", 2)
+	if len(parts) != 2 || (parts[0] != "s3" && parts[0] != "gs") {
+		return "", "", "", fmt.Errorf("not an s3:// or gs:// reference: %s", ref)
+	}
+	location := strings.SplitN(parts[1], "/", 2)
+	if len(location) != 2 || location[0] == "" || location[1] == "" {
+		return "", "", "", fmt.Errorf("%s: a reference is scheme://bucket/key", ref)
+	}
+	return parts[0], location[0], location[1], nil
+}
+
+func (client *Client) newS3Request(method string, bucket string, key string, body []byte) (*http.Request, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := client.S3Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	var target string
+	if endpoint != "" {
+		target = strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + escapePath(key)
+	} else {
+		target = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, escapePath(key))
+	}
+	request, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey != "" && secretKey != "" {
+		signV4(request, body, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), region, time.Now())
+	}
+	return request, nil
+}
+
+// signV4 signs an S3 request with AWS Signature Version 4.
+func signV4(request *http.Request, body []byte, accessKey string, secretKey string, sessionToken string, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if sessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath escapes the segments of a key, keeping its slashes, as S3 signatures require.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+func (client *Client) newGCSRequest(method string, bucket string, key string, body []byte) (*http.Request, error) {
+	endpoint := client.GCSEndpoint
+	if endpoint == "" {
+		if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+			endpoint = host
+			if !strings.Contains(endpoint, "://") {
+				endpoint = "http://" + endpoint
+			}
+		}
+	}
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	request, err := http.NewRequest(method, strings.TrimSuffix(endpoint, "/")+"/"+bucket+"/"+escapePath(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if token == "" && client.GCSEndpoint == "" && os.Getenv("STORAGE_EMULATOR_HOST") == "" {
+		token = client.metadataToken()
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request, nil
+}
+
+// metadataToken returns the access token of the default service account from the metadata
+// server, or "" if there is no metadata server, as outside of Google Cloud.
+func (client *Client) metadataToken() string {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	// Tokens are refreshed a minute before they expire, so that requests don't fail mid-flight
+	if client.token != "" && time.Now().Before(client.expiry.Add(-time.Minute)) {
+		return client.token
+	}
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	request, err := http.NewRequest(http.MethodGet, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return ""
+	}
+	request.Header.Set("Metadata-Flavor", "Google")
+	response, err := (&http.Client{Timeout: 2 * time.Second}).Do(request)
+	if err != nil {
+		return ""
+	}
+	defer response.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if response.StatusCode != http.StatusOK || json.NewDecoder(response.Body).Decode(&token) != nil {
+		return ""
+	}
+	client.token, client.expiry = token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn)*time.Second)
+	return client.token
+}
@@ -0,0 +1,267 @@
+package lhdiff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Option configures Compare and the functions built on top of it.
+type Option func(*options)
+
+// options that affect the result of Compare must be part of key.
+type options struct {
+	contextSize int
+	threshold   float64
+	contentGate float64
+	calibrate   bool
+	monotonic   bool
+	reference   bool
+	// ignoreAnchors is true if lhdiff:anchor directives aren't recognized
+	ignoreAnchors bool
+	// shortLineLength and longLineLength are 0 unless weights are adapted to line lengths
+	shortLineLength int
+	longLineLength  int
+	// maxLines is 0 unless the number of lines is limited
+	maxLines int
+	// maxComparisons and deadline are 0 unless the fuzzy comparisons are limited. They only
+	// affect results that are truncated, which aren't cached
+	maxComparisons int
+	deadline       time.Duration
+	// ctx is nil unless comparisons can be cancelled. It only affects results that are partial,
+	// which aren't cached either
+	ctx   context.Context
+	pins  []Pin
+	cache Cache
+	// diffEngine is Myers{} unless another engine is set
+	diffEngine DiffEngine
+	vectorizer *Vectorizer
+	// tokenizer is nil for WhitespaceTokenizer, which is the fastest
+	tokenizer Tokenizer
+	stopWords map[string]bool
+	// path and profiles choose options that are applied after the others, see WithProfiles
+	path     string
+	profiles map[string][]Option
+	// surroundings are the Surroundings of the left and right line, see WithSurroundings
+	surroundings []Surroundings
+	// debug, debugCandidates, diffOutput, visitor and redact don't affect the result
+	debug           io.Writer
+	debugCandidates int
+	diffOutput      io.Writer
+	visitor         Visitor
+	// redact is nil unless contents are redacted, see WithRedaction
+	redact func(string) string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		contextSize: 4,
+		threshold:   SimilarityThreshold,
+		contentGate: ContentSimilarityGate,
+		diffEngine:  Myers{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	for _, opt := range o.profiles[strings.ToLower(filepath.Ext(o.path))] {
+		opt(o)
+	}
+	return o
+}
+
+// WithContextSize sets the number of context lines above and below each line. The default is 4.
+// With 0 (or less), lines are paired by the similarity of their content alone.
+func WithContextSize(contextSize int) Option {
+	return func(o *options) {
+		o.contextSize = contextSize
+		if contextSize < 0 {
+			o.contextSize = 0
+		}
+	}
+}
+
+// WithThreshold sets the similarity that two changed lines must exceed to be paired. The default
+// is SimilarityThreshold.
+func WithThreshold(threshold float64) Option {
+	return func(o *options) {
+		o.threshold = threshold
+	}
+}
+
+// WithContentGate sets the content similarity that two lines must exceed to be compared by their
+// context at all. The default is ContentSimilarityGate, so that unrelated lines in similar
+// contexts aren't paired. A lower gate lets the context pair lines that were heavily rewritten,
+// and a negative one disables the gate.
+func WithContentGate(gate float64) Option {
+	return func(o *options) {
+		o.contentGate = gate
+	}
+}
+
+// WithCalibratedThreshold makes Compare derive the similarity threshold from how similar the
+// unrelated lines of the compared files are, instead of using SimilarityThreshold. The threshold
+// is lowered for terse files such as configuration files, and raised for verbose ones.
+func WithCalibratedThreshold() Option {
+	return func(o *options) {
+		o.calibrate = true
+	}
+}
+
+// WithAdaptiveWeighting adapts the weights of the content and context similarities to the
+// length of the lines, since short lines carry little content signal. Lines up to shortLength
+// characters weigh their content by 0.4 and their context by 0.6, lines of at least longLength
+// characters weigh their content by 0.8 and their context by 0.2, and the weights of the lines in
+// between are interpolated. The default is to weigh the content by 0.6 and the context by 0.4.
+func WithAdaptiveWeighting(shortLength int, longLength int) Option {
+	return func(o *options) {
+		o.shortLineLength = shortLength
+		o.longLineLength = longLength
+	}
+}
+
+// WithMonotonic makes Compare return an order-preserving alignment, where no two pairs of lines
+// cross. The longest sequence of pairs that don't cross is kept, and the other pairs are moved to
+// Result.Moved, their left lines becoming deleted and their right lines added.
+func WithMonotonic() Option {
+	return func(o *options) {
+		o.monotonic = true
+	}
+}
+
+// WithMaxLines makes Compare return a *LimitError instead of comparing files with more than
+// maxLines lines, to bound the time and memory spent on a comparison.
+func WithMaxLines(maxLines int) Option {
+	return func(o *options) {
+		o.maxLines = maxLines
+	}
+}
+
+// WithMaxComparisons limits the number of pairs of changed lines whose similarity Compare
+// computes to n. Unchanged lines, pins and lines that are equal are paired whatever the budget,
+// and the added lines that are past it are left unpaired, with the result marked as Truncated.
+func WithMaxComparisons(n int) Option {
+	return func(o *options) {
+		o.maxComparisons = n
+	}
+}
+
+// WithDeadline is like WithMaxComparisons, for the time spent on a comparison: the added lines
+// that haven't been compared d after Compare started are left unpaired.
+func WithDeadline(d time.Duration) Option {
+	return func(o *options) {
+		o.deadline = d
+	}
+}
+
+// WithContext stops pairing the changed lines by similarity when ctx is cancelled or its
+// deadline passes, and returns the pairs made so far in a result marked as Partial, instead of
+// an error: the unchanged lines, the anchors, the pins, the lines that are equal and the added
+// lines that were compared before. The other changed lines are left unpaired. Compare returns
+// the error of ctx if it is done before the comparison starts. Like WithDeadline, it doesn't
+// apply to the pairs made in reference mode.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// cancelled returns true if the context of WithContext is done.
+func (o *options) cancelled() bool {
+	return o.ctx != nil && o.ctx.Err() != nil
+}
+
+// WithPins pairs the lines of each pin before comparing the other lines, for example to apply
+// corrections made by a reviewer or mappings known from a previous comparison. Pinned lines
+// aren't paired with any other line, and the lines that the diff would have paired with them
+// are paired by similarity instead. Compare returns an error if a line is pinned twice or is
+// out of range.
+func WithPins(pins []Pin) Option {
+	return func(o *options) {
+		o.pins = pins
+	}
+}
+
+// WithDiffEngine makes Compare find the unchanged lines with engine instead of Myers{}.
+func WithDiffEngine(engine DiffEngine) Option {
+	return func(o *options) {
+		o.diffEngine = engine
+	}
+}
+
+// WithVectorizer makes Compare weigh the terms of the contexts of lines with the statistics that
+// vectorizer was fitted on, instead of with those of the two contexts being compared. The vector
+// of each context is computed once, rather than for every pair of lines it is part of.
+func WithVectorizer(vectorizer *Vectorizer) Option {
+	return func(o *options) {
+		o.vectorizer = vectorizer
+	}
+}
+
+// WithProfiles applies different options to files of different types. Profiles are keyed by
+// lower-case file extension including the dot, such as ".json", and the options of the profile of
+// the compared file (see WithPath) are applied after the other options, so that they take
+// precedence. Profiles can't contain other profiles.
+func WithProfiles(profiles map[string][]Option) Option {
+	return func(o *options) {
+		o.profiles = profiles
+	}
+}
+
+// WithPath tells Compare the path of the compared file, which chooses its profile with
+// WithProfiles. Functions comparing the files of snapshots or repositories pass it themselves.
+func WithPath(path string) Option {
+	return func(o *options) {
+		o.path = path
+	}
+}
+
+// WithCache makes Compare look up results in cache before comparing, and store the results it
+// computes in it.
+func WithCache(cache Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithDebug makes Compare write to w, for each added right line, its most similar candidates
+// (at most candidates of them) with their similarity, content similarity and context similarity,
+// and whether the line was paired. Lines that were paired with a deleted line of the same
+// content aren't compared, so they aren't written. Line numbers are one-based. The cache isn't
+// looked up, so that the comparison is always made.
+func WithDebug(w io.Writer, candidates int) Option {
+	return func(o *options) {
+		o.debug = w
+		o.debugCandidates = candidates
+	}
+}
+
+// WithDiffOutput makes Compare write the unified diff that it pairs lines from to w, for example
+// to see why lines weren't paired when the result looks surprising. The lines of the diff are
+// normalized, and nothing is written when the files are identical. The cache isn't looked up, so
+// that the comparison is always made.
+func WithDiffOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.diffOutput = w
+	}
+}
+
+// key returns a string identifying the options that affect the result of Compare.
+func (o *options) key() string {
+	return fmt.Sprintf("contextSize=%d threshold=%g contentGate=%g calibrate=%t monotonic=%t reference=%t ignoreAnchors=%t shortLineLength=%d longLineLength=%d pins=%v diffEngine=%#v tokenizer=%#v stopWords=%v vectorizer=%s", o.contextSize, o.threshold, o.contentGate, o.calibrate, o.monotonic, o.reference, o.ignoreAnchors, o.shortLineLength, o.longLineLength, o.pins, o.diffEngine, o.tokenizer, o.stopWords, o.vectorizer.key())
+}
+
+// cached returns the result cached under key, unless the comparison must be made anyway to
+// write what WithDebug, WithDiffOutput or WithVisitor ask for, or the cached result is corrupted.
+func (o *options) cached(key string) (*Result, bool) {
+	if o.debug != nil || o.diffOutput != nil || o.visitor != nil {
+		return nil, false
+	}
+	result, ok := o.cache.Get(key)
+	if !ok || !result.consistent() {
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,37 @@
+package lhdiff
+
+import (
+	"sort"
+)
+
+// Order is an order of mappings, for SortedMappings.
+type Order int
+
+const (
+	// LeftOrder is the order of Result.Mappings: left line order, followed by the added lines.
+	LeftOrder Order = iota
+	// RightOrder is right line order, followed by the deleted lines in left line order.
+	RightOrder
+	// SimilarityOrder is ascending similarity, so the least certain mappings come first. Deleted
+	// and added lines have a similarity of 0. Mappings with the same similarity are in left order.
+	SimilarityOrder
+)
+
+// SortedMappings returns a copy of the mappings of result in the given order.
+func (result *Result) SortedMappings(order Order) []LineMapping {
+	mappings := append([]LineMapping(nil), result.Mappings...)
+	switch order {
+	case RightOrder:
+		sort.SliceStable(mappings, func(i, j int) bool {
+			if (mappings[i].Right == -1) != (mappings[j].Right == -1) {
+				return mappings[j].Right == -1
+			}
+			return mappings[i].Right < mappings[j].Right
+		})
+	case SimilarityOrder:
+		sort.SliceStable(mappings, func(i, j int) bool {
+			return mappings[i].Similarity < mappings[j].Similarity
+		})
+	}
+	return mappings
+}
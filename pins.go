@@ -0,0 +1,74 @@
+package lhdiff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pin is a pair of zero-based lines that are known to correspond, for WithPins.
+type Pin struct {
+	Left  int
+	Right int
+}
+
+func checkPins(pins []Pin, leftLineCount int, rightLineCount int) error {
+	lefts := make(map[int]bool)
+	rights := make(map[int]bool)
+	for _, pin := range pins {
+		if pin.Left < 0 || pin.Left >= leftLineCount || pin.Right < 0 || pin.Right >= rightLineCount {
+			return fmt.Errorf("pin %d,%d is out of range: the files have %d and %d lines", pin.Left+1, pin.Right+1, leftLineCount, rightLineCount)
+		}
+		if lefts[pin.Left] || rights[pin.Right] {
+			return fmt.Errorf("pin %d,%d: a line can only be pinned once", pin.Left+1, pin.Right+1)
+		}
+		lefts[pin.Left], rights[pin.Right] = true, true
+	}
+	return nil
+}
+
+// applyPins takes the pinned lines out of the runs of unchanged lines and of the deleted and added
+// lines, so that they aren't paired with other lines. The lines that were unchanged but whose
+// counterpart is pinned to another line are deleted or added instead, so that they can be paired
+// by similarity.
+func applyPins(pins []Pin, runs []Run, deleted []int, added []int) ([]Run, []int, []int) {
+	pinnedLefts := make(map[int]bool)
+	pinnedRights := make(map[int]bool)
+	for _, pin := range pins {
+		pinnedLefts[pin.Left], pinnedRights[pin.Right] = true, true
+	}
+	var kept []Run
+	for _, run := range runs {
+		start := 0
+		for i := 0; i <= run.Length; i++ {
+			if i < run.Length && !pinnedLefts[run.Left+i] && !pinnedRights[run.Right+i] {
+				continue
+			}
+			if i > start {
+				kept = append(kept, Run{Left: run.Left + start, Right: run.Right + start, Length: i - start})
+			}
+			start = i + 1
+			if i == run.Length {
+				break
+			}
+			left, right := run.Left+i, run.Right+i
+			if !pinnedLefts[left] {
+				deleted = append(deleted, left)
+			}
+			if !pinnedRights[right] {
+				added = append(added, right)
+			}
+		}
+	}
+	return kept, unpinned(deleted, pinnedLefts), unpinned(added, pinnedRights)
+}
+
+func unpinned(lineNumbers []int, pinned map[int]bool) []int {
+	var kept []int
+	for _, lineNumber := range lineNumbers {
+		if !pinned[lineNumber] {
+			kept = append(kept, lineNumber)
+		}
+	}
+	sort.Ints(kept)
+	return kept
+}
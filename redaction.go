@@ -0,0 +1,66 @@
+package lhdiff
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Redact returns a salted hash of text that stands for it in outputs that are shared, so that
+// the same text has the same hash, but the text can't be guessed without the salt. Empty text
+// stays empty, so that blank lines can be told apart.
+func Redact(salt string, text string) string {
+	if text == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	_, _ = mac.Write([]byte(text))
+	return "#" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// WithRedaction replaces the contents and contexts of lines written by WithDebug and
+// WithDiffOutput, and those of an Explanation, with Redact(salt, ...), keeping line numbers and
+// similarities, so that the outputs of comparisons of proprietary files can be shared. Results
+// don't have contents, and aren't affected. The functions of the repo package given this option
+// redact the contents of lines they return, such as those of timelines, followed ranges and
+// markers, and others can use Redaction to do the same.
+func WithRedaction(salt string) Option {
+	return func(o *options) {
+		o.redact = func(text string) string {
+			return Redact(salt, text)
+		}
+	}
+}
+
+// Redaction returns the function replacing the contents of lines in the outputs of comparisons
+// made with opts, for other outputs to be redacted alike: Redact with the salt of WithRedaction,
+// keeping a trailing newline, or a function returning the contents as they are.
+func Redaction(opts ...Option) func(string) string {
+	return newOptions(opts).redaction()
+}
+
+func (o *options) redaction() func(string) string {
+	if o.redact == nil {
+		return func(text string) string {
+			return text
+		}
+	}
+	return func(text string) string {
+		content := strings.TrimSuffix(text, "\n")
+		return o.redact(content) + text[len(content):]
+	}
+}
+
+// redactLines returns lines with their contents redacted, if WithRedaction is set.
+func (o *options) redactLines(lines []string) []string {
+	if o.redact == nil {
+		return lines
+	}
+	redact := o.redaction()
+	redacted := make([]string, len(lines))
+	for i, line := range lines {
+		redacted[i] = redact(line)
+	}
+	return redacted
+}
@@ -0,0 +1,160 @@
+package lhdiff
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// ReferenceCandidates is the number of candidates that each deleted line is compared with in
+// reference mode, see WithReferenceMode.
+const ReferenceCandidates = 15
+
+// WithReferenceMode pairs changed lines as described in the LHDiff paper (Asaduzzaman et al.,
+// ICSM 2013) instead of the way this implementation does by default, so that its results can be
+// compared with the numbers of studies using the original tool:
+//
+//   - lines that are equal aren't paired before the others, and there is no content gate
+//   - the candidates of each deleted line are the ReferenceCandidates added lines whose simhashes
+//     of content and context are the closest, in Hamming distance
+//   - contexts are compared with the cosine similarity of their term frequencies, without IDF
+//   - each deleted line is paired with its most similar candidate, and an added line that is the
+//     most similar candidate of several deleted lines goes to the most similar of them
+//   - ties go to the candidate that is closest in simhash, then to the earliest line
+//
+// Line splits, which the paper detects by concatenating added lines, aren't detected. The
+// scoring options (WithAdaptiveWeighting, WithContentGate, WithCalibratedThreshold,
+// WithVectorizer, WithTokenizer and WithStopWords) and WithMaxComparisons, WithDeadline and
+// WithDebug don't apply to the pairs made in reference mode.
+func WithReferenceMode() Option {
+	return func(o *options) {
+		o.reference = true
+	}
+}
+
+type referenceLine struct {
+	info           *LineInfo
+	contentHash    uint64
+	contextHash    uint64
+	contextVector  map[string]float64
+	contextLength2 float64
+}
+
+func newReferenceLine(info *LineInfo) *referenceLine {
+	line := &referenceLine{
+		info:          info,
+		contentHash:   simhash(strings.Fields(info.content)),
+		contextHash:   simhash(strings.Fields(info.context)),
+		contextVector: make(map[string]float64),
+	}
+	for _, term := range strings.Fields(info.context) {
+		line.contextVector[term]++
+	}
+	for _, frequency := range line.contextVector {
+		line.contextLength2 += frequency * frequency
+	}
+	return line
+}
+
+// distance is the Hamming distance of the simhashes of two lines.
+func (line *referenceLine) distance(other *referenceLine) int {
+	return bits.OnesCount64(line.contentHash^other.contentHash) + bits.OnesCount64(line.contextHash^other.contextHash)
+}
+
+// contextSimilarity is the cosine similarity of the term frequencies of the contexts.
+func (line *referenceLine) contextSimilarity(other *referenceLine) float64 {
+	if line.contextLength2 == 0 || other.contextLength2 == 0 {
+		if line.contextLength2 == other.contextLength2 {
+			return 1
+		}
+		return 0
+	}
+	product := 0.0
+	for term, frequency := range line.contextVector {
+		product += frequency * other.contextVector[term]
+	}
+	return product / math.Sqrt(line.contextLength2*other.contextLength2)
+}
+
+// simhash is the 64-bit simhash of terms, each weighing 1.
+func simhash(terms []string) uint64 {
+	var weights [64]int
+	for _, term := range terms {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(term))
+		sum := hash.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	return fingerprint
+}
+
+// pairReference pairs the deleted lines with the added lines in reference mode, and returns
+// the right line numbers that were paired.
+func (pairs *pairing) pairReference(leftLineInfos []*LineInfo, rightLineInfos []*LineInfo) map[int]bool {
+	lefts := make([]*referenceLine, len(leftLineInfos))
+	for i, info := range leftLineInfos {
+		lefts[i] = newReferenceLine(info)
+	}
+	rights := make([]*referenceLine, len(rightLineInfos))
+	for i, info := range rightLineInfos {
+		rights[i] = newReferenceLine(info)
+	}
+	type choice struct {
+		left       *referenceLine
+		right      *referenceLine
+		similarity float64
+	}
+	// chosen[right] is the most similar deleted line that chose the added line right
+	chosen := make(map[int]choice)
+	for _, left := range lefts {
+		distances := make(map[*referenceLine]int, len(rights))
+		for _, right := range rights {
+			distances[right] = left.distance(right)
+		}
+		candidates := append([]*referenceLine(nil), rights...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return distances[candidates[i]] < distances[candidates[j]]
+		})
+		if len(candidates) > ReferenceCandidates {
+			candidates = candidates[:ReferenceCandidates]
+		}
+		var best *referenceLine
+		bestSimilarity := 0.0
+		for _, right := range candidates {
+			pair := LinePair{left: left.info, right: right.info}
+			similarity := ContentSimilarityFactor*pair.contentNormalizedLevenshteinSimilarity() + ContextSimilarityFactor*left.contextSimilarity(right)
+			// Candidates are in simhash order, so the closest one wins ties
+			if best == nil || similarity > bestSimilarity {
+				best, bestSimilarity = right, similarity
+			}
+		}
+		if best == nil || bestSimilarity <= pairs.threshold {
+			continue
+		}
+		if previous, exists := chosen[best.info.lineNumber]; exists && previous.similarity >= bestSimilarity {
+			continue
+		}
+		chosen[best.info.lineNumber] = choice{left: left, right: best, similarity: bestSimilarity}
+	}
+	mappedRightLines := make(map[int]bool)
+	for rightLineNumber, c := range chosen {
+		pairs.similar[c.left.info.lineNumber] = LinePair{left: c.left.info, right: c.right.info}
+		pairs.similarities[c.left.info.lineNumber] = c.similarity
+		mappedRightLines[rightLineNumber] = true
+	}
+	return mappedRightLines
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"encoding/json"
+	"github.com/SmartBear/lhdiff"
+	bolt "go.etcd.io/bbolt"
+	"sync"
+	"time"
+)
+
+var /* const */ resultsBucket = []byte("results")
+
+// CheckpointInterval is how often a Checkpoint persists the comparisons made since it last did.
+const CheckpointInterval = 10 * time.Second
+
+// Checkpoint is a lhdiff.Cache persisting the comparisons of a long analysis of the history of
+// a repository, such as HotSpots or TrackMarkers, in a database file. Passed to the analysis
+// with lhdiff.WithCache, an interrupted run that is started again with the same checkpoint
+// reads back the comparisons that were already made instead of making them again, and resumes
+// where it left off. Comparisons are written in batches, every CheckpointInterval and by Close,
+// so that at most the last batch is lost when the process is killed.
+//
+// Results are keyed like those of any lhdiff.Cache, by the hashes of the files and of the
+// options, so a checkpoint can be reused with another range or other options.
+type Checkpoint struct {
+	db      *bolt.DB
+	mutex   sync.Mutex
+	pending map[string][]byte
+	written time.Time
+}
+
+// OpenCheckpoint opens (or creates) the checkpoint database at path.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(resultsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Checkpoint{db: db, pending: make(map[string][]byte), written: time.Now()}, nil
+}
+
+// Len returns the number of comparisons in the checkpoint.
+func (checkpoint *Checkpoint) Len() int {
+	checkpoint.mutex.Lock()
+	defer checkpoint.mutex.Unlock()
+	n := len(checkpoint.pending)
+	_ = checkpoint.db.View(func(tx *bolt.Tx) error {
+		n += tx.Bucket(resultsBucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// Get implements lhdiff.Cache.
+func (checkpoint *Checkpoint) Get(key string) (*lhdiff.Result, bool) {
+	checkpoint.mutex.Lock()
+	data, ok := checkpoint.pending[key]
+	checkpoint.mutex.Unlock()
+	if !ok {
+		_ = checkpoint.db.View(func(tx *bolt.Tx) error {
+			// The data of a read-only transaction is only valid until it ends
+			data = append([]byte(nil), tx.Bucket(resultsBucket).Get([]byte(key))...)
+			return nil
+		})
+	}
+	if len(data) == 0 {
+		return nil, false
+	}
+	result := &lhdiff.Result{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// Put implements lhdiff.Cache, writing the pending comparisons if the last batch was written
+// more than CheckpointInterval ago.
+func (checkpoint *Checkpoint) Put(key string, result *lhdiff.Result) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	checkpoint.mutex.Lock()
+	defer checkpoint.mutex.Unlock()
+	checkpoint.pending[key] = data
+	if time.Since(checkpoint.written) >= CheckpointInterval {
+		_ = checkpoint.flush()
+	}
+}
+
+// Flush writes the pending comparisons.
+func (checkpoint *Checkpoint) Flush() error {
+	checkpoint.mutex.Lock()
+	defer checkpoint.mutex.Unlock()
+	return checkpoint.flush()
+}
+
+func (checkpoint *Checkpoint) flush() error {
+	checkpoint.written = time.Now()
+	if len(checkpoint.pending) == 0 {
+		return nil
+	}
+	err := checkpoint.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(resultsBucket)
+		for key, data := range checkpoint.pending {
+			if err := bucket.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	checkpoint.pending = make(map[string][]byte)
+	return nil
+}
+
+// Close writes the pending comparisons and closes the database.
+func (checkpoint *Checkpoint) Close() error {
+	err := checkpoint.Flush()
+	if closeErr := checkpoint.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
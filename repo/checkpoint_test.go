@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"path/filepath"
+)
+
+func ExampleCheckpoint() {
+	repository, _ := newTestRepository(
+		map[string]string{"main.go": "package main\n\nfunc main() {\n\trun(1)\n}\n"},
+		map[string]string{"main.go": "package main\n\nfunc main() {\n\trun(2)\n}\n"},
+		map[string]string{"main.go": "package main\n\nfunc main() {\n\tsetup()\n\trun(3)\n}\n"},
+	)
+	defer os.RemoveAll(repository.Dir)
+	path := filepath.Join(repository.Dir, "checkpoint.db")
+
+	checkpoint, err := OpenCheckpoint(path)
+	check(err)
+	hotSpots, err := repository.HotSpots("", "HEAD", lhdiff.WithCache(checkpoint))
+	check(err)
+	check(checkpoint.Close())
+	fmt.Printf("%d rewrite(s) of %s\n", hotSpots.Files[0].Rewrites, hotSpots.Files[0].Path)
+
+	// The run is made again from the checkpoint
+	checkpoint, err = OpenCheckpoint(path)
+	check(err)
+	defer checkpoint.Close()
+	fmt.Printf("%d comparison(s) in the checkpoint\n", checkpoint.Len())
+	hotSpots, err = repository.HotSpots("", "HEAD", lhdiff.WithCache(checkpoint))
+	check(err)
+	fmt.Printf("%d rewrite(s) of %s\n", hotSpots.Files[0].Rewrites, hotSpots.Files[0].Path)
+
+	// Output:
+	// 2 rewrite(s) of main.go
+	// 2 comparison(s) in the checkpoint
+	// 2 rewrite(s) of main.go
+}
@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"strings"
+)
+
+// RangeChange is a commit that changed a range of lines followed with FollowRange. Line
+// numbers are one-based and inclusive. OldPath is empty if the commit introduced the range.
+type RangeChange struct {
+	Commit   Commit
+	OldPath  string
+	OldStart int
+	OldEnd   int
+	NewPath  string
+	NewStart int
+	NewEnd   int
+	// Lines are the lines of the old and new ranges, in new line order, with the deleted
+	// lines before the line that followed them.
+	Lines []RangeLine
+}
+
+// RangeLine is a line of a RangeChange. Old is 0 for added lines and New is 0 for deleted ones.
+type RangeLine struct {
+	Old        int
+	New        int
+	OldText    string
+	NewText    string
+	Similarity float64
+}
+
+// FollowRange follows the lines from start to end (one-based, inclusive) of path at rev back
+// through the first-parent history, like git log -L, and returns the commits that changed
+// them, newest first. Lines are tracked with lhdiff, so the range is followed through moves,
+// renames and rewrites. The last change is the commit that introduced the range, unless the
+// history stops first. With lhdiff.WithRedaction, the texts of the lines are redacted.
+func (repository *Repository) FollowRange(rev string, path string, start int, end int, opts ...lhdiff.Option) ([]RangeChange, error) {
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("invalid range: %d,%d", start, end)
+	}
+	commits, err := repository.Commits("", rev)
+	if err != nil {
+		return nil, err
+	}
+	redact := lhdiff.Redaction(opts...)
+	var changes []RangeChange
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		parent := commit.Parent
+		if parent == "" {
+			parent = emptyTree
+		}
+		diff, err := repository.Diff(parent, commit.SHA)
+		if err != nil {
+			return nil, err
+		}
+		var fileChange *Change
+		for j := range diff {
+			if diff[j].NewPath == path {
+				fileChange = &diff[j]
+			}
+		}
+		if fileChange == nil {
+			continue
+		}
+		newContent, err := repository.Show(commit.SHA, path)
+		if err != nil {
+			return nil, err
+		}
+		oldContent := ""
+		if fileChange.OldPath != "" {
+			oldContent, err = repository.Show(parent, fileChange.OldPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result, err := lhdiff.Compare(oldContent, newContent, append([]lhdiff.Option{lhdiff.WithPath(path)}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		change, changed := rangeChange(commit, fileChange.OldPath, path, start, end, oldContent, newContent, result)
+		if changed {
+			for j := range change.Lines {
+				change.Lines[j].OldText, change.Lines[j].NewText = redact(change.Lines[j].OldText), redact(change.Lines[j].NewText)
+			}
+			changes = append(changes, change)
+		}
+		if change.OldPath == "" {
+			return changes, nil
+		}
+		path, start, end = change.OldPath, change.OldStart, change.OldEnd
+	}
+	return changes, nil
+}
+
+// rangeChange maps the new range back to the old one. The boolean is false if the lines of
+// the range were only moved.
+func rangeChange(commit Commit, oldPath string, newPath string, start int, end int, oldContent string, newContent string, result *lhdiff.Result) (RangeChange, bool) {
+	change := RangeChange{Commit: commit, NewPath: newPath, NewStart: start, NewEnd: end}
+	oldLines := strings.Split(oldContent, "\n")
+	newLines := strings.Split(newContent, "\n")
+	if end > len(newLines) {
+		end = len(newLines)
+	}
+	oldStart, oldEnd := 0, 0
+	for line := start; line <= end; line++ {
+		oldLine, _, ok := result.LeftLine(line - 1)
+		if !ok {
+			continue
+		}
+		if oldStart == 0 || oldLine+1 < oldStart {
+			oldStart = oldLine + 1
+		}
+		if oldLine+1 > oldEnd {
+			oldEnd = oldLine + 1
+		}
+	}
+	if oldPath != "" && oldStart != 0 {
+		change.OldPath, change.OldStart, change.OldEnd = oldPath, oldStart, oldEnd
+	}
+	changed := change.OldPath == ""
+	// Old lines that are in the old range but not in the new one were deleted
+	emitted := make(map[int]bool)
+	emitDeleted := func(before int) {
+		for old := oldStart; old != 0 && old <= before; old++ {
+			if newLine, _, ok := result.RightLine(old - 1); !emitted[old] && (!ok || newLine+1 < start || newLine+1 > end) {
+				change.Lines = append(change.Lines, RangeLine{Old: old, OldText: oldLines[old-1]})
+				emitted[old] = true
+				changed = true
+			}
+		}
+	}
+	for line := start; line <= end; line++ {
+		rangeLine := RangeLine{New: line, NewText: newLines[line-1]}
+		if oldLine, similarity, ok := result.LeftLine(line - 1); ok && change.OldPath != "" {
+			emitDeleted(oldLine)
+			rangeLine.Old, rangeLine.OldText, rangeLine.Similarity = oldLine+1, oldLines[oldLine], similarity
+			emitted[oldLine+1] = true
+		}
+		if rangeLine.Old == 0 || rangeLine.OldText != rangeLine.NewText {
+			changed = true
+		}
+		change.Lines = append(change.Lines, rangeLine)
+	}
+	emitDeleted(oldEnd)
+	return change, changed
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRepository_FollowRange() {
+	repository, _ := newTestRepository(
+		map[string]string{"main.go": `package main
+
+func main() {
+	run("server")
+}
+`},
+		map[string]string{"main.go": `package main
+
+import "os"
+
+func main() {
+	run("server")
+	os.Exit(0)
+}
+`},
+		map[string]string{"app.go": `package main
+
+import "os"
+
+// main starts the servers
+func main() {
+	run("servers")
+	os.Exit(0)
+}
+`, "main.go": ""},
+		map[string]string{"app.go": `package main
+
+import "os"
+
+// main starts the servers
+func main() {
+	run("servers")
+	os.Exit(0)
+}
+
+func run(name string) {
+}
+`},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	changes, err := repository.FollowRange("HEAD", "app.go", 5, 9)
+	check(err)
+	for _, change := range changes {
+		fmt.Printf("%s: %s:%d-%d <- %s:%d-%d\n", change.Commit.Subject, change.NewPath, change.NewStart, change.NewEnd, change.OldPath, change.OldStart, change.OldEnd)
+		for _, line := range change.Lines {
+			fmt.Printf("  %d,%d %q %q\n", line.Old, line.New, line.OldText, line.NewText)
+		}
+	}
+
+	// Output:
+	// revision 2: app.go:5-9 <- main.go:5-8
+	//   0,5 "" "// main starts the servers"
+	//   5,6 "func main() {" "func main() {"
+	//   6,7 "\trun(\"server\")" "\trun(\"servers\")"
+	//   7,8 "\tos.Exit(0)" "\tos.Exit(0)"
+	//   8,9 "}" "}"
+	// revision 1: main.go:5-8 <- main.go:3-5
+	//   3,5 "func main() {" "func main() {"
+	//   4,6 "\trun(\"server\")" "\trun(\"server\")"
+	//   0,7 "" "\tos.Exit(0)"
+	//   5,8 "}" "}"
+	// revision 0: main.go:3-5 <- :0-0
+	//   0,3 "" "func main() {"
+	//   0,4 "" "\trun(\"server\")"
+	//   0,5 "" "}"
+}
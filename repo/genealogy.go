@@ -0,0 +1,391 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/avro"
+	bolt "go.etcd.io/bbolt"
+	"io"
+	"sort"
+	"time"
+)
+
+var /* const */ commitsBucket = []byte("commits")
+
+// The settings bucket has the key of the options that the commits were indexed with
+var /* const */ settingsBucket = []byte("settings")
+var /* const */ optionsKey = []byte("options")
+
+// ErrMappingStale is returned by the queries of a Genealogy for commits that it hasn't indexed
+// yet, so that callers can Update it and try again.
+var ErrMappingStale = errors.New("the genealogy database is stale")
+
+// ErrOptionsMismatch is returned by OpenGenealogy for a database that was indexed with other
+// options, or by another version of lhdiff, whose mappings would be mixed with those of opts.
+var ErrOptionsMismatch = errors.New("the genealogy database was indexed with other options")
+
+// Genealogy is a database of the line mappings of each commit along the first-parent
+// history of a repository. It is built incrementally with Update, and answers queries about
+// where lines came from and went to without comparing any files.
+type Genealogy struct {
+	repository *Repository
+	db         *bolt.DB
+	opts       []lhdiff.Option
+}
+
+// commitRecord is what is stored for each commit, keyed by its SHA.
+type commitRecord struct {
+	Commit  Commit         `json:"commit"`
+	Changes []changeRecord `json:"changes,omitempty"`
+}
+
+// changeRecord is a changed file. Runs map the old lines to the new ones; lines that aren't in
+// any run were deleted or added.
+type changeRecord struct {
+	Status  string `json:"status"`
+	OldPath string `json:"old,omitempty"`
+	NewPath string `json:"new,omitempty"`
+	Runs    []run  `json:"runs,omitempty"`
+}
+
+// run maps Length consecutive zero-based old lines from Left to new lines from Right, with the
+// same similarity.
+type run struct {
+	Left       int     `json:"l"`
+	Right      int     `json:"r"`
+	Length     int     `json:"n"`
+	Similarity float64 `json:"s"`
+}
+
+// OpenGenealogy opens (or creates) the genealogy database of the repository at path. Commits
+// are compared with opts when they are indexed, and read with the TextConv and Filters of the
+// repository. A database that was indexed with other options or settings can't be opened:
+// the error satisfies errors.Is(err, ErrOptionsMismatch).
+func (repository *Repository) OpenGenealogy(path string, opts ...lhdiff.Option) (*Genealogy, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	key := []byte(fmt.Sprintf("%s textconv=%t filters=%t", lhdiff.OptionsKey(opts...), repository.TextConv, repository.Filters))
+	err = db.Update(func(tx *bolt.Tx) error {
+		commits, err := tx.CreateBucketIfNotExists(commitsBucket)
+		if err != nil {
+			return err
+		}
+		settings, err := tx.CreateBucketIfNotExists(settingsBucket)
+		if err != nil {
+			return err
+		}
+		stored := settings.Get(optionsKey)
+		if stored == nil {
+			// A database indexed before the options were stored has commits but no key
+			if first, _ := commits.Cursor().First(); first != nil {
+				return fmt.Errorf("%s: %w", path, ErrOptionsMismatch)
+			}
+			return settings.Put(optionsKey, key)
+		}
+		if string(stored) != string(key) {
+			return fmt.Errorf("%s: %w", path, ErrOptionsMismatch)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Genealogy{repository: repository, db: db, opts: opts}, nil
+}
+
+// Close closes the database.
+func (genealogy *Genealogy) Close() error {
+	return genealogy.db.Close()
+}
+
+// Update indexes the commits along the first-parent history of rev that aren't indexed yet,
+// oldest first, and returns how many were indexed. Each commit is stored as soon as it is
+// indexed, so an interrupted update keeps its progress.
+func (genealogy *Genealogy) Update(rev string) (int, error) {
+	commits, err := genealogy.repository.Commits("", rev)
+	if err != nil {
+		return 0, err
+	}
+	indexed := 0
+	for _, commit := range commits {
+		record, err := genealogy.record(commit.SHA)
+		if err != nil {
+			return indexed, err
+		}
+		if record != nil {
+			continue
+		}
+		fileChanges, err := genealogy.repository.FileChanges(commit, genealogy.opts...)
+		if err != nil {
+			return indexed, err
+		}
+		record = &commitRecord{Commit: commit}
+		for _, fileChange := range fileChanges {
+			record.Changes = append(record.Changes, newChangeRecord(fileChange))
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return indexed, err
+		}
+		err = genealogy.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(commitsBucket).Put([]byte(commit.SHA), data)
+		})
+		if err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
+func newChangeRecord(fileChange FileChange) changeRecord {
+	change := changeRecord{Status: string(fileChange.Status), OldPath: fileChange.OldPath, NewPath: fileChange.NewPath}
+	if fileChange.Result == nil {
+		return change
+	}
+	for _, mapping := range fileChange.Result.Mappings {
+		if mapping.Left == -1 || mapping.Right == -1 {
+			continue
+		}
+		if n := len(change.Runs); n > 0 {
+			last := &change.Runs[n-1]
+			if mapping.Left == last.Left+last.Length && mapping.Right == last.Right+last.Length && mapping.Similarity == last.Similarity {
+				last.Length++
+				continue
+			}
+		}
+		change.Runs = append(change.Runs, run{Left: mapping.Left, Right: mapping.Right, Length: 1, Similarity: mapping.Similarity})
+	}
+	return change
+}
+
+// record returns the stored record of a commit, or nil if it isn't indexed.
+func (genealogy *Genealogy) record(sha string) (*commitRecord, error) {
+	var data []byte
+	err := genealogy.db.View(func(tx *bolt.Tx) error {
+		data = append(data, tx.Bucket(commitsBucket).Get([]byte(sha))...)
+		return nil
+	})
+	if err != nil || data == nil {
+		return nil, err
+	}
+	record := &commitRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("commit %s: %w", sha, err)
+	}
+	return record, nil
+}
+
+func (genealogy *Genealogy) indexedRecord(sha string) (*commitRecord, error) {
+	record, err := genealogy.record(sha)
+	if err == nil && record == nil {
+		err = fmt.Errorf("commit %s isn't indexed: %w", sha, ErrMappingStale)
+	}
+	return record, err
+}
+
+// forward returns the new line that an old line maps to.
+func (change *changeRecord) forward(line int) (int, float64, bool) {
+	i := sort.Search(len(change.Runs), func(i int) bool {
+		return change.Runs[i].Left+change.Runs[i].Length > line
+	})
+	if i == len(change.Runs) || change.Runs[i].Left > line {
+		return -1, 0, false
+	}
+	return change.Runs[i].Right + line - change.Runs[i].Left, change.Runs[i].Similarity, true
+}
+
+// backward returns the old line that a new line maps to.
+func (change *changeRecord) backward(line int) (int, float64, bool) {
+	for _, r := range change.Runs {
+		if line >= r.Right && line < r.Right+r.Length {
+			return r.Left + line - r.Right, r.Similarity, true
+		}
+	}
+	return -1, 0, false
+}
+
+// Hop is a commit that changed a line: it introduced, moved, edited or deleted it. Path and Line
+// (one-based) are the location of the line after the commit, or before it if it was deleted.
+// Similarity is the similarity of the line to its previous version.
+type Hop struct {
+	Commit     Commit
+	Path       string
+	Line       int
+	Similarity float64
+	Introduced bool
+	Deleted    bool
+}
+
+// Origin follows a line (one-based) at rev back through the history, and returns the commits
+// that changed it, newest first. The last hop is the commit that introduced the line.
+func (genealogy *Genealogy) Origin(rev string, path string, line int) ([]Hop, error) {
+	if line < 1 {
+		return nil, fmt.Errorf("invalid line: %d", line)
+	}
+	sha, err := genealogy.repository.ResolveRevision(rev)
+	if err != nil {
+		return nil, err
+	}
+	var hops []Hop
+	for sha != "" {
+		record, err := genealogy.indexedRecord(sha)
+		if err != nil {
+			return nil, err
+		}
+		sha = record.Commit.Parent
+		change := record.change(func(change *changeRecord) bool { return change.NewPath == path })
+		if change == nil {
+			continue
+		}
+		hop := Hop{Commit: record.Commit, Path: path, Line: line}
+		oldLine, similarity, ok := change.backward(line - 1)
+		if change.OldPath == "" || !ok {
+			hop.Introduced = true
+			return append(hops, hop), nil
+		}
+		hop.Similarity = similarity
+		if change.OldPath != path || oldLine != line-1 || similarity != 1 {
+			hops = append(hops, hop)
+		}
+		path, line = change.OldPath, oldLine+1
+	}
+	return hops, nil
+}
+
+// Follow follows a line (one-based) at from forward to to, which must be a first-parent
+// descendant of from, and returns the commits that changed it, oldest first. The location of
+// the line at to is that of the last hop, or the given one if no commit changed it. The last
+// hop is marked as deleted if the line was deleted.
+func (genealogy *Genealogy) Follow(from string, to string, path string, line int) ([]Hop, error) {
+	if line < 1 {
+		return nil, fmt.Errorf("invalid line: %d", line)
+	}
+	records, err := genealogy.between(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var hops []Hop
+	for _, record := range records {
+		change := record.change(func(change *changeRecord) bool { return change.OldPath == path && change.Status != "C" })
+		if change == nil {
+			continue
+		}
+		newLine, similarity, ok := change.forward(line - 1)
+		if change.NewPath == "" || !ok {
+			return append(hops, Hop{Commit: record.Commit, Path: path, Line: line, Deleted: true}), nil
+		}
+		if change.NewPath != path || newLine != line-1 || similarity != 1 {
+			hops = append(hops, Hop{Commit: record.Commit, Path: change.NewPath, Line: newLine + 1, Similarity: similarity})
+		}
+		path, line = change.NewPath, newLine+1
+	}
+	return hops, nil
+}
+
+// between returns the records of the commits after from up to to, oldest first.
+func (genealogy *Genealogy) between(from string, to string) ([]*commitRecord, error) {
+	fromSHA, err := genealogy.repository.ResolveRevision(from)
+	if err != nil {
+		return nil, err
+	}
+	sha, err := genealogy.repository.ResolveRevision(to)
+	if err != nil {
+		return nil, err
+	}
+	var records []*commitRecord
+	for sha != fromSHA {
+		if sha == "" {
+			return nil, fmt.Errorf("%s isn't a first-parent ancestor of %s", from, to)
+		}
+		record, err := genealogy.indexedRecord(sha)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+		sha = record.Commit.Parent
+	}
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+func (record *commitRecord) change(matches func(*changeRecord) bool) *changeRecord {
+	for i := range record.Changes {
+		if matches(&record.Changes[i]) {
+			return &record.Changes[i]
+		}
+	}
+	return nil
+}
+
+// GenealogySchema is the schema of the records written by WriteAvro. There is one record per run
+// of consecutive lines that a commit mapped from an old file to a new one with the same
+// similarity, so files that were added or deleted as a whole have no records. Line numbers are
+// one-based.
+const GenealogySchema = `{
+  "type": "record",
+  "name": "GenealogyRun",
+  "namespace": "com.smartbear.lhdiff",
+  "doc": "Consecutive lines that a commit mapped from an old file to a new one.",
+  "fields": [
+    {"name": "commit", "type": "string", "doc": "SHA of the commit."},
+    {"name": "parent", "type": "string", "doc": "SHA of the first parent of the commit, empty for a root commit."},
+    {"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}, "doc": "Commit time."},
+    {"name": "status", "type": "string", "doc": "Status reported by git diff --name-status: M (modified), R (renamed), C (copied), etc."},
+    {"name": "old_path", "type": "string", "doc": "Path of the file before the commit."},
+    {"name": "new_path", "type": "string", "doc": "Path of the file after the commit."},
+    {"name": "old_line", "type": "long", "doc": "First one-based line of the run before the commit."},
+    {"name": "new_line", "type": "long", "doc": "First one-based line of the run after the commit."},
+    {"name": "length", "type": "long", "doc": "Number of lines of the run."},
+    {"name": "similarity", "type": "double", "doc": "Similarity of each line of the run to its previous version."}
+  ]
+}`
+
+// WriteAvro writes the runs of lines of every indexed commit to w as an Avro object container
+// file with GenealogySchema, for analyzing line histories with data pipelines. Commits are
+// written in the order of their SHAs.
+func (genealogy *Genealogy) WriteAvro(w io.Writer) error {
+	writer, err := avro.NewWriter(w, GenealogySchema)
+	if err != nil {
+		return err
+	}
+	err = genealogy.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(commitsBucket).ForEach(func(sha []byte, data []byte) error {
+			record := &commitRecord{}
+			if err := json.Unmarshal(data, record); err != nil {
+				return fmt.Errorf("commit %s: %w", sha, err)
+			}
+			for _, change := range record.Changes {
+				for _, r := range change.Runs {
+					err := writer.Append(func(encoder *avro.Encoder) {
+						encoder.String(record.Commit.SHA)
+						encoder.String(record.Commit.Parent)
+						encoder.Long(record.Commit.Time.UnixNano() / int64(time.Millisecond))
+						encoder.String(change.Status)
+						encoder.String(change.OldPath)
+						encoder.String(change.NewPath)
+						encoder.Long(int64(r.Left) + 1)
+						encoder.Long(int64(r.Right) + 1)
+						encoder.Long(int64(r.Length))
+						encoder.Double(r.Similarity)
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"path/filepath"
+)
+
+func ExampleGenealogy() {
+	repository, shas := newTestRepository(
+		map[string]string{"main.go": `package main
+
+func main() {
+	run("server")
+}
+`},
+		map[string]string{"main.go": `package main
+
+import "os"
+
+func main() {
+	run("server")
+	os.Exit(0)
+}
+
+func run() {
+	println("starting")
+	println("running")
+	println("stopping")
+}
+`},
+		map[string]string{"app.go": `package main
+
+import "os"
+
+func main() {
+	run("servers")
+	os.Exit(0)
+}
+
+func run() {
+	println("starting")
+	println("running")
+	println("stopping")
+}
+`, "main.go": ""},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	genealogy, err := repository.OpenGenealogy(filepath.Join(repository.Dir, ".git", "lhdiff.db"))
+	check(err)
+	defer genealogy.Close()
+	indexed, err := genealogy.Update(shas[1])
+	check(err)
+	fmt.Printf("indexed %d commit(s)\n", indexed)
+	_, err = genealogy.Origin("HEAD", "app.go", 6)
+	fmt.Println(errors.Is(err, ErrMappingStale))
+	// Only the new commit is indexed
+	indexed, err = genealogy.Update("HEAD")
+	check(err)
+	fmt.Printf("indexed %d commit(s)\n", indexed)
+
+	printHops := func(hops []Hop) {
+		for _, hop := range hops {
+			fmt.Printf("  %q %s:%d %.2f introduced=%v deleted=%v\n", hop.Commit.Subject, hop.Path, hop.Line, hop.Similarity, hop.Introduced, hop.Deleted)
+		}
+	}
+	fmt.Println("origin of app.go:6")
+	hops, err := genealogy.Origin("HEAD", "app.go", 6)
+	check(err)
+	printHops(hops)
+
+	fmt.Println("main.go:4 followed to HEAD")
+	hops, err = genealogy.Follow(shas[0], "HEAD", "main.go", 4)
+	check(err)
+	printHops(hops)
+
+	// Output:
+	// indexed 2 commit(s)
+	// true
+	// indexed 1 commit(s)
+	// origin of app.go:6
+	//   "revision 2" app.go:6 0.96 introduced=false deleted=false
+	//   "revision 1" main.go:6 1.00 introduced=false deleted=false
+	//   "revision 0" main.go:4 0.00 introduced=true deleted=false
+	// main.go:4 followed to HEAD
+	//   "revision 1" main.go:6 1.00 introduced=false deleted=false
+	//   "revision 2" app.go:6 0.96 introduced=false deleted=false
+}
+
+func ExampleRepository_OpenGenealogy_options() {
+	repository, _ := newTestRepository(map[string]string{"main.go": "package main\n"})
+	defer os.RemoveAll(repository.Dir)
+	path := filepath.Join(repository.Dir, ".git", "lhdiff.db")
+
+	genealogy, err := repository.OpenGenealogy(path)
+	check(err)
+	_, err = genealogy.Update("HEAD")
+	check(err)
+	check(genealogy.Close())
+
+	// Options that don't change the mappings, such as redaction, can differ
+	genealogy, err = repository.OpenGenealogy(path, lhdiff.WithRedaction("salt"))
+	check(err)
+	check(genealogy.Close())
+	_, err = repository.OpenGenealogy(path, lhdiff.WithContextSize(2))
+	fmt.Println(errors.Is(err, ErrOptionsMismatch))
+	repository.TextConv = true
+	_, err = repository.OpenGenealogy(path)
+	fmt.Println(errors.Is(err, ErrOptionsMismatch))
+
+	// Output:
+	// true
+	// true
+}
@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"github.com/SmartBear/lhdiff"
+	"sort"
+)
+
+// FileHotSpot is a file whose lines were rewritten: Rewrites is the number of times one of its
+// lines was modified, and Commits the number of commits that modified its lines.
+type FileHotSpot struct {
+	Path     string
+	Rewrites int
+	Commits  int
+}
+
+// RegionHotSpot is a run of consecutive lines (one-based, inclusive) that were each rewritten at
+// least once. Rewrites is the number of times they were modified in total.
+type RegionHotSpot struct {
+	Path     string
+	Start    int
+	End      int
+	Rewrites int
+}
+
+// HotSpots are the files and regions of the lines at the end of a commit range that were
+// rewritten the most, most rewritten first.
+type HotSpots struct {
+	Files   []FileHotSpot
+	Regions []RegionHotSpot
+}
+
+// hotSpotTracker follows the lines of each file through the commits, counting how many times
+// each of them was modified.
+type hotSpotTracker struct {
+	// rewrites are the counts of each zero-based line of each file
+	rewrites map[string][]int
+	files    map[string]*FileHotSpot
+}
+
+// HotSpots walks the commits between from and to (see Commits), and counts how many times the
+// lines of each file were rewritten. A line is rewritten when lhdiff pairs it with a line whose
+// content is different. Unlike the churn of diffs, lines that are only moved or reindented, within
+// a file or to a renamed one, don't count, and the counts follow the lines as they move, so that
+// the regions are those of the files at to. Files that don't exist at to are left out.
+func (repository *Repository) HotSpots(from string, to string, opts ...lhdiff.Option) (*HotSpots, error) {
+	tracker := &hotSpotTracker{rewrites: make(map[string][]int), files: make(map[string]*FileHotSpot)}
+	if err := repository.Walk(from, to, tracker.visit, opts...); err != nil {
+		return nil, err
+	}
+	hotSpots := &HotSpots{Files: make([]FileHotSpot, 0), Regions: make([]RegionHotSpot, 0)}
+	for path, file := range tracker.files {
+		if _, exists := tracker.rewrites[path]; exists && file.Rewrites > 0 {
+			hotSpots.Files = append(hotSpots.Files, *file)
+		}
+	}
+	sort.Slice(hotSpots.Files, func(i, j int) bool {
+		if hotSpots.Files[i].Rewrites != hotSpots.Files[j].Rewrites {
+			return hotSpots.Files[i].Rewrites > hotSpots.Files[j].Rewrites
+		}
+		return hotSpots.Files[i].Path < hotSpots.Files[j].Path
+	})
+	for path, rewrites := range tracker.rewrites {
+		for line := 0; line < len(rewrites); line++ {
+			if rewrites[line] == 0 {
+				continue
+			}
+			region := RegionHotSpot{Path: path, Start: line + 1}
+			for ; line < len(rewrites) && rewrites[line] > 0; line++ {
+				region.Rewrites += rewrites[line]
+			}
+			region.End = line
+			hotSpots.Regions = append(hotSpots.Regions, region)
+		}
+	}
+	sort.Slice(hotSpots.Regions, func(i, j int) bool {
+		a, b := hotSpots.Regions[i], hotSpots.Regions[j]
+		if a.Rewrites != b.Rewrites {
+			return a.Rewrites > b.Rewrites
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Start < b.Start
+	})
+	return hotSpots, nil
+}
+
+func (tracker *hotSpotTracker) visit(commit Commit, fileChanges []FileChange) error {
+	// The counts of the new files are computed from the counts before the commit, so that
+	// renames and copies are followed whatever their order
+	rewrites := make(map[string][]int)
+	for path, counts := range tracker.rewrites {
+		rewrites[path] = counts
+	}
+	for _, fileChange := range fileChanges {
+		if fileChange.Status != 'C' && fileChange.OldPath != "" {
+			delete(rewrites, fileChange.OldPath)
+		}
+	}
+	for _, fileChange := range fileChanges {
+		if fileChange.NewPath == "" {
+			continue
+		}
+		// A renamed or copied file keeps the counts of the file it came from
+		file := &FileHotSpot{}
+		if previous := tracker.files[fileChange.OldPath]; previous != nil {
+			*file = *previous
+		}
+		file.Path = fileChange.NewPath
+		if fileChange.Result == nil {
+			rewrites[fileChange.NewPath] = make([]int, len(lhdiff.ConvertToLinesWithoutNewLine(fileChange.New)))
+			tracker.files[fileChange.NewPath] = file
+			continue
+		}
+		old := tracker.rewrites[fileChange.OldPath]
+		if len(old) != fileChange.Result.LeftLineCount {
+			old = make([]int, fileChange.Result.LeftLineCount)
+		}
+		oldLines, newLines := lhdiff.ConvertToLinesWithoutNewLine(fileChange.Old), lhdiff.ConvertToLinesWithoutNewLine(fileChange.New)
+		counts := make([]int, fileChange.Result.RightLineCount)
+		rewritten := false
+		for _, mapping := range append(append([]lhdiff.LineMapping(nil), fileChange.Result.Mappings...), fileChange.Result.Moved...) {
+			if mapping.Left == -1 || mapping.Right == -1 {
+				continue
+			}
+			counts[mapping.Right] = old[mapping.Left]
+			// Lines that only moved may be less similar than 1 because of their contexts
+			if oldLines[mapping.Left] != newLines[mapping.Right] {
+				counts[mapping.Right]++
+				file.Rewrites++
+				rewritten = true
+			}
+		}
+		if rewritten {
+			file.Commits++
+		}
+		rewrites[fileChange.NewPath] = counts
+		tracker.files[fileChange.NewPath] = file
+	}
+	tracker.rewrites = rewrites
+	return nil
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRepository_HotSpots() {
+	repository, _ := newTestRepository(
+		map[string]string{
+			"main.go": `package main
+
+func main() {
+	run("server", 1)
+	stop()
+}
+`,
+			"util.go": `package main
+
+func stop() {
+	println("stopping")
+}
+`,
+		},
+		map[string]string{
+			"main.go": `package main
+
+func main() {
+	run("server", 2)
+	stop()
+}
+`,
+			"util.go": `package main
+
+func stop() {
+	println("stopping")
+}
+
+func start() {
+	println("starting")
+}
+`,
+		},
+		map[string]string{
+			"app.go": `package main
+
+func main() {
+	stop()
+	run("server", 3)
+}
+`,
+			"main.go": "",
+			"util.go": `package main
+
+func start() {
+	println("starting")
+}
+
+func stop() {
+	println("stopping")
+}
+`,
+		},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	hotSpots, err := repository.HotSpots("", "HEAD")
+	check(err)
+	for _, file := range hotSpots.Files {
+		fmt.Printf("%s: %d rewrite(s) in %d commit(s)\n", file.Path, file.Rewrites, file.Commits)
+	}
+	for _, region := range hotSpots.Regions {
+		fmt.Printf("%s:%d-%d: %d rewrite(s)\n", region.Path, region.Start, region.End, region.Rewrites)
+	}
+
+	// Output:
+	// app.go: 2 rewrite(s) in 2 commit(s)
+	// app.go:5-5: 2 rewrite(s)
+}
@@ -0,0 +1,176 @@
+package repo
+
+import (
+	"github.com/SmartBear/lhdiff"
+	"regexp"
+	"strings"
+)
+
+// MarkerLocation is where a marker was found after a commit. Line is one-based.
+type MarkerLocation struct {
+	Commit Commit
+	Path   string
+	Line   int
+	Text   string
+}
+
+// Marker is a TODO/FIXME (or similar) comment line tracked across a range of commits. Its
+// identity follows the line as it moves or is edited, so its age isn't reset when the
+// surrounding code shifts.
+type Marker struct {
+	Keyword string
+	// History has the location where the marker was introduced, followed by a location for
+	// each commit that moved or edited it. The last location is the current one.
+	History []MarkerLocation
+	// IntroducedBefore is true if the marker already existed at the start of the range, in
+	// which case it may be older than its first location.
+	IntroducedBefore bool
+	// Resolved is the commit that removed the marker, or nil if it still exists.
+	Resolved *Commit
+}
+
+// Introduced returns the location where the marker was (first seen to be) introduced.
+func (marker *Marker) Introduced() MarkerLocation {
+	return marker.History[0]
+}
+
+// Current returns the last known location of the marker.
+func (marker *Marker) Current() MarkerLocation {
+	return marker.History[len(marker.History)-1]
+}
+
+// MarkerPattern returns a pattern matching any of the keywords as a whole word.
+func MarkerPattern(keywords []string) *regexp.Regexp {
+	quoted := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		quoted[i] = regexp.QuoteMeta(keyword)
+	}
+	return regexp.MustCompile(`\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+type markerTracker struct {
+	pattern *regexp.Regexp
+	markers []*Marker
+	// byPath has the markers that still exist, by path
+	byPath map[string][]*Marker
+}
+
+// TrackMarkers tracks the lines matching pattern (see MarkerPattern) across the commits
+// between from and to. Markers that exist at from are found first, then each commit is
+// compared with its parent to move, resolve or introduce markers. An empty from tracks markers
+// over the whole history, which gives their true age. Markers are returned in the order they
+// were introduced. With lhdiff.WithRedaction, the texts of their locations are redacted.
+func (repository *Repository) TrackMarkers(from string, to string, pattern *regexp.Regexp, opts ...lhdiff.Option) ([]*Marker, error) {
+	tracker := &markerTracker{pattern: pattern, byPath: make(map[string][]*Marker)}
+	if from != "" {
+		start, err := repository.Commit(from)
+		if err != nil {
+			return nil, err
+		}
+		paths, err := repository.Files(start.SHA)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			content, err := repository.Show(start.SHA, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, marker := range tracker.introduce(start, path, content) {
+				marker.IntroducedBefore = true
+			}
+		}
+	}
+	err := repository.Walk(from, to, tracker.visit, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// Texts are only redacted once tracked, since edits are told apart by them
+	redact := lhdiff.Redaction(opts...)
+	for _, marker := range tracker.markers {
+		for i := range marker.History {
+			marker.History[i].Text = redact(marker.History[i].Text)
+		}
+	}
+	return tracker.markers, nil
+}
+
+func (tracker *markerTracker) visit(commit Commit, fileChanges []FileChange) error {
+	// Markers are moved for all files before new ones are introduced, so that a marker moved
+	// to a renamed file isn't mistaken for a new one.
+	moved := make(map[string]map[int]bool)
+	changed := make(map[string]bool)
+	for _, fileChange := range fileChanges {
+		changed[fileChange.OldPath] = true
+	}
+	byPath := make(map[string][]*Marker)
+	for path, markers := range tracker.byPath {
+		if !changed[path] {
+			byPath[path] = markers
+		}
+	}
+	for _, fileChange := range fileChanges {
+		markers := tracker.byPath[fileChange.OldPath]
+		if fileChange.OldPath == "" || len(markers) == 0 {
+			continue
+		}
+		newLines := strings.Split(fileChange.New, "\n")
+		for _, marker := range markers {
+			line := marker.Current().Line
+			newLine, ok := -1, false
+			if fileChange.Result != nil {
+				newLine, _, ok = fileChange.Result.RightLine(line - 1)
+			}
+			if !ok || newLine >= len(newLines) || !tracker.pattern.MatchString(newLines[newLine]) {
+				resolved := commit
+				marker.Resolved = &resolved
+				continue
+			}
+			text := strings.TrimSpace(newLines[newLine])
+			current := marker.Current()
+			if current.Path != fileChange.NewPath || current.Line != newLine+1 || current.Text != text {
+				marker.History = append(marker.History, MarkerLocation{Commit: commit, Path: fileChange.NewPath, Line: newLine + 1, Text: text})
+			}
+			byPath[fileChange.NewPath] = append(byPath[fileChange.NewPath], marker)
+			if moved[fileChange.NewPath] == nil {
+				moved[fileChange.NewPath] = make(map[int]bool)
+			}
+			moved[fileChange.NewPath][newLine] = true
+		}
+	}
+	tracker.byPath = byPath
+	for _, fileChange := range fileChanges {
+		if fileChange.NewPath == "" {
+			continue
+		}
+		tracker.introduceExcept(commit, fileChange.NewPath, fileChange.New, moved[fileChange.NewPath])
+	}
+	return nil
+}
+
+func (tracker *markerTracker) introduce(commit Commit, path string, content string) []*Marker {
+	return tracker.introduceExcept(commit, path, content, nil)
+}
+
+// introduceExcept adds a marker for each line of content matching the pattern, except for the
+// (zero-based) lines in existing.
+func (tracker *markerTracker) introduceExcept(commit Commit, path string, content string, existing map[int]bool) []*Marker {
+	var introduced []*Marker
+	for i, line := range strings.Split(content, "\n") {
+		if existing[i] {
+			continue
+		}
+		keyword := tracker.pattern.FindString(line)
+		if keyword == "" {
+			continue
+		}
+		marker := &Marker{
+			Keyword: keyword,
+			History: []MarkerLocation{{Commit: commit, Path: path, Line: i + 1, Text: strings.TrimSpace(line)}},
+		}
+		introduced = append(introduced, marker)
+		tracker.markers = append(tracker.markers, marker)
+		tracker.byPath[path] = append(tracker.byPath[path], marker)
+	}
+	return introduced
+}
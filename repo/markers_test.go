@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRepository_TrackMarkers() {
+	repository, _ := newTestRepository(
+		map[string]string{"main.go": `package main
+
+func main() {
+	// TODO: handle errors
+	run()
+}
+`},
+		map[string]string{"main.go": `package main
+
+import "os"
+
+func main() {
+	// TODO: handle errors
+	run()
+	// FIXME: exit code
+	os.Exit(0)
+}
+
+func run() {
+	println("starting")
+	println("running")
+	println("stopping")
+}
+`},
+		map[string]string{"app.go": `package main
+
+import "os"
+
+func main() {
+	// TODO: handle all errors
+	run()
+	os.Exit(0)
+}
+
+func run() {
+	println("starting")
+	println("running")
+	println("stopping")
+}
+`, "main.go": ""},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	markers, err := repository.TrackMarkers("", "HEAD", MarkerPattern([]string{"TODO", "FIXME"}))
+	check(err)
+	for _, marker := range markers {
+		fmt.Printf("%s introduced in %q\n", marker.Keyword, marker.Introduced().Commit.Subject)
+		for _, location := range marker.History {
+			fmt.Printf("  %s:%d %s\n", location.Path, location.Line, location.Text)
+		}
+		if marker.Resolved != nil {
+			fmt.Printf("  resolved in %q\n", marker.Resolved.Subject)
+		}
+	}
+
+	// Output:
+	// TODO introduced in "revision 0"
+	//   main.go:4 // TODO: handle errors
+	//   main.go:6 // TODO: handle errors
+	//   app.go:6 // TODO: handle all errors
+	// FIXME introduced in "revision 1"
+	//   main.go:8 // FIXME: exit code
+	//   resolved in "revision 2"
+}
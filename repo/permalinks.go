@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+var /* const */ permalinkPattern = regexp.MustCompile(`https://github\.com/([\w.-]+)/([\w.-]+)/blob/([^/\s]+)/([^\s#?)\]>"']+)#L(\d+)(?:-L(\d+))?`)
+
+// Permalink is a GitHub blob link with a line (or line range) fragment, such as
+// https://github.com/owner/name/blob/<rev>/<path>#L10-L12. Lines are one-based, and
+// EndLine is 0 for a single line.
+type Permalink struct {
+	Owner     string
+	Name      string
+	Revision  string
+	Path      string
+	StartLine int
+	EndLine   int
+}
+
+func (permalink Permalink) String() string {
+	s := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s#L%d", permalink.Owner, permalink.Name, permalink.Revision, permalink.Path, permalink.StartLine)
+	if permalink.EndLine != 0 {
+		s += fmt.Sprintf("-L%d", permalink.EndLine)
+	}
+	return s
+}
+
+// PermalinkFix describes what happened to a permalink found by FixPermalinks. New is the
+// rewritten link, or the zero Permalink if the link couldn't be fixed, in which case Problem
+// says why.
+type PermalinkFix struct {
+	Old     Permalink
+	New     Permalink
+	Problem string
+}
+
+// FixPermalinks finds the permalinks in text whose revision exists in the repository, recomputes
+// their line numbers at revision to and rewrites them to point at to. Links to lines that no
+// longer exist are reported as dead and left unchanged. Only links to the given owner/name are
+// considered, unless it is empty.
+func (repository *Repository) FixPermalinks(text string, to string, ownerAndName string, opts ...lhdiff.Option) (string, []PermalinkFix, error) {
+	toSha, err := repository.ResolveRevision(to)
+	if err != nil {
+		return "", nil, err
+	}
+	remappers := make(map[string]*lhdiff.Remapper)
+	var fixes []PermalinkFix
+	var firstErr error
+	fixed := permalinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := permalinkPattern.FindStringSubmatch(link)
+		old := Permalink{Owner: match[1], Name: match[2], Revision: match[3], Path: match[4]}
+		old.StartLine, _ = strconv.Atoi(match[5])
+		old.EndLine, _ = strconv.Atoi(match[6])
+		if ownerAndName != "" && ownerAndName != old.Owner+"/"+old.Name {
+			return link
+		}
+		fix, err := repository.fixPermalink(old, toSha, remappers, opts)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return link
+		}
+		fixes = append(fixes, fix)
+		if fix.Problem != "" {
+			return link
+		}
+		return fix.New.String()
+	})
+	if firstErr != nil {
+		return "", nil, firstErr
+	}
+	return fixed, fixes, nil
+}
+
+func (repository *Repository) fixPermalink(old Permalink, toSha string, remappers map[string]*lhdiff.Remapper, opts []lhdiff.Option) (PermalinkFix, error) {
+	fix := PermalinkFix{Old: old}
+	fromSha, err := repository.ResolveRevision(old.Revision)
+	if err != nil {
+		fix.Problem = "unknown revision"
+		return fix, nil
+	}
+	newPath, exists, err := repository.NewPath(fromSha, toSha, old.Path)
+	if err != nil {
+		return fix, err
+	}
+	if !exists {
+		fix.Problem = "file deleted"
+		return fix, nil
+	}
+	content, err := repository.Show(fromSha, old.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		fix.Problem = "file not found"
+		return fix, nil
+	}
+	if err != nil {
+		return fix, err
+	}
+	newContent, err := repository.Show(toSha, newPath)
+	if err != nil {
+		return fix, err
+	}
+	key := fromSha + ":" + old.Path
+	remapper, ok := remappers[key]
+	if !ok {
+		remapper = lhdiff.NewRemapper(lhdiff.MapSnapshot{old.Path: content}, lhdiff.MapSnapshot{old.Path: newContent}, opts...)
+		remappers[key] = remapper
+	}
+
+	endLine := old.EndLine
+	if endLine == 0 {
+		endLine = old.StartLine
+	}
+	// A range is mapped to the span of its lines that still exist
+	newStart, newEnd := -1, -1
+	for line := old.StartLine; line <= endLine; line++ {
+		newLine, _, ok, err := remapper.Remap(old.Path, line-1)
+		if err != nil {
+			return fix, err
+		}
+		if !ok {
+			continue
+		}
+		if newStart == -1 || newLine+1 < newStart {
+			newStart = newLine + 1
+		}
+		if newLine+1 > newEnd {
+			newEnd = newLine + 1
+		}
+	}
+	if newStart == -1 {
+		fix.Problem = "dead link"
+		return fix, nil
+	}
+	fix.New = Permalink{Owner: old.Owner, Name: old.Name, Revision: toSha, Path: newPath, StartLine: newStart}
+	if old.EndLine != 0 && newEnd != newStart {
+		fix.New.EndLine = newEnd
+	}
+	return fix, nil
+}
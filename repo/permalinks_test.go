@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func ExampleRepository_FixPermalinks() {
+	repository, shas := newTestRepository(
+		map[string]string{"hello.go": "package hello\n\n// TODO: say more\nfunc Hello() string {\n\treturn \"hello\"\n}\n"},
+		map[string]string{"hello.go": "package hello\n\nvar greeting = 1\n\n// Hello says hello\nfunc Hello() string {\n\treturn \"hello, world\"\n}\n"},
+	)
+	defer os.RemoveAll(repository.Dir)
+	text := strings.Join([]string{
+		"See https://github.com/acme/hello/blob/" + shas[0] + "/hello.go#L4-L6 for the function,",
+		"https://github.com/acme/hello/blob/" + shas[0] + "/hello.go#L5 for the return statement",
+		"and https://github.com/acme/hello/blob/" + shas[0] + "/hello.go#L3 for the TODO.",
+		"This is not ours: https://github.com/other/repo/blob/main/x.go#L1",
+	}, "\n")
+
+	fixed, fixes, err := repository.FixPermalinks(text, "HEAD", "acme/hello")
+	check(err)
+	fixed = strings.ReplaceAll(fixed, shas[0], "<OLD>")
+	fmt.Println(strings.ReplaceAll(fixed, shas[1], "<HEAD>"))
+	for _, fix := range fixes {
+		if fix.Problem != "" {
+			fmt.Printf("L%d: %s\n", fix.Old.StartLine, fix.Problem)
+		} else {
+			fmt.Printf("L%d -> L%d\n", fix.Old.StartLine, fix.New.StartLine)
+		}
+	}
+
+	// Output:
+	// See https://github.com/acme/hello/blob/<HEAD>/hello.go#L6-L8 for the function,
+	// https://github.com/acme/hello/blob/<HEAD>/hello.go#L7 for the return statement
+	// and https://github.com/acme/hello/blob/<OLD>/hello.go#L3 for the TODO.
+	// This is not ours: https://github.com/other/repo/blob/main/x.go#L1
+	// L4 -> L6
+	// L5 -> L7
+	// L3: dead link
+}
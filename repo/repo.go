@@ -0,0 +1,169 @@
+// Package repo reads files and history from a git repository by running the git command.
+package repo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrDiffParse is returned when the output of git diff can't be parsed.
+var ErrDiffParse = errors.New("unexpected git diff output")
+
+// Repository is a git repository (or a directory inside one).
+type Repository struct {
+	Dir string
+	// TextConv makes Show convert files with the textconv drivers of their diff attributes, such
+	// as one converting notebooks or encrypted files to text, so that they are compared as git
+	// diff shows them. Files without a textconv driver are read as they are.
+	TextConv bool
+	// Filters makes Show convert files with their smudge filters and end-of-line conversions, as
+	// they are checked out, such as the contents of Git LFS files instead of their pointers.
+	// TextConv takes precedence.
+	Filters bool
+}
+
+// Open returns the repository containing dir.
+func Open(dir string) (*Repository, error) {
+	repository := &Repository{Dir: dir}
+	_, err := repository.git("rev-parse", "--git-dir")
+	if err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func (repository *Repository) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repository.Dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// GitDir returns the absolute path of the repository's .git directory.
+func (repository *Repository) GitDir() (string, error) {
+	dir, err := repository.git("rev-parse", "--absolute-git-dir")
+	return strings.TrimSpace(dir), err
+}
+
+// ResolveRevision returns the full commit SHA of rev.
+func (repository *Repository) ResolveRevision(rev string) (string, error) {
+	sha, err := repository.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision: %s", rev)
+	}
+	return strings.TrimSpace(sha), nil
+}
+
+// Show returns the contents of path at rev. An error satisfying errors.Is(err, os.ErrNotExist)
+// is returned if the file doesn't exist at that revision.
+func (repository *Repository) Show(rev string, path string) (string, error) {
+	args := []string{"show", rev + ":" + path}
+	switch {
+	case repository.TextConv:
+		args = []string{"cat-file", "--textconv", rev + ":" + path}
+	case repository.Filters:
+		args = []string{"cat-file", "--filters", rev + ":" + path}
+	}
+	content, err := repository.git(args...)
+	if err != nil && !repository.exists(rev, path) {
+		return "", &os.PathError{Op: "show " + rev, Path: path, Err: os.ErrNotExist}
+	}
+	return content, err
+}
+
+// exists returns false if rev is a revision without path, rather than asking git in a way
+// whose messages depend on the locale.
+func (repository *Repository) exists(rev string, path string) bool {
+	if _, err := repository.git("cat-file", "-e", rev+":"+path); err == nil {
+		return true
+	}
+	_, err := repository.git("rev-parse", "--verify", "--quiet", rev+"^{tree}")
+	return err != nil
+}
+
+// Snapshot returns the files at rev as a lhdiff.Snapshot.
+func (repository *Repository) Snapshot(rev string) lhdiff.Snapshot {
+	return snapshot{repository: repository, rev: rev}
+}
+
+type snapshot struct {
+	repository *Repository
+	rev        string
+}
+
+func (s snapshot) ReadFile(path string) (string, error) {
+	return s.repository.Show(s.rev, path)
+}
+
+// Change is a file changed between two revisions. Status is the first letter of the
+// status reported by git diff --name-status: A(dded), D(eleted), M(odified), R(enamed), etc.
+// OldPath is empty for added files, and NewPath is empty for deleted files.
+type Change struct {
+	Status  byte
+	OldPath string
+	NewPath string
+}
+
+// Diff returns the files changed between from and to, detecting renames.
+func (repository *Repository) Diff(from string, to string) ([]Change, error) {
+	out, err := repository.git("diff", "--name-status", "-z", "--find-renames=50%", from, to)
+	if err != nil {
+		return nil, err
+	}
+	return parseNameStatus(out)
+}
+
+func parseNameStatus(out string) ([]Change, error) {
+	fields := strings.Split(out, "\x00")
+	var changes []Change
+	for i := 0; i < len(fields) && fields[i] != ""; {
+		status := fields[i][0]
+		switch status {
+		case 'R', 'C':
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("%w: %s without a new path", ErrDiffParse, fields[i])
+			}
+			changes = append(changes, Change{Status: status, OldPath: fields[i+1], NewPath: fields[i+2]})
+			i += 3
+		default:
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("%w: %s without a path", ErrDiffParse, fields[i])
+			}
+			change := Change{Status: status, OldPath: fields[i+1], NewPath: fields[i+1]}
+			if status == 'A' {
+				change.OldPath = ""
+			}
+			if status == 'D' {
+				change.NewPath = ""
+			}
+			changes = append(changes, change)
+			i += 2
+		}
+	}
+	return changes, nil
+}
+
+// NewPath returns the path that path at from has at to, following renames. The boolean is
+// false if the file was deleted.
+func (repository *Repository) NewPath(from string, to string, path string) (string, bool, error) {
+	changes, err := repository.Diff(from, to)
+	if err != nil {
+		return "", false, err
+	}
+	for _, change := range changes {
+		if change.OldPath == path && change.Status != 'C' {
+			return change.NewPath, change.NewPath != "", nil
+		}
+	}
+	return path, true, nil
+}
@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// newTestRepository creates a repository with one commit per revision, each commit
+// containing the given files. It returns the repository and the commit SHAs.
+func newTestRepository(revisions ...map[string]string) (*Repository, []string) {
+	dir, err := ioutil.TempDir("", "lhdiff-repo")
+	check(err)
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE=2022-01-01T00:00:00Z",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE=2022-01-01T00:00:00Z",
+			"GIT_CONFIG_NOSYSTEM=1",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			panic(fmt.Sprintf("git %v: %s", args, out))
+		}
+		return string(out)
+	}
+	git("init", "-q")
+	var shas []string
+	for i, files := range revisions {
+		for path, content := range files {
+			fullPath := filepath.Join(dir, path)
+			if content == "" {
+				check(os.Remove(fullPath))
+				continue
+			}
+			check(os.MkdirAll(filepath.Dir(fullPath), 0755))
+			check(ioutil.WriteFile(fullPath, []byte(content), 0644))
+		}
+		git("add", "-A")
+		git("commit", "-q", "--allow-empty", "-m", fmt.Sprintf("revision %d", i))
+		shas = append(shas, git("rev-parse", "HEAD")[:40])
+	}
+	repository, err := Open(dir)
+	check(err)
+	return repository, shas
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleRepository_Show_textConv() {
+	repository, _ := newTestRepository(map[string]string{
+		".gitattributes": "*.secret diff=upper\n",
+		"notes.secret":   "one\ntwo\n",
+	})
+	defer os.RemoveAll(repository.Dir)
+	_, err := repository.git("config", "diff.upper.textconv", "tr a-z A-Z <")
+	check(err)
+
+	content, err := repository.Show("HEAD", "notes.secret")
+	check(err)
+	fmt.Printf("%q\n", content)
+	repository.TextConv = true
+	content, err = repository.Show("HEAD", "notes.secret")
+	check(err)
+	fmt.Printf("%q\n", content)
+	_, err = repository.Show("HEAD", "missing.secret")
+	fmt.Println(errors.Is(err, os.ErrNotExist))
+
+	// Output:
+	// "one\ntwo\n"
+	// "ONE\nTWO\n"
+	// true
+}
+
+func ExampleRepository_Show_locale() {
+	repository, _ := newTestRepository(map[string]string{"main.go": "package main\n"})
+	defer os.RemoveAll(repository.Dir)
+	// Missing files are told apart from other errors whatever the language of the messages of git
+	for name, value := range map[string]string{"LANGUAGE": "de", "LC_ALL": "C.UTF-8"} {
+		previous, ok := os.LookupEnv(name)
+		check(os.Setenv(name, value))
+		if ok {
+			defer os.Setenv(name, previous)
+		} else {
+			defer os.Unsetenv(name)
+		}
+	}
+
+	_, err := repository.Show("HEAD", "missing.go")
+	fmt.Println(errors.Is(err, os.ErrNotExist))
+	_, err = repository.Show("unknown", "main.go")
+	fmt.Println(err != nil, errors.Is(err, os.ErrNotExist))
+
+	// Output:
+	// true
+	// true false
+}
@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"github.com/SmartBear/lhdiff"
+	"strings"
+)
+
+// FileStats are the Stats of a file changed between two revisions. Path is empty for a file that
+// was added, and NewPath for a file that was deleted.
+type FileStats struct {
+	Path    string
+	NewPath string
+	lhdiff.Stats
+	// Moves are the blocks of lines that moved (see Result.MovedBlocks)
+	Moves []lhdiff.Block
+	// Lost are the lines of the old version that were deleted (see Result.Lost)
+	Lost []LostLine
+	// Result is the comparison that the stats are of
+	Result *lhdiff.Result
+	// Lines are the lines of the old version, without their line endings, which Lost and the
+	// left lines of Result refer to, including the empty line after a final newline
+	Lines []string
+}
+
+// LostLine is a deleted line of the old version of a file, with its zero-based line number.
+type LostLine struct {
+	Line int
+	Text string
+}
+
+// NewFileStats returns the FileStats of the comparison of the old file at path with the file at
+// newPath.
+func NewFileStats(path string, newPath string, old string, result *lhdiff.Result) FileStats {
+	file := FileStats{Path: path, NewPath: newPath, Stats: result.Stats(), Moves: result.MovedBlocks(), Result: result}
+	file.Lines = strings.Split(old, "\n")
+	for i, line := range file.Lines {
+		file.Lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	for _, line := range result.Lost() {
+		if line < len(file.Lines) {
+			file.Lost = append(file.Lost, LostLine{Line: line, Text: file.Lines[line]})
+		}
+	}
+	return file
+}
+
+// Stats are the Stats of the files changed between two revisions, per file and overall.
+type Stats struct {
+	// Files are in the order of git diff
+	Files []FileStats
+	Total lhdiff.Stats
+}
+
+// Stats compares each file changed between from and to with the file it became, following
+// renames. The lines of added files are counted as added, and those of deleted files as deleted.
+func (repository *Repository) Stats(from string, to string, opts ...lhdiff.Option) (*Stats, error) {
+	changes, err := repository.Diff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{}
+	for _, change := range changes {
+		var old, content string
+		if change.OldPath != "" {
+			if old, err = repository.Show(from, change.OldPath); err != nil {
+				return nil, err
+			}
+		}
+		if change.NewPath != "" {
+			if content, err = repository.Show(to, change.NewPath); err != nil {
+				return nil, err
+			}
+		}
+		path := change.NewPath
+		if path == "" {
+			path = change.OldPath
+		}
+		result, err := lhdiff.Compare(old, content, append([]lhdiff.Option{lhdiff.WithPath(path)}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		file := NewFileStats(change.OldPath, change.NewPath, old, result)
+		stats.Files = append(stats.Files, file)
+		stats.Total.Add(file.Stats)
+	}
+	return stats, nil
+}
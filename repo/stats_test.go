@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRepository_Stats() {
+	repository, shas := newTestRepository(
+		map[string]string{
+			"main.go": `package main
+
+func main() {
+	run("server")
+	stop()
+}
+`,
+			"util.go": `package main
+
+func stop() {
+}
+`,
+			"README.md": `# App
+`,
+		},
+		map[string]string{
+			"app.go": `package main
+
+func main() {
+	stop()
+	run("servers")
+}
+`,
+			"main.go": "",
+			"util.go": "",
+			"LICENSE": `MIT
+`,
+		},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	stats, err := repository.Stats(shas[0], shas[1])
+	check(err)
+	for _, file := range stats.Files {
+		fmt.Printf("%q -> %q: %s\n", file.Path, file.NewPath, file.Summary)
+		fmt.Printf("  moves %v, lost %v\n", file.Moves, file.Lost)
+	}
+	fmt.Printf("total: %s, %.3f similar\n", stats.Total.Summary, stats.Total.Similarity)
+
+	// Output:
+	// "" -> "LICENSE": 0 unchanged, 0 changed, 0 reordered, 0 deleted, 2 added
+	//   moves [], lost []
+	// "main.go" -> "app.go": 6 unchanged, 0 changed, 1 reordered, 0 deleted, 0 added
+	//   moves [{3 4 1}], lost []
+	// "util.go" -> "": 0 unchanged, 0 changed, 0 reordered, 5 deleted, 0 added
+	//   moves [], lost [{0 package main} {1 } {2 func stop() {} {3 }} {4 }]
+	// total: 6 unchanged, 0 changed, 1 reordered, 5 deleted, 2 added, 0.994 similar
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"github.com/SmartBear/lhdiff"
+)
+
+// FileSurvival counts what became of the lines of a file between two revisions. NewPath is the
+// path of the file at the later revision, and empty if the file was deleted.
+type FileSurvival struct {
+	Path      string
+	NewPath   string
+	Lines     int
+	Unchanged int
+	Modified  int
+	Moved     int
+	Deleted   int
+}
+
+// Survived returns the fraction of the lines that are still in the file, unchanged, modified or
+// moved. It is 1 for a file without lines.
+func (survival FileSurvival) Survived() float64 {
+	return survival.Fraction(survival.Unchanged + survival.Modified + survival.Moved)
+}
+
+// Fraction returns count as a fraction of the lines. It is 1 for a file without lines.
+func (survival FileSurvival) Fraction(count int) float64 {
+	if survival.Lines == 0 {
+		return 1
+	}
+	return float64(count) / float64(survival.Lines)
+}
+
+func (survival *FileSurvival) add(other FileSurvival) {
+	survival.Lines += other.Lines
+	survival.Unchanged += other.Unchanged
+	survival.Modified += other.Modified
+	survival.Moved += other.Moved
+	survival.Deleted += other.Deleted
+}
+
+// Survival is what became of the lines of the files of a revision at a later one, per file and
+// overall.
+type Survival struct {
+	// Files are the files of the earlier revision, in path order
+	Files []FileSurvival
+	Total FileSurvival
+}
+
+// Survival compares each file at from with the file it became at to, following renames, and
+// counts its lines that are unchanged, modified, moved (see lhdiff.Result.Reordered) or deleted.
+// Lines that moved to another file are counted as deleted.
+func (repository *Repository) Survival(from string, to string, opts ...lhdiff.Option) (*Survival, error) {
+	paths, err := repository.Files(from)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := repository.Diff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]Change)
+	for _, change := range changes {
+		if change.OldPath != "" && change.Status != 'C' {
+			changed[change.OldPath] = change
+		}
+	}
+	survival := &Survival{}
+	for _, path := range paths {
+		old, err := repository.Show(from, path)
+		if err != nil {
+			return nil, err
+		}
+		file := FileSurvival{Path: path, NewPath: path, Lines: len(lhdiff.ConvertToLinesWithoutNewLine(old))}
+		change, ok := changed[path]
+		switch {
+		case !ok:
+			file.Unchanged = file.Lines
+		case change.NewPath == "":
+			file.NewPath = ""
+			file.Deleted = file.Lines
+		default:
+			file.NewPath = change.NewPath
+			content, err := repository.Show(to, change.NewPath)
+			if err != nil {
+				return nil, err
+			}
+			result, err := lhdiff.Compare(old, content, append([]lhdiff.Option{lhdiff.WithPath(change.NewPath)}, opts...)...)
+			if err != nil {
+				return nil, err
+			}
+			summary := result.Summary()
+			file.Unchanged, file.Modified, file.Moved, file.Deleted = summary.Unchanged, summary.Changed, summary.Reordered, summary.Deleted
+		}
+		survival.Files = append(survival.Files, file)
+		survival.Total.add(file)
+	}
+	return survival, nil
+}
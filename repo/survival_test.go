@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRepository_Survival() {
+	repository, shas := newTestRepository(
+		map[string]string{
+			"main.go": `package main
+
+func main() {
+	run("server")
+	stop()
+}
+`,
+			"util.go": `package main
+
+func stop() {
+}
+`,
+			"README.md": `# App
+`,
+		},
+		map[string]string{
+			"app.go": `package main
+
+func main() {
+	stop()
+	run("servers")
+}
+`,
+			"main.go": "",
+			"util.go": "",
+		},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	survival, err := repository.Survival(shas[0], shas[1])
+	check(err)
+	printSurvival := func(name string, file FileSurvival) {
+		fmt.Printf("%s: %d lines, %d unchanged, %d modified, %d moved, %d deleted, %.0f%% survived\n", name, file.Lines, file.Unchanged, file.Modified, file.Moved, file.Deleted, 100*file.Survived())
+	}
+	for _, file := range survival.Files {
+		printSurvival(fmt.Sprintf("%s -> %q", file.Path, file.NewPath), file)
+	}
+	printSurvival("total", survival.Total)
+
+	// Output:
+	// README.md -> "README.md": 2 lines, 2 unchanged, 0 modified, 0 moved, 0 deleted, 100% survived
+	// main.go -> "app.go": 7 lines, 6 unchanged, 0 modified, 1 moved, 0 deleted, 100% survived
+	// util.go -> "": 5 lines, 0 unchanged, 0 modified, 0 moved, 5 deleted, 0% survived
+	// total: 14 lines, 8 unchanged, 0 modified, 1 moved, 5 deleted, 64% survived
+}
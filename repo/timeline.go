@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"github.com/SmartBear/lhdiff"
+	"strings"
+	"time"
+)
+
+// TimelineEntry is a version of a line: the commit that introduced, moved, edited or deleted it,
+// where the line was after the commit (before it if it was deleted), and its content there. Line
+// is one-based, and Similarity is the similarity of the line to its previous version.
+type TimelineEntry struct {
+	Commit     string    `json:"commit"`
+	Time       time.Time `json:"time"`
+	Subject    string    `json:"subject"`
+	Path       string    `json:"path"`
+	Line       int       `json:"line"`
+	Content    string    `json:"content"`
+	Similarity float64   `json:"similarity"`
+	Introduced bool      `json:"introduced,omitempty"`
+	Deleted    bool      `json:"deleted,omitempty"`
+}
+
+// Timeline returns the versions of a line (one-based) at rev, oldest first: the hops of Origin
+// followed by the hops of Follow up to to, each with the content of the line at that commit,
+// redacted if the genealogy was opened with lhdiff.WithRedaction.
+func (genealogy *Genealogy) Timeline(rev string, to string, path string, line int) ([]TimelineEntry, error) {
+	origin, err := genealogy.Origin(rev, path, line)
+	if err != nil {
+		return nil, err
+	}
+	followed, err := genealogy.Follow(rev, to, path, line)
+	if err != nil {
+		return nil, err
+	}
+	var hops []Hop
+	for i := len(origin) - 1; i >= 0; i-- {
+		hops = append(hops, origin[i])
+	}
+	hops = append(hops, followed...)
+	redact := lhdiff.Redaction(genealogy.opts...)
+	entries := make([]TimelineEntry, 0, len(hops))
+	for _, hop := range hops {
+		entry := TimelineEntry{
+			Commit:     hop.Commit.SHA,
+			Time:       hop.Commit.Time,
+			Subject:    hop.Commit.Subject,
+			Path:       hop.Path,
+			Line:       hop.Line,
+			Similarity: hop.Similarity,
+			Introduced: hop.Introduced,
+			Deleted:    hop.Deleted,
+		}
+		// A deleted line is only in the parent of the commit that deleted it
+		sha := hop.Commit.SHA
+		if hop.Deleted {
+			sha = hop.Commit.Parent
+		}
+		content, err := genealogy.repository.Show(sha, hop.Path)
+		if err != nil {
+			return nil, err
+		}
+		if lines := strings.SplitAfter(content, "\n"); hop.Line <= len(lines) {
+			entry.Content = redact(strings.TrimRight(lines[hop.Line-1], "\r\n"))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
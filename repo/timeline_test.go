@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func ExampleGenealogy_Timeline() {
+	repository, _ := newTestRepository(
+		map[string]string{"main.go": `package main
+
+func main() {
+	run("server")
+}
+`},
+		map[string]string{"main.go": `package main
+
+import "os"
+
+func main() {
+	run("servers")
+	os.Exit(0)
+}
+`},
+		map[string]string{"main.go": `package main
+
+import "os"
+
+func main() {
+	os.Exit(0)
+}
+`},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	genealogy, err := repository.OpenGenealogy(filepath.Join(repository.Dir, ".git", "lhdiff.db"))
+	check(err)
+	defer genealogy.Close()
+	_, err = genealogy.Update("HEAD")
+	check(err)
+
+	entries, err := genealogy.Timeline("HEAD~1", "HEAD", "main.go", 6)
+	check(err)
+	for _, entry := range entries {
+		fmt.Printf("%q %s:%d %q %.2f introduced=%v deleted=%v\n", entry.Subject, entry.Path, entry.Line, entry.Content, entry.Similarity, entry.Introduced, entry.Deleted)
+	}
+	_, err = genealogy.Timeline("HEAD~1", "HEAD", "main.go", 0)
+	fmt.Println(err)
+
+	// Output:
+	// "revision 0" main.go:4 "\trun(\"server\")" 0.00 introduced=true deleted=false
+	// "revision 1" main.go:6 "\trun(\"servers\")" 0.72 introduced=false deleted=false
+	// "revision 2" main.go:6 "\trun(\"servers\")" 0.00 introduced=false deleted=true
+	// invalid line: 0
+}
+
+func ExampleGenealogy_Timeline_redacted() {
+	repository, _ := newTestRepository(
+		map[string]string{"config.go": "package config\n\nconst password = \"hunter2\"\n\nconst user = \"admin\"\n"},
+		map[string]string{"config.go": "package config\n\nconst password = \"hunter22\"\n\nconst user = \"admin\"\n"},
+	)
+	defer os.RemoveAll(repository.Dir)
+
+	genealogy, err := repository.OpenGenealogy(filepath.Join(repository.Dir, ".git", "lhdiff.db"), lhdiff.WithRedaction("salt"))
+	check(err)
+	defer genealogy.Close()
+	_, err = genealogy.Update("HEAD")
+	check(err)
+
+	entries, err := genealogy.Timeline("HEAD", "HEAD", "config.go", 3)
+	check(err)
+	for _, entry := range entries {
+		fmt.Printf("%q %s:%d %q %v %v\n", entry.Subject, entry.Path, entry.Line, entry.Content, entry.Introduced, strings.Contains(entry.Content, "hunter"))
+	}
+
+	// Output:
+	// "revision 0" config.go:3 "#ee5c54904b44b39b" true false
+	// "revision 1" config.go:3 "#1a5986f1fffd0352" false false
+}
@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"github.com/SmartBear/lhdiff"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emptyTree is the SHA of git's empty tree, used as the parent of root commits.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Commit is a commit along the first-parent history of a revision range. Parent is empty
+// for a root commit.
+type Commit struct {
+	SHA     string
+	Parent  string
+	Time    time.Time
+	Subject string
+}
+
+// Short returns the abbreviated SHA of the commit.
+func (commit Commit) Short() string {
+	if len(commit.SHA) > 7 {
+		return commit.SHA[:7]
+	}
+	return commit.SHA
+}
+
+// Commits returns the commits reachable from to but not from from, oldest first, following
+// first parents only. An empty from means the whole history of to.
+func (repository *Repository) Commits(from string, to string) ([]Commit, error) {
+	rangeSpec := to
+	if from != "" {
+		rangeSpec = from + ".." + to
+	}
+	out, err := repository.git("log", "--reverse", "--first-parent", "--format="+commitFormat, rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line != "" {
+			commits = append(commits, parseCommit(line))
+		}
+	}
+	return commits, nil
+}
+
+// Commit returns the commit rev points to.
+func (repository *Repository) Commit(rev string) (Commit, error) {
+	sha, err := repository.ResolveRevision(rev)
+	if err != nil {
+		return Commit{}, err
+	}
+	out, err := repository.git("log", "-1", "--format="+commitFormat, sha)
+	if err != nil {
+		return Commit{}, err
+	}
+	return parseCommit(strings.TrimRight(out, "\n")), nil
+}
+
+const commitFormat = "%H %P%x00%at%x00%s"
+
+func parseCommit(line string) Commit {
+	fields := strings.SplitN(line, "\x00", 3)
+	shas := strings.Fields(fields[0])
+	commit := Commit{SHA: shas[0]}
+	if len(shas) > 1 {
+		commit.Parent = shas[1]
+	}
+	if len(fields) > 1 {
+		seconds, _ := strconv.ParseInt(fields[1], 10, 64)
+		commit.Time = time.Unix(seconds, 0).UTC()
+	}
+	if len(fields) > 2 {
+		commit.Subject = fields[2]
+	}
+	return commit
+}
+
+// FileChange is a file changed by a commit. Old and New are the contents before and after the
+// commit. Result is the comparison of Old and New, and is nil for added and deleted files.
+type FileChange struct {
+	Change
+	Old    string
+	New    string
+	Result *lhdiff.Result
+}
+
+// Walk calls visit for each commit between from and to (see Commits) with the files changed
+// by the commit, compared with lhdiff.
+func (repository *Repository) Walk(from string, to string, visit func(Commit, []FileChange) error, opts ...lhdiff.Option) error {
+	commits, err := repository.Commits(from, to)
+	if err != nil {
+		return err
+	}
+	for _, commit := range commits {
+		fileChanges, err := repository.FileChanges(commit, opts...)
+		if err != nil {
+			return err
+		}
+		if err := visit(commit, fileChanges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileChanges returns the files changed by commit compared to its parent.
+func (repository *Repository) FileChanges(commit Commit, opts ...lhdiff.Option) ([]FileChange, error) {
+	parent := commit.Parent
+	if parent == "" {
+		parent = emptyTree
+	}
+	changes, err := repository.Diff(parent, commit.SHA)
+	if err != nil {
+		return nil, err
+	}
+	var fileChanges []FileChange
+	for _, change := range changes {
+		fileChange := FileChange{Change: change}
+		if change.OldPath != "" {
+			fileChange.Old, err = repository.Show(parent, change.OldPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if change.NewPath != "" {
+			fileChange.New, err = repository.Show(commit.SHA, change.NewPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if change.OldPath != "" && change.NewPath != "" {
+			fileChange.Result, err = lhdiff.Compare(fileChange.Old, fileChange.New, append([]lhdiff.Option{lhdiff.WithPath(change.NewPath)}, opts...)...)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fileChanges = append(fileChanges, fileChange)
+	}
+	return fileChanges, nil
+}
+
+// Files returns the paths of the files at rev.
+func (repository *Repository) Files(rev string) ([]string, error) {
+	out, err := repository.git("ls-tree", "-r", "-z", "--name-only", rev)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,116 @@
+package review
+
+import (
+	"encoding/json"
+	"github.com/SmartBear/lhdiff"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Bitbucket is a ReviewProvider for a pull request on Bitbucket Cloud. Versions are commit
+// SHAs. Requests are authenticated with Token (an access token) if it is set, and with
+// Username and Password (an app password) otherwise.
+type Bitbucket struct {
+	URL         string
+	Username    string
+	Password    string
+	Token       string
+	Workspace   string
+	Repository  string
+	PullRequest int
+	Client      *http.Client
+}
+
+type bitbucketInline struct {
+	Path string `json:"path"`
+	To   *int   `json:"to,omitempty"`
+}
+
+type bitbucketComment struct {
+	ID      int `json:"id,omitempty"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline  *bitbucketInline `json:"inline,omitempty"`
+	Parent  *struct{}        `json:"parent,omitempty"`
+	Deleted bool             `json:"deleted,omitempty"`
+}
+
+// Comments returns the inline comments starting a thread on the new side of the diff.
+// Bitbucket doesn't record which commit a comment was made on, so the comments are returned
+// whatever the version.
+func (bitbucket *Bitbucket) Comments(version string) ([]Comment, error) {
+	var comments []Comment
+	for next := bitbucket.url("/comments?pagelen=100"); next != ""; {
+		data, _, err := send(bitbucket.Client, "GET", next, nil, bitbucket.authorize)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Values []bitbucketComment `json:"values"`
+			Next   string             `json:"next"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, c := range page.Values {
+			if c.Inline == nil || c.Inline.To == nil || c.Parent != nil || c.Deleted {
+				continue
+			}
+			comments = append(comments, Comment{ID: strconv.Itoa(c.ID), Path: c.Inline.Path, Line: *c.Inline.To, Message: c.Content.Raw})
+		}
+		next = page.Next
+	}
+	return comments, nil
+}
+
+// Snapshot returns the files at a commit.
+func (bitbucket *Bitbucket) Snapshot(version string) lhdiff.Snapshot {
+	return bitbucketSnapshot{bitbucket: bitbucket, commit: version}
+}
+
+type bitbucketSnapshot struct {
+	bitbucket *Bitbucket
+	commit    string
+}
+
+func (s bitbucketSnapshot) ReadFile(path string) (string, error) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	data, _, err := send(s.bitbucket.Client, "GET", s.bitbucket.repositoryURL("/src/"+url.PathEscape(s.commit)+"/"+strings.Join(segments, "/")), nil, s.bitbucket.authorize)
+	return string(data), err
+}
+
+// Write adds a new inline comment with the comment's message. Bitbucket positions comments
+// on the current diff of the pull request, so version must be its current head.
+func (bitbucket *Bitbucket) Write(version string, remapping Remapping) error {
+	line := remapping.New.Line
+	comment := bitbucketComment{Inline: &bitbucketInline{Path: remapping.New.Path, To: &line}}
+	comment.Content.Raw = remapping.New.Message
+	_, _, err := send(bitbucket.Client, "POST", bitbucket.url("/comments"), comment, bitbucket.authorize)
+	return err
+}
+
+func (bitbucket *Bitbucket) url(path string) string {
+	return bitbucket.repositoryURL("/pullrequests/" + strconv.Itoa(bitbucket.PullRequest) + path)
+}
+
+func (bitbucket *Bitbucket) repositoryURL(path string) string {
+	base := bitbucket.URL
+	if base == "" {
+		base = "https://api.bitbucket.org/2.0"
+	}
+	return strings.TrimSuffix(base, "/") + "/repositories/" + url.PathEscape(bitbucket.Workspace) + "/" + url.PathEscape(bitbucket.Repository) + path
+}
+
+func (bitbucket *Bitbucket) authorize(req *http.Request) {
+	if bitbucket.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+bitbucket.Token)
+	} else if bitbucket.Username != "" {
+		req.SetBasicAuth(bitbucket.Username, bitbucket.Password)
+	}
+}
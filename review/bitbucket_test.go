@@ -0,0 +1,53 @@
+package review
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleBitbucket() {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/repositories/team/app"
+		switch r.URL.Path {
+		case prefix + "/pullrequests/3/comments":
+			if r.Method == "POST" {
+				body, _ := ioutil.ReadAll(r.Body)
+				fmt.Printf("POST %s\n", body)
+				return
+			}
+			if r.URL.Query().Get("page") == "" {
+				_, _ = fmt.Fprintf(w, `{"values": [
+  {"id": 1, "content": {"raw": "Rename this"}, "inline": {"path": "lib/util.py", "to": 2}},
+  {"id": 2, "content": {"raw": "Agreed"}, "inline": {"path": "lib/util.py", "to": 2}, "parent": {"id": 1}}
+], "next": "%s%s/pullrequests/3/comments?pagelen=100&page=2"}`, server.URL, prefix)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"values": [
+  {"id": 3, "content": {"raw": "Was this removed?"}, "inline": {"path": "lib/util.py", "from": 5}}
+]}`)
+		case prefix + "/src/abc/lib/util.py":
+			_, _ = fmt.Fprint(w, "import os\ndef f(x):\n    return x\n")
+		case prefix + "/src/def/lib/util.py":
+			_, _ = fmt.Fprint(w, "import os\nimport sys\n\ndef f(x):\n    return x\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	bitbucket := &Bitbucket{URL: server.URL, Workspace: "team", Repository: "app", PullRequest: 3}
+	remappings, err := Sync(bitbucket, "abc", "def", true)
+	if err != nil {
+		panic(err)
+	}
+	for _, remapping := range remappings {
+		fmt.Printf("%s %s:%d -> %d\n", remapping.Old.ID, remapping.Old.Path, remapping.Old.Line, remapping.New.Line)
+	}
+
+	// Output:
+	// POST {"content":{"raw":"Rename this"},"inline":{"path":"lib/util.py","to":4}}
+	// 1 lib/util.py:2 -> 4
+}
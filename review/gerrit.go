@@ -0,0 +1,128 @@
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/SmartBear/lhdiff"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Gerrit is a ReviewProvider for a change on a Gerrit server. Versions are patchset numbers.
+// Requests are authenticated with HTTP basic authentication (using the HTTP password) if
+// Username is set.
+type Gerrit struct {
+	URL      string
+	Username string
+	Password string
+	Change   string
+	Client   *http.Client
+}
+
+type gerritRange struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+type gerritComment struct {
+	ID        string       `json:"id,omitempty"`
+	PatchSet  int          `json:"patch_set,omitempty"`
+	Path      string       `json:"path,omitempty"`
+	Side      string       `json:"side,omitempty"`
+	Line      int          `json:"line,omitempty"`
+	Range     *gerritRange `json:"range,omitempty"`
+	InReplyTo string       `json:"in_reply_to,omitempty"`
+	Message   string       `json:"message"`
+}
+
+// Comments returns the published comments on the files of a patchset, in path order.
+// Comments on the parent side of a diff and patchset-level comments are left out, since they
+// aren't attached to the patchset's files.
+func (gerrit *Gerrit) Comments(patchSet string) ([]Comment, error) {
+	data, err := gerrit.get("/changes/" + url.PathEscape(gerrit.Change) + "/comments")
+	if err != nil {
+		return nil, err
+	}
+	var byPath map[string][]gerritComment
+	if err := json.Unmarshal(data, &byPath); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var comments []Comment
+	for _, path := range paths {
+		if strings.HasPrefix(path, "/") {
+			// Magic files such as /COMMIT_MSG and /PATCHSET_LEVEL
+			continue
+		}
+		for _, c := range byPath[path] {
+			if strconv.Itoa(c.PatchSet) != patchSet || c.Side == "PARENT" {
+				continue
+			}
+			comment := Comment{ID: c.ID, Path: path, Line: c.Line, Message: c.Message}
+			if c.Range != nil {
+				comment.Range = &Range{StartLine: c.Range.StartLine, StartCharacter: c.Range.StartCharacter, EndLine: c.Range.EndLine, EndCharacter: c.Range.EndCharacter}
+			}
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+// Snapshot returns the files of a patchset.
+func (gerrit *Gerrit) Snapshot(patchSet string) lhdiff.Snapshot {
+	return gerritSnapshot{gerrit: gerrit, patchSet: patchSet}
+}
+
+type gerritSnapshot struct {
+	gerrit   *Gerrit
+	patchSet string
+}
+
+func (s gerritSnapshot) ReadFile(path string) (string, error) {
+	data, err := s.gerrit.get("/changes/" + url.PathEscape(s.gerrit.Change) + "/revisions/" + url.PathEscape(s.patchSet) + "/files/" + url.PathEscape(path) + "/content")
+	if err != nil {
+		return "", err
+	}
+	content, err := base64.StdEncoding.DecodeString(string(data))
+	return string(content), err
+}
+
+// Write adds the remapped comment to the patchset as a draft reply to the original comment,
+// since Gerrit doesn't move published comments. The author publishes the drafts.
+func (gerrit *Gerrit) Write(patchSet string, remapping Remapping) error {
+	comment := gerritComment{Path: remapping.New.Path, Line: remapping.New.Line, InReplyTo: remapping.Old.ID, Message: remapping.New.Message}
+	if r := remapping.New.Range; r != nil {
+		comment.Range = &gerritRange{StartLine: r.StartLine, StartCharacter: r.StartCharacter, EndLine: r.EndLine, EndCharacter: r.EndCharacter}
+	}
+	_, _, err := send(gerrit.Client, "PUT", gerrit.url("/changes/"+url.PathEscape(gerrit.Change)+"/revisions/"+url.PathEscape(patchSet)+"/drafts"), comment, gerrit.authorize)
+	return err
+}
+
+func (gerrit *Gerrit) get(path string) ([]byte, error) {
+	data, _, err := send(gerrit.Client, "GET", gerrit.url(path), nil, gerrit.authorize)
+	// JSON responses start with a line preventing XSSI
+	return []byte(strings.TrimPrefix(string(data), ")]}'\n")), err
+}
+
+func (gerrit *Gerrit) url(path string) string {
+	if gerrit.Username != "" {
+		// Authenticated endpoints are below /a/
+		path = "/a" + path
+	}
+	return strings.TrimSuffix(gerrit.URL, "/") + path
+}
+
+func (gerrit *Gerrit) authorize(req *http.Request) {
+	if gerrit.Username != "" {
+		req.SetBasicAuth(gerrit.Username, gerrit.Password)
+	}
+}
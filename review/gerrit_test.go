@@ -0,0 +1,56 @@
+package review
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleGerrit() {
+	files := map[string]string{
+		"/changes/42/revisions/1/files/src%2Fmain.go/content": "package main\n\nfunc main() {\n\trun()\n}\n",
+		"/changes/42/revisions/2/files/src%2Fmain.go/content": "package main\n\nimport \"os\"\n\nfunc main() {\n\trun()\n\tos.Exit(0)\n}\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/changes/42/comments" {
+			_, _ = fmt.Fprint(w, `)]}'
+{
+  "/PATCHSET_LEVEL": [{"id": "c0", "patch_set": 1, "message": "Looks good"}],
+  "src/main.go": [
+    {"id": "c1", "patch_set": 1, "line": 4, "range": {"start_line": 4, "start_character": 1, "end_line": 4, "end_character": 6}, "message": "Handle the error"},
+    {"id": "c2", "patch_set": 1, "line": 1, "side": "PARENT", "message": "On the base"},
+    {"id": "c3", "patch_set": 2, "line": 7, "message": "Newer"}
+  ]
+}`)
+			return
+		}
+		if r.Method == "PUT" {
+			body, _ := ioutil.ReadAll(r.Body)
+			fmt.Printf("%s %s %s\n", r.Method, r.URL.EscapedPath(), body)
+			_, _ = fmt.Fprint(w, ")]}'\n{}")
+			return
+		}
+		content, ok := files[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, base64.StdEncoding.EncodeToString([]byte(content)))
+	}))
+	defer server.Close()
+
+	gerrit := &Gerrit{URL: server.URL, Change: "42"}
+	remappings, err := Sync(gerrit, "1", "2", true)
+	if err != nil {
+		panic(err)
+	}
+	for _, remapping := range remappings {
+		fmt.Printf("%s %s:%d -> %d %v\n", remapping.Old.ID, remapping.Old.Path, remapping.Old.Line, remapping.New.Line, *remapping.New.Range)
+	}
+
+	// Output:
+	// PUT /changes/42/revisions/2/drafts {"path":"src/main.go","line":6,"range":{"start_line":6,"start_character":1,"end_line":6,"end_character":6},"in_reply_to":"c1","message":"Handle the error"}
+	// c1 src/main.go:4 -> 6 {6 1 6 6}
+}
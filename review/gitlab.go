@@ -0,0 +1,179 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GitLab is a ReviewProvider for a merge request on GitLab. Versions are the SHAs of the head
+// commits of the merge request's versions. Project is the ID or path of the project, and
+// Token a personal or project access token.
+type GitLab struct {
+	URL          string
+	Token        string
+	Project      string
+	MergeRequest int
+	Client       *http.Client
+}
+
+type gitlabPosition struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	StartSHA     string `json:"start_sha"`
+	HeadSHA      string `json:"head_sha"`
+	OldPath      string `json:"old_path,omitempty"`
+	NewPath      string `json:"new_path"`
+	OldLine      *int   `json:"old_line,omitempty"`
+	NewLine      *int   `json:"new_line,omitempty"`
+	LineRange    *struct {
+		Start struct {
+			NewLine *int `json:"new_line"`
+		} `json:"start"`
+		End struct {
+			NewLine *int `json:"new_line"`
+		} `json:"end"`
+	} `json:"line_range,omitempty"`
+}
+
+type gitlabDiscussion struct {
+	ID    string `json:"id"`
+	Notes []struct {
+		Type     string          `json:"type"`
+		Body     string          `json:"body"`
+		Position *gitlabPosition `json:"position"`
+	} `json:"notes"`
+}
+
+// Comments returns the threads started on the new side of the diff of a version. Multi-line
+// comments get a range without characters.
+func (gitlab *GitLab) Comments(version string) ([]Comment, error) {
+	var comments []Comment
+	for page := "1"; page != ""; {
+		data, header, err := send(gitlab.Client, "GET", gitlab.url("/discussions?per_page=100&page="+page), nil, gitlab.authorize)
+		if err != nil {
+			return nil, err
+		}
+		var discussions []gitlabDiscussion
+		if err := json.Unmarshal(data, &discussions); err != nil {
+			return nil, err
+		}
+		for _, discussion := range discussions {
+			if len(discussion.Notes) == 0 {
+				continue
+			}
+			note := discussion.Notes[0]
+			position := note.Position
+			if note.Type != "DiffNote" || position == nil || position.HeadSHA != version || position.NewLine == nil {
+				continue
+			}
+			comment := Comment{ID: discussion.ID, Path: position.NewPath, Line: *position.NewLine, Message: note.Body}
+			if r := position.LineRange; r != nil && r.Start.NewLine != nil && r.End.NewLine != nil {
+				comment.Range = &Range{StartLine: *r.Start.NewLine, EndLine: *r.End.NewLine}
+			}
+			comments = append(comments, comment)
+		}
+		page = header.Get("X-Next-Page")
+	}
+	return comments, nil
+}
+
+// Snapshot returns the files at a commit.
+func (gitlab *GitLab) Snapshot(version string) lhdiff.Snapshot {
+	return gitlabSnapshot{gitlab: gitlab, ref: version}
+}
+
+type gitlabSnapshot struct {
+	gitlab *GitLab
+	ref    string
+}
+
+func (s gitlabSnapshot) ReadFile(path string) (string, error) {
+	data, _, err := send(s.gitlab.Client, "GET", s.gitlab.projectURL("/repository/files/"+url.PathEscape(path)+"/raw?ref="+url.QueryEscape(s.ref)), nil, s.gitlab.authorize)
+	return string(data), err
+}
+
+// Write starts a new thread with the comment's message on the version, since GitLab doesn't
+// move existing threads. GitLab positions need the base and start SHAs of the version, and
+// lines that the merge request didn't change also need their line in the base, which is
+// found with lhdiff, unless the merge request added the file. The range of multi-line comments
+// isn't written.
+func (gitlab *GitLab) Write(version string, remapping Remapping) error {
+	position, err := gitlab.position(version)
+	if err != nil {
+		return err
+	}
+	newLine := remapping.New.Line
+	position.NewPath, position.OldPath, position.NewLine = remapping.New.Path, remapping.New.Path, &newLine
+	// A file that the merge request added has no base, and no line in it
+	base, err := gitlab.Snapshot(position.BaseSHA).ReadFile(remapping.New.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		head, err := gitlab.Snapshot(version).ReadFile(remapping.New.Path)
+		if err != nil {
+			return err
+		}
+		result, err := lhdiff.Compare(base, head)
+		if err != nil {
+			return err
+		}
+		if oldLine, similarity, ok := result.LeftLine(newLine - 1); ok && similarity == 1 {
+			oldLine++
+			position.OldLine = &oldLine
+		}
+	}
+	body := struct {
+		Body     string          `json:"body"`
+		Position *gitlabPosition `json:"position"`
+	}{remapping.New.Message, position}
+	_, _, err = send(gitlab.Client, "POST", gitlab.url("/discussions"), body, gitlab.authorize)
+	return err
+}
+
+// position returns the position of the version of the merge request with the given head.
+func (gitlab *GitLab) position(version string) (*gitlabPosition, error) {
+	data, _, err := send(gitlab.Client, "GET", gitlab.url("/versions"), nil, gitlab.authorize)
+	if err != nil {
+		return nil, err
+	}
+	var versions []struct {
+		HeadCommitSHA  string `json:"head_commit_sha"`
+		BaseCommitSHA  string `json:"base_commit_sha"`
+		StartCommitSHA string `json:"start_commit_sha"`
+	}
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.HeadCommitSHA == version {
+			return &gitlabPosition{PositionType: "text", BaseSHA: v.BaseCommitSHA, StartSHA: v.StartCommitSHA, HeadSHA: v.HeadCommitSHA}, nil
+		}
+	}
+	return nil, fmt.Errorf("merge request %d has no version with head %s", gitlab.MergeRequest, version)
+}
+
+func (gitlab *GitLab) url(path string) string {
+	return gitlab.projectURL("/merge_requests/" + strconv.Itoa(gitlab.MergeRequest) + path)
+}
+
+func (gitlab *GitLab) projectURL(path string) string {
+	base := gitlab.URL
+	if base == "" {
+		base = "https://gitlab.com"
+	}
+	return strings.TrimSuffix(base, "/") + "/api/v4/projects/" + url.PathEscape(gitlab.Project) + path
+}
+
+func (gitlab *GitLab) authorize(req *http.Request) {
+	if gitlab.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", gitlab.Token)
+	}
+}
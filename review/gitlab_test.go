@@ -0,0 +1,91 @@
+package review
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleGitLab() {
+	files := map[string]string{
+		"base":  "package main\n\nfunc main() {\n\trun()\n}\n",
+		"head1": "package main\n\nfunc main() {\n\trun()\n\tstop()\n}\n",
+		"head2": "package main\n\nimport \"os\"\n\nfunc main() {\n\trun()\n\tstop()\n}\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/api/v4/projects/group%2Fapp"
+		switch r.URL.EscapedPath() {
+		case prefix + "/merge_requests/7/discussions":
+			if r.Method == "POST" {
+				body, _ := ioutil.ReadAll(r.Body)
+				fmt.Printf("POST %s\n", body)
+				return
+			}
+			_, _ = fmt.Fprint(w, `[
+  {"id": "d1", "notes": [{"type": "DiffNote", "body": "Check the result", "position": {"head_sha": "head1", "new_path": "main.go", "new_line": 4}}]},
+  {"id": "d2", "notes": [{"type": "DiffNote", "body": "Why stop?", "position": {"head_sha": "head1", "new_path": "main.go", "new_line": 5}}]},
+  {"id": "d3", "notes": [{"type": null, "body": "A general remark"}]}
+]`)
+		case prefix + "/merge_requests/7/versions":
+			_, _ = fmt.Fprint(w, `[{"head_commit_sha": "head2", "base_commit_sha": "base", "start_commit_sha": "base"}]`)
+		case prefix + "/repository/files/main.go/raw":
+			_, _ = fmt.Fprint(w, files[r.URL.Query().Get("ref")])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gitlab := &GitLab{URL: server.URL, Project: "group/app", MergeRequest: 7}
+	remappings, err := Sync(gitlab, "head1", "head2", true)
+	if err != nil {
+		panic(err)
+	}
+	for _, remapping := range remappings {
+		fmt.Printf("%s %s:%d -> %d\n", remapping.Old.ID, remapping.Old.Path, remapping.Old.Line, remapping.New.Line)
+	}
+
+	// Output:
+	// POST {"body":"Check the result","position":{"position_type":"text","base_sha":"base","start_sha":"base","head_sha":"head2","old_path":"main.go","new_path":"main.go","old_line":4,"new_line":6}}
+	// POST {"body":"Why stop?","position":{"position_type":"text","base_sha":"base","start_sha":"base","head_sha":"head2","old_path":"main.go","new_path":"main.go","new_line":7}}
+	// d1 main.go:4 -> 6
+	// d2 main.go:5 -> 7
+}
+
+func ExampleGitLab_Write_base() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/api/v4/projects/app"
+		switch r.URL.EscapedPath() {
+		case prefix + "/merge_requests/7/discussions":
+			body, _ := ioutil.ReadAll(r.Body)
+			fmt.Printf("POST %s\n", body)
+		case prefix + "/merge_requests/7/versions":
+			_, _ = fmt.Fprint(w, `[{"head_commit_sha": "head", "base_commit_sha": "base", "start_commit_sha": "base"}]`)
+		case prefix + "/repository/files/new.go/raw", prefix + "/repository/files/main.go/raw":
+			if r.URL.Query().Get("ref") == "head" {
+				_, _ = fmt.Fprint(w, "package main\n")
+			} else if r.URL.EscapedPath() == prefix+"/repository/files/main.go/raw" {
+				http.Error(w, "try again later", http.StatusInternalServerError)
+			} else {
+				http.NotFound(w, r)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// A file added by the merge request has no line in the base, but the base must be read for
+	// the others
+	gitlab := &GitLab{URL: server.URL, Project: "app", MergeRequest: 7}
+	for _, path := range []string{"new.go", "main.go"} {
+		err := gitlab.Write("head", Remapping{New: Comment{Path: path, Line: 1, Message: "Check"}})
+		fmt.Println(err)
+	}
+
+	// Output:
+	// POST {"body":"Check","position":{"position_type":"text","base_sha":"base","start_sha":"base","head_sha":"head","old_path":"new.go","new_path":"new.go","new_line":1}}
+	// <nil>
+	// GET /api/v4/projects/app/repository/files/main.go/raw: 500 Internal Server Error: try again later
+}
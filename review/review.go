@@ -0,0 +1,111 @@
+// Package review remaps inline code review comments between two versions of a change, such as
+// two patchsets or the old and new head of a pull request, so that comments stay attached to
+// the code they were made on.
+package review
+
+import (
+	"github.com/SmartBear/lhdiff"
+)
+
+// Comment is an inline review comment. Line is one-based, and 0 for comments on a whole file.
+// Range is the commented text, if any.
+type Comment struct {
+	ID      string
+	Path    string
+	Line    int
+	Range   *Range
+	Message string
+}
+
+// Range is a commented range of text. Lines are one-based and characters zero-based.
+type Range struct {
+	StartLine      int
+	StartCharacter int
+	EndLine        int
+	EndCharacter   int
+}
+
+// Remapping is a comment remapped to the new version. New is the zero Comment if the
+// commented line was deleted.
+type Remapping struct {
+	Old        Comment
+	New        Comment
+	Deleted    bool
+	Similarity float64
+}
+
+// Remap remaps comments made on the old snapshot to the new snapshot. Comments on whole files
+// are kept as-is. The range of a comment is kept if its first and last lines are unchanged,
+// and moved along with them. Otherwise the comment is remapped to a line, without a range.
+func Remap(comments []Comment, old lhdiff.Snapshot, new lhdiff.Snapshot, opts ...lhdiff.Option) ([]Remapping, error) {
+	remapper := lhdiff.NewRemapper(old, new, opts...)
+	remappings := make([]Remapping, len(comments))
+	for i, comment := range comments {
+		remappings[i] = Remapping{Old: comment, New: comment, Similarity: 1}
+		if comment.Line == 0 {
+			continue
+		}
+		line, similarity, ok, err := remapper.Remap(comment.Path, comment.Line-1)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			remappings[i] = Remapping{Old: comment, Deleted: true}
+			continue
+		}
+		remappings[i].New.Line = line + 1
+		remappings[i].New.Range = nil
+		remappings[i].Similarity = similarity
+		if comment.Range == nil {
+			continue
+		}
+		start, startSimilarity, startOk, err := remapper.Remap(comment.Path, comment.Range.StartLine-1)
+		if err != nil {
+			return nil, err
+		}
+		end, endSimilarity, endOk, err := remapper.Remap(comment.Path, comment.Range.EndLine-1)
+		if err != nil {
+			return nil, err
+		}
+		if startOk && endOk && startSimilarity == 1 && endSimilarity == 1 && start <= end {
+			commentRange := *comment.Range
+			commentRange.StartLine, commentRange.EndLine = start+1, end+1
+			remappings[i].New.Range = &commentRange
+		}
+	}
+	return remappings, nil
+}
+
+// ReviewProvider is a code review host, such as Gerrit, GitLab or Bitbucket. It hides how
+// the host identifies versions of a change and positions comments on them. Versions are the
+// host's identifiers of the versions of a change, such as patchset numbers or commit SHAs.
+type ReviewProvider interface {
+	// Comments returns the inline comments made on a version.
+	Comments(version string) ([]Comment, error)
+	// Snapshot returns the files of a version.
+	Snapshot(version string) lhdiff.Snapshot
+	// Write adds a remapped comment to a version, at the position the host expects.
+	Write(version string, remapping Remapping) error
+}
+
+// Sync remaps the comments made on version from to version to. If write is true, the
+// comments whose lines weren't deleted are written to version to.
+func Sync(provider ReviewProvider, from string, to string, write bool, opts ...lhdiff.Option) ([]Remapping, error) {
+	comments, err := provider.Comments(from)
+	if err != nil {
+		return nil, err
+	}
+	remappings, err := Remap(comments, provider.Snapshot(from), provider.Snapshot(to), opts...)
+	if err != nil || !write {
+		return remappings, err
+	}
+	for _, remapping := range remappings {
+		if remapping.Deleted {
+			continue
+		}
+		if err := provider.Write(to, remapping); err != nil {
+			return nil, err
+		}
+	}
+	return remappings, nil
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"errors"
+	"github.com/SmartBear/lhdiff"
+	"net"
+)
+
+// ServeListener accepts connections on listener until it is closed, and serves the requests of
+// each connection with its own Server comparing buffers with opts, so that clients don't see
+// each other's buffers. Clients share the results of a Cache in opts, such as a
+// lhdiff.MemoryCache. A client calling shutdown only closes its connection.
+func ServeListener(listener net.Listener, opts ...lhdiff.Option) error {
+	return serveListener(listener, nil, opts)
+}
+
+// ServeWorkspace is like ServeListener, with servers translating the positions of the files of
+// workspace as well.
+func ServeWorkspace(listener net.Listener, workspace *Workspace, opts ...lhdiff.Option) error {
+	return serveListener(listener, workspace, opts)
+}
+
+func serveListener(listener net.Listener, workspace *Workspace, opts []lhdiff.Option) error {
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s := New(opts...)
+			s.workspace = workspace
+			_ = s.Serve(conn, conn)
+		}()
+	}
+}
@@ -0,0 +1,287 @@
+// Package server implements a JSON-RPC 2.0 server that editors and extensions can use to
+// translate positions between two versions of a buffer.
+//
+// Messages are framed with a Content-Length header, as in the Language Server Protocol, on
+// stdin/stdout (see Server.Serve) or on the connections of a socket (see ServeListener).
+// The supported methods are:
+//
+//	load       {"uri", "left", "right", "contextSize"}  compares two buffers
+//	           {"leftObject", "rightObject", "output"}  reads them from, or writes the mapping
+//	                                                    to, s3:// and gs:// objects
+//	translate  {"uri", "direction", "positions"}          translates positions
+//	close      {"uri"}                                    forgets the buffers
+//	rebase     {"uri"}                                    makes the version on disk of a
+//	                                                      workspace file its baseline
+//	shutdown                                              stops serving
+//
+// Servers of a Workspace (see ServeWorkspace) translate the positions of the files below its
+// directory without loading them first.
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"github.com/SmartBear/lhdiff/objectstore"
+	"io"
+	"strings"
+)
+
+// Position is a zero-based line and character offset in a buffer. As in the Language Server
+// Protocol, characters are counted in UTF-16 code units, so that a character outside the Basic
+// Multilingual Plane, such as an emoji, counts as two.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LoadParams are the parameters of the load method.
+type LoadParams struct {
+	URI         string `json:"uri"`
+	Left        string `json:"left"`
+	Right       string `json:"right"`
+	ContextSize int    `json:"contextSize,omitempty"`
+	// LeftObject and RightObject are s3:// or gs:// references of objects that are read instead
+	// of Left and Right, and Output is one that the Result is written to as JSON (see the
+	// objectstore package)
+	LeftObject  string `json:"leftObject,omitempty"`
+	RightObject string `json:"rightObject,omitempty"`
+	Output      string `json:"output,omitempty"`
+}
+
+// TranslateParams are the parameters of the translate method. Direction is either
+// "leftToRight" (the default) or "rightToLeft".
+type TranslateParams struct {
+	URI       string     `json:"uri"`
+	Direction string     `json:"direction,omitempty"`
+	Positions []Position `json:"positions"`
+}
+
+// Translation is the translation of a single position. Position is nil if the line has no
+// counterpart in the other buffer.
+type Translation struct {
+	Position   *Position `json:"position"`
+	Similarity float64   `json:"similarity"`
+}
+
+// CloseParams are the parameters of the close method.
+type CloseParams struct {
+	URI string `json:"uri"`
+}
+
+type document struct {
+	leftLines  []string
+	rightLines []string
+	result     *lhdiff.Result
+}
+
+// Server holds the buffers loaded by a client.
+type Server struct {
+	documents map[string]*document
+	opts      []lhdiff.Option
+	workspace *Workspace
+}
+
+// New returns a server comparing buffers with opts. The contextSize of a load request
+// overrides the context size in opts.
+func New(opts ...lhdiff.Option) *Server {
+	return &Server{documents: make(map[string]*document), opts: opts}
+}
+
+var errShutdown = errors.New("shutdown")
+
+// Serve reads requests from r and writes responses to w until r is exhausted or the client
+// calls shutdown.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	for {
+		body, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		var result interface{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			err = writeMessage(w, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: ParseError, Message: err.Error()}})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		result, err = s.handle(req.Method, req.Params)
+		if req.ID == nil {
+			// Notifications don't get a response
+			if err == errShutdown {
+				return nil
+			}
+			continue
+		}
+		res := response{JSONRPC: "2.0", ID: req.ID, Result: result}
+		if err != nil && err != errShutdown {
+			rpcErr, ok := err.(*Error)
+			if !ok {
+				rpcErr = &Error{Code: InternalError, Message: err.Error()}
+			}
+			res.Result, res.Error = nil, rpcErr
+		}
+		if res.Result == nil && res.Error == nil {
+			res.Result = json.RawMessage("null")
+		}
+		if werr := writeMessage(w, res); werr != nil {
+			return werr
+		}
+		if err == errShutdown {
+			return nil
+		}
+	}
+}
+
+func (s *Server) handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "load":
+		var p LoadParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.Load(p)
+	case "translate":
+		var p TranslateParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.Translate(p)
+	case "close":
+		var p CloseParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		delete(s.documents, p.URI)
+		return nil, nil
+	case "rebase":
+		var p CloseParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		if s.workspace == nil {
+			return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("not in the workspace: %s", p.URI)}
+		}
+		return nil, s.workspace.Rebase(p.URI)
+	case "shutdown":
+		return nil, errShutdown
+	default:
+		return nil, &Error{Code: MethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+	}
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: InvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// Load compares the left and right buffers and remembers the result under p.URI.
+func (s *Server) Load(p LoadParams) error {
+	for _, object := range []struct {
+		ref     string
+		content *string
+	}{{p.LeftObject, &p.Left}, {p.RightObject, &p.Right}} {
+		if object.ref == "" {
+			continue
+		}
+		data, err := objectstore.Get(object.ref)
+		if err != nil {
+			return err
+		}
+		*object.content = string(data)
+	}
+	opts := append([]lhdiff.Option{}, s.opts...)
+	if p.ContextSize > 0 {
+		opts = append(opts, lhdiff.WithContextSize(p.ContextSize))
+	}
+	result, err := lhdiff.Compare(p.Left, p.Right, opts...)
+	if err != nil {
+		return err
+	}
+	if p.Output != "" {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		if err := objectstore.Put(p.Output, data); err != nil {
+			return err
+		}
+	}
+	s.documents[p.URI] = &document{
+		leftLines:  strings.Split(p.Left, "\n"),
+		rightLines: strings.Split(p.Right, "\n"),
+		result:     result,
+	}
+	return nil
+}
+
+// Translate translates positions from one buffer to the other. The character offset is kept,
+// but clamped to the length of the translated line in UTF-16 code units. Files of the workspace that aren't loaded
+// are translated from their baselines to their versions on disk.
+func (s *Server) Translate(p TranslateParams) ([]Translation, error) {
+	doc, ok := s.documents[p.URI]
+	if !ok && s.workspace != nil {
+		var err error
+		if doc, err = s.workspace.document(p.URI); err != nil {
+			return nil, err
+		}
+		ok = true
+	}
+	if !ok {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("not loaded: %s", p.URI)}
+	}
+	var lookup func(int) (int, float64, bool)
+	var targetLines []string
+	switch p.Direction {
+	case "", "leftToRight":
+		lookup, targetLines = doc.result.RightLine, doc.rightLines
+	case "rightToLeft":
+		lookup, targetLines = doc.result.LeftLine, doc.leftLines
+	default:
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("invalid direction: %s", p.Direction)}
+	}
+	translations := make([]Translation, len(p.Positions))
+	for i, position := range p.Positions {
+		line, similarity, ok := lookup(position.Line)
+		if !ok {
+			continue
+		}
+		character := position.Character
+		length := utf16Length(strings.TrimSuffix(targetLines[line], "\r"))
+		if character > length {
+			character = length
+		}
+		if character < 0 {
+			character = 0
+		}
+		translations[i] = Translation{
+			Position:   &Position{Line: line, Character: character},
+			Similarity: similarity,
+		}
+	}
+	return translations, nil
+}
+
+// utf16Length returns the number of UTF-16 code units of text.
+func utf16Length(text string) int {
+	length := 0
+	for _, r := range text {
+		// Characters outside the Basic Multilingual Plane are encoded as surrogate pairs
+		if r > 0xFFFF {
+			length += 2
+		} else {
+			length++
+		}
+	}
+	return length
+}
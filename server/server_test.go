@@ -0,0 +1,179 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func ExampleServer_Serve() {
+	var in bytes.Buffer
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"load","params":{"uri":"file:///a.txt","left":"one\ntwo\nthree\nfour\n","right":"zero\none\nthree\ntwo\nfour\n"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"translate","params":{"uri":"file:///a.txt","positions":[{"line":1,"character":2},{"line":3,"character":9}]}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"translate","params":{"uri":"file:///a.txt","direction":"rightToLeft","positions":[{"line":0,"character":0}]}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"nope"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`,
+	} {
+		_, _ = fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var out bytes.Buffer
+	err := New().Serve(&in, &out)
+	if err != nil {
+		panic(err)
+	}
+	for _, message := range strings.Split(out.String(), "Content-Length: ")[1:] {
+		fmt.Println(message[strings.Index(message, "{"):])
+	}
+
+	// Output:
+	// {"jsonrpc":"2.0","id":1,"result":null}
+	// {"jsonrpc":"2.0","id":2,"result":[{"position":{"line":3,"character":2},"similarity":1},{"position":{"line":4,"character":4},"similarity":1}]}
+	// {"jsonrpc":"2.0","id":3,"result":[{"position":null,"similarity":0}]}
+	// {"jsonrpc":"2.0","id":4,"error":{"code":-32601,"message":"method not found: nope"}}
+	// {"jsonrpc":"2.0","id":5,"result":null}
+}
+
+func ExampleServer_Serve_malformed() {
+	for _, in := range []string{
+		"Content-Length: -5\r\n\r\n",
+		"Content-Length: 1000000000000\r\n\r\n{}",
+	} {
+		var out bytes.Buffer
+		fmt.Println(New().Serve(strings.NewReader(in), &out))
+	}
+
+	var in bytes.Buffer
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"load","params":{"uri":"file:///a.txt","left":"one\ntwo\n","right":"one\ntwo\n"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"translate","params":{"uri":"file:///a.txt","positions":[{"line":-1,"character":0},{"line":1,"character":-3},{"line":9,"character":0}]}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"translate","params":{"uri":"file:///b.txt","positions":[]}}`,
+	} {
+		_, _ = fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	var out bytes.Buffer
+	fmt.Println(New().Serve(&in, &out))
+	for _, message := range strings.Split(out.String(), "Content-Length: ")[1:] {
+		fmt.Println(message[strings.Index(message, "{"):])
+	}
+
+	// Output:
+	// invalid Content-Length header: -5
+	// unexpected EOF
+	// <nil>
+	// {"jsonrpc":"2.0","id":1,"result":null}
+	// {"jsonrpc":"2.0","id":2,"result":[{"position":null,"similarity":0},{"position":{"line":1,"character":0},"similarity":1},{"position":null,"similarity":0}]}
+	// {"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"not loaded: file:///b.txt"}}
+}
+
+func ExampleServer_Translate_utf16() {
+	server := New()
+	err := server.Load(LoadParams{URI: "file:///a.txt", Left: "say hello 👋\n", Right: "first\nsay hello 👋\n"})
+	if err != nil {
+		panic(err)
+	}
+	// The emoji is two UTF-16 code units, so the end of the line is at 12
+	translations, err := server.Translate(TranslateParams{URI: "file:///a.txt", Positions: []Position{{Line: 0, Character: 20}}})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(*translations[0].Position)
+
+	// Output:
+	// {1 12}
+}
+
+func ExampleServeListener() {
+	dir, err := ioutil.TempDir("", "lhdiffd")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	listener, err := net.Listen("unix", filepath.Join(dir, "lhdiffd.sock"))
+	if err != nil {
+		panic(err)
+	}
+	done := make(chan error)
+	go func() { done <- ServeListener(listener, lhdiff.WithCache(lhdiff.NewMemoryCache(100))) }()
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", filepath.Join(dir, "lhdiffd.sock"))
+		if err != nil {
+			panic(err)
+		}
+		reader := bufio.NewReader(conn)
+		for _, body := range []string{
+			`{"jsonrpc":"2.0","id":1,"method":"load","params":{"uri":"file:///a.txt","left":"one\ntwo\n","right":"zero\none\ntwo\n"}}`,
+			`{"jsonrpc":"2.0","id":2,"method":"translate","params":{"uri":"file:///a.txt","positions":[{"line":1,"character":0}]}}`,
+		} {
+			_, _ = fmt.Fprintf(conn, "Content-Length: %d\r\n\r\n%s", len(body), body)
+			message, err := readMessage(reader)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(message))
+		}
+		_ = conn.Close()
+	}
+	_ = listener.Close()
+	fmt.Println(<-done)
+
+	// Output:
+	// {"jsonrpc":"2.0","id":1,"result":null}
+	// {"jsonrpc":"2.0","id":2,"result":[{"position":{"line":2,"character":0},"similarity":1}]}
+	// {"jsonrpc":"2.0","id":1,"result":null}
+	// {"jsonrpc":"2.0","id":2,"result":[{"position":{"line":2,"character":0},"similarity":1}]}
+	// <nil>
+}
+
+func ExampleWorkspace() {
+	dir, err := ioutil.TempDir("", "lhdiff-workspace")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.txt")
+	if err := ioutil.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		panic(err)
+	}
+	workspace, err := NewWorkspace(dir)
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("zero\none\ntwo\nthree\n"), 0644); err != nil {
+		panic(err)
+	}
+
+	var in bytes.Buffer
+	uri := "file://" + filepath.ToSlash(path)
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"translate","params":{"uri":"` + uri + `","positions":[{"line":1,"character":2}]}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"rebase","params":{"uri":"` + uri + `"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"translate","params":{"uri":"` + uri + `","positions":[{"line":1,"character":2}]}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"translate","params":{"uri":"file:///elsewhere.txt","positions":[]}}`,
+	} {
+		_, _ = fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	var out bytes.Buffer
+	s := New()
+	s.workspace = workspace
+	if err := s.Serve(&in, &out); err != nil {
+		panic(err)
+	}
+	for _, message := range strings.Split(out.String(), "Content-Length: ")[1:] {
+		fmt.Println(message[strings.Index(message, "{"):])
+	}
+
+	// Output:
+	// {"jsonrpc":"2.0","id":1,"result":[{"position":{"line":2,"character":2},"similarity":1}]}
+	// {"jsonrpc":"2.0","id":2,"result":null}
+	// {"jsonrpc":"2.0","id":3,"result":[{"position":{"line":1,"character":2},"similarity":1}]}
+	// {"jsonrpc":"2.0","id":4,"error":{"code":-32602,"message":"not loaded: file:///elsewhere.txt"}}
+}
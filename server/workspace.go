@@ -0,0 +1,257 @@
+package server
+
+import (
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxWorkspaceFileSize is the size of the largest file that a Workspace tracks.
+const MaxWorkspaceFileSize = 1 << 20
+
+// WorkspaceCacheSize is the number of comparison results that a Workspace keeps in memory, so
+// that a file going back to an earlier version, as with an undo, isn't compared again.
+const WorkspaceCacheSize = 1000
+
+// Workspace tracks the text files below a directory as they change on disk, so that translate
+// requests for their file:// URIs are answered without loading them first. The first version of
+// a file that the workspace sees is its baseline, until rebase is called. Left positions are
+// those of the baseline, and right positions those of the file on disk.
+//
+// What answering takes is kept warm in memory: the normalized lines of the baselines, a
+// lhdiff.Vectorizer fitted on them, and the mapping of each file to its version on disk, which
+// is refreshed when the file changes (see Watch), and before answering if Watch hasn't noticed
+// the change yet.
+type Workspace struct {
+	root  string
+	opts  []lhdiff.Option
+	mutex sync.Mutex
+	files map[string]*watchedFile
+	stop  chan struct{}
+}
+
+// watchedFile is a file of a workspace. Binary and too large files are skipped, but watched so
+// that they are read again if they change.
+type watchedFile struct {
+	mutex    sync.Mutex
+	modTime  time.Time
+	size     int64
+	skipped  bool
+	base     version
+	current  version
+	document *document
+}
+
+// version is the text of a file split into lines, as they are and normalized.
+type version struct {
+	text       string
+	lines      []string
+	normalized []string
+}
+
+func newVersion(text string) version {
+	return version{text: text, lines: strings.Split(text, "\n"), normalized: lhdiff.ConvertToLinesWithoutNewLine(text)}
+}
+
+// NewWorkspace reads the files below root as the baselines of the workspace. Files are compared
+// with opts, after a cache of WorkspaceCacheSize results and the Vectorizer of the baselines,
+// which opts can replace. Directories whose names start with a dot, such as .git, are skipped.
+func NewWorkspace(root string, opts ...lhdiff.Option) (*Workspace, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	workspace := &Workspace{root: root, files: make(map[string]*watchedFile)}
+	if err := workspace.Scan(); err != nil {
+		return nil, err
+	}
+	var baselines []string
+	for _, file := range workspace.files {
+		baselines = append(baselines, file.base.text)
+	}
+	// The files of the first scan are compared when they are first translated or changed
+	workspace.opts = append([]lhdiff.Option{lhdiff.WithCache(lhdiff.NewMemoryCache(WorkspaceCacheSize)), lhdiff.WithVectorizer(lhdiff.NewVectorizer(baselines...))}, opts...)
+	return workspace, nil
+}
+
+// Watch scans the workspace every interval (see Scan) until Close is called.
+func (workspace *Workspace) Watch(interval time.Duration) {
+	workspace.mutex.Lock()
+	if workspace.stop != nil {
+		workspace.mutex.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	workspace.stop = stop
+	workspace.mutex.Unlock()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = workspace.Scan()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops watching the workspace.
+func (workspace *Workspace) Close() {
+	workspace.mutex.Lock()
+	defer workspace.mutex.Unlock()
+	if workspace.stop != nil {
+		close(workspace.stop)
+		workspace.stop = nil
+	}
+}
+
+// Scan compares the files that changed since the last scan with their baselines, starts
+// tracking the files that were created, and forgets those that were deleted.
+func (workspace *Workspace) Scan() error {
+	seen := make(map[string]bool)
+	err := filepath.Walk(workspace.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// A file deleted during the walk is forgotten
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			if path != workspace.root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		seen[path] = true
+		workspace.file(path, info)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	workspace.mutex.Lock()
+	defer workspace.mutex.Unlock()
+	for path := range workspace.files {
+		if !seen[path] {
+			delete(workspace.files, path)
+		}
+	}
+	return nil
+}
+
+// file returns the tracked file at path, refreshed if info tells it changed.
+func (workspace *Workspace) file(path string, info os.FileInfo) *watchedFile {
+	workspace.mutex.Lock()
+	file, exists := workspace.files[path]
+	if !exists {
+		file = &watchedFile{}
+		workspace.files[path] = file
+	}
+	opts := workspace.opts
+	workspace.mutex.Unlock()
+
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if exists && info.ModTime().Equal(file.modTime) && info.Size() == file.size {
+		return file
+	}
+	file.modTime, file.size = info.ModTime(), info.Size()
+	file.skipped, file.document = true, nil
+	if info.Size() > MaxWorkspaceFileSize {
+		return file
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || lhdiff.IsBinary(string(data)) {
+		return file
+	}
+	file.skipped = false
+	file.current = newVersion(string(data))
+	if !exists || file.base.lines == nil {
+		file.base = file.current
+	}
+	if opts != nil {
+		// The mapping is kept warm, so that translations don't wait for it
+		_ = file.refresh(opts)
+	}
+	return file
+}
+
+// refresh compares the baseline of the file with its current version, if it hasn't been yet.
+// It must be called with the mutex of the file held.
+func (file *watchedFile) refresh(opts []lhdiff.Option) error {
+	if file.document != nil {
+		return nil
+	}
+	result, err := lhdiff.LhdiffLines(file.base.normalized, file.current.normalized, opts...)
+	if err != nil {
+		return err
+	}
+	file.document = &document{leftLines: file.base.lines, rightLines: file.current.lines, result: result}
+	return nil
+}
+
+// path returns the path of the file of a file:// URI, and false if it isn't below the root.
+func (workspace *Workspace) path(uri string) (string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return "", false
+	}
+	path := filepath.FromSlash(parsed.Path)
+	relative, err := filepath.Rel(workspace.root, path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return path, true
+}
+
+// document returns the mapping of the baseline of the file of uri to its version on disk.
+func (workspace *Workspace) document(uri string) (*document, error) {
+	return workspace.update(uri, func(file *watchedFile) {})
+}
+
+// Rebase makes the version on disk of the file of uri its baseline.
+func (workspace *Workspace) Rebase(uri string) error {
+	_, err := workspace.update(uri, func(file *watchedFile) {
+		file.base, file.document = file.current, nil
+	})
+	return err
+}
+
+// update refreshes the file of uri, calls change with its mutex held, and returns its mapping.
+func (workspace *Workspace) update(uri string, change func(*watchedFile)) (*document, error) {
+	path, ok := workspace.path(uri)
+	if !ok {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("not loaded: %s", uri)}
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("not in the workspace: %s", uri)}
+	}
+	file := workspace.file(path, info)
+	workspace.mutex.Lock()
+	opts := workspace.opts
+	workspace.mutex.Unlock()
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.skipped {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("not a text file: %s", uri)}
+	}
+	change(file)
+	if err := file.refresh(opts); err != nil {
+		return nil, err
+	}
+	return file.document, nil
+}
@@ -0,0 +1,50 @@
+package lhdiff
+
+// Scores are the similarities of two lines. Combined is the similarity lhdiff pairs lines by:
+// Content and Context weighted by the factors of the options, and 0 if Content isn't above
+// the content gate (see WithContentGate).
+type Scores struct {
+	Content  float64
+	Context  float64
+	Combined float64
+}
+
+// Surroundings are the lines around a line, nearest last for Before and nearest first for After.
+type Surroundings struct {
+	Before []string
+	After  []string
+}
+
+// WithSurroundings gives Similarity the lines around the left and the right line, which their
+// contexts are made of. It is ignored by the functions comparing files.
+func WithSurroundings(left Surroundings, right Surroundings) Option {
+	return func(o *options) {
+		o.surroundings = []Surroundings{left, right}
+	}
+}
+
+// Similarity returns the scores of the lines a and b, normalized the way the lines of compared
+// files are. Their contexts are made of the lines given with WithSurroundings. Without them,
+// Context is 0 and Combined is Content, like with WithContextSize(0).
+func Similarity(a string, b string, opts ...Option) Scores {
+	o := newOptions(opts)
+	var left, right Surroundings
+	if o.surroundings == nil {
+		o.contextSize = 0
+	} else {
+		left, right = o.surroundings[0], o.surroundings[1]
+	}
+	pair := LinePair{left: surroundedLineInfo(a, left, o), right: surroundedLineInfo(b, right, o)}
+	o.vectorize(pair.left, pair.right)
+	scores := Scores{Content: pair.contentNormalizedLevenshteinSimilarity(), Combined: o.similarity(pair)}
+	if o.contextSize > 0 {
+		scores.Context = pair.contextTfIdfCosineSimilarity()
+	}
+	return scores
+}
+
+func surroundedLineInfo(line string, surroundings Surroundings, o *options) *LineInfo {
+	lines := make([]string, 0, len(surroundings.Before)+1+len(surroundings.After))
+	lines = append(append(append(lines, surroundings.Before...), line), surroundings.After...)
+	return MakeLineInfo(len(surroundings.Before), normalizeLines(lines), o.contextSize)
+}
@@ -0,0 +1,98 @@
+package lhdiff
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot provides the contents of the files in one version of a source tree.
+type Snapshot interface {
+	// ReadFile returns the contents of the file at path. An error satisfying
+	// errors.Is(err, os.ErrNotExist) means the file doesn't exist in the snapshot.
+	ReadFile(path string) (string, error)
+}
+
+// DirSnapshot is a Snapshot of the files below a directory. Absolute paths are read as-is.
+type DirSnapshot string
+
+func (dir DirSnapshot) ReadFile(path string) (string, error) {
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(string(dir), path)
+	}
+	content, err := ioutil.ReadFile(path)
+	return string(content), err
+}
+
+// MapSnapshot is a Snapshot of files held in memory, keyed by path.
+type MapSnapshot map[string]string
+
+func (files MapSnapshot) ReadFile(path string) (string, error) {
+	content, ok := files[path]
+	if !ok {
+		return "", &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return content, nil
+}
+
+// Remapper maps line numbers in files of an old snapshot to line numbers in a new snapshot.
+// Each file is compared once, no matter how many of its lines are remapped.
+type Remapper struct {
+	old     Snapshot
+	new     Snapshot
+	opts    []Option
+	results map[string]*Result
+}
+
+func NewRemapper(old Snapshot, new Snapshot, opts ...Option) *Remapper {
+	return &Remapper{
+		old:     old,
+		new:     new,
+		opts:    opts,
+		results: make(map[string]*Result),
+	}
+}
+
+// Remap returns the zero-based line in the new version of path that corresponds to the
+// zero-based line in the old version, and the similarity of the two lines. The boolean is
+// false if the line was deleted, or if the file doesn't exist in the new snapshot.
+func (remapper *Remapper) Remap(path string, line int) (int, float64, bool, error) {
+	result, err := remapper.Result(path)
+	if err != nil || result == nil {
+		return -1, 0, false, err
+	}
+	newLine, similarity, ok := result.RightLine(line)
+	return newLine, similarity, ok, nil
+}
+
+// Result returns the comparison of the old and new versions of path, or nil if the file
+// doesn't exist in the new snapshot.
+func (remapper *Remapper) Result(path string) (*Result, error) {
+	result, ok := remapper.results[path]
+	if ok {
+		return result, nil
+	}
+	left, err := remapper.old.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	right, err := remapper.new.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		remapper.results[path] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkText(path, left, right); err != nil {
+		return nil, err
+	}
+	result, err = Compare(left, right, append([]Option{WithPath(path)}, remapper.opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	remapper.results[path] = result
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package lhdiff
+
+import (
+	"sort"
+	"strings"
+)
+
+// Run is a run of Length unchanged lines, from zero-based line Left in the left file and Right
+// in the right file.
+type Run struct {
+	Left   int
+	Right  int
+	Length int
+}
+
+// SparseResult is a Result where unchanged lines are represented as runs, so that its size is
+// proportional to the size of the changes rather than to the size of the files.
+type SparseResult struct {
+	// Identical are the runs of unchanged lines, in order.
+	Identical []Run
+	// Mappings are the mappings of the lines that aren't in Identical: changed and deleted
+	// left lines in left line order, followed by the right lines that were added.
+	Mappings       []LineMapping
+	LeftLineCount  int
+	RightLineCount int
+	// Truncated and Partial are like those of Result
+	Truncated bool
+	Partial   bool
+	// Anchors is like Result.Anchors
+	Anchors []Anchor
+}
+
+// CompareSparse is like Compare, but returns a SparseResult. WithCache and WithMonotonic are
+// ignored.
+func CompareSparse(left string, right string, opts ...Option) (*SparseResult, error) {
+	o := newOptions(opts)
+	leftLines := ConvertToLinesWithoutNewLine(left)
+	rightLines := ConvertToLinesWithoutNewLine(right)
+	pairs, err := computePairs(leftLines, rightLines, o)
+	if err != nil {
+		return nil, err
+	}
+	return newSparseResult(pairs, len(leftLines), len(rightLines)), nil
+}
+
+func newSparseResult(pairs *pairing, leftLineCount int, rightLineCount int) *SparseResult {
+	result := &SparseResult{
+		Identical:      pairs.identical,
+		Mappings:       make([]LineMapping, 0, leftLineCount-identicalLength(pairs.identical)+len(pairs.added)),
+		LeftLineCount:  leftLineCount,
+		RightLineCount: rightLineCount,
+		Truncated:      pairs.truncated,
+		Partial:        pairs.partial,
+		Anchors:        pairs.anchors,
+	}
+	// Only the lines between the runs have to be looked up
+	leftLineNumber := 0
+	for i := 0; i <= len(pairs.identical); i++ {
+		end := leftLineCount
+		if i < len(pairs.identical) {
+			end = pairs.identical[i].Left
+		}
+		for ; leftLineNumber < end; leftLineNumber++ {
+			if pair, exists := pairs.similar[leftLineNumber]; exists {
+				result.Mappings = append(result.Mappings, LineMapping{Left: leftLineNumber, Right: pair.right.lineNumber, Similarity: pairs.similarities[leftLineNumber], WhitespaceOnly: whitespaceOnly(pair)})
+			} else {
+				result.Mappings = append(result.Mappings, LineMapping{Left: leftLineNumber, Right: -1})
+			}
+		}
+		if i < len(pairs.identical) {
+			leftLineNumber += pairs.identical[i].Length
+		}
+	}
+	for _, rightLineNumber := range pairs.added {
+		result.Mappings = append(result.Mappings, LineMapping{Left: -1, Right: rightLineNumber})
+	}
+	return result
+}
+
+// whitespaceOnly returns true if the lines of pair differ, but not once their whitespace is
+// removed, such as when spaces are added around an operator.
+func whitespaceOnly(pair LinePair) bool {
+	return pair.left.content != pair.right.content && strings.Join(strings.Fields(pair.left.content), "") == strings.Join(strings.Fields(pair.right.content), "")
+}
+
+func identicalLength(runs []Run) int {
+	length := 0
+	for _, run := range runs {
+		length += run.Length
+	}
+	return length
+}
+
+// Expand returns the equivalent Result, with a mapping for every line.
+func (result *SparseResult) Expand() *Result {
+	expanded := &Result{
+		Mappings:       make([]LineMapping, 0, result.LeftLineCount+result.RightLineCount-identicalLength(result.Identical)),
+		LeftLineCount:  result.LeftLineCount,
+		RightLineCount: result.RightLineCount,
+		Truncated:      result.Truncated,
+		Partial:        result.Partial,
+		Anchors:        result.Anchors,
+	}
+	runs, mappings := result.Identical, result.Mappings
+	for leftLineNumber := 0; leftLineNumber < result.LeftLineCount; leftLineNumber++ {
+		if len(runs) > 0 && runs[0].Left <= leftLineNumber {
+			expanded.Mappings = append(expanded.Mappings, LineMapping{Left: leftLineNumber, Right: runs[0].Right + leftLineNumber - runs[0].Left, Similarity: 1})
+			if leftLineNumber == runs[0].Left+runs[0].Length-1 {
+				runs = runs[1:]
+			}
+			continue
+		}
+		expanded.Mappings = append(expanded.Mappings, mappings[0])
+		mappings = mappings[1:]
+	}
+	expanded.Mappings = append(expanded.Mappings, mappings...)
+	return expanded
+}
+
+// RightLine is like Result.RightLine.
+func (result *SparseResult) RightLine(left int) (int, float64, bool) {
+	if left < 0 || left >= result.LeftLineCount {
+		return -1, 0, false
+	}
+	i := sort.Search(len(result.Identical), func(i int) bool {
+		return result.Identical[i].Left+result.Identical[i].Length > left
+	})
+	if i < len(result.Identical) && result.Identical[i].Left <= left {
+		return result.Identical[i].Right + left - result.Identical[i].Left, 1, true
+	}
+	for _, mapping := range result.Mappings {
+		if mapping.Left == left {
+			return mapping.Right, mapping.Similarity, mapping.Right != -1
+		}
+	}
+	return -1, 0, false
+}
+
+// LeftLine is like Result.LeftLine.
+func (result *SparseResult) LeftLine(right int) (int, float64, bool) {
+	if right < 0 || right >= result.RightLineCount {
+		return -1, 0, false
+	}
+	i := sort.Search(len(result.Identical), func(i int) bool {
+		return result.Identical[i].Right+result.Identical[i].Length > right
+	})
+	if i < len(result.Identical) && result.Identical[i].Right <= right {
+		return result.Identical[i].Left + right - result.Identical[i].Right, 1, true
+	}
+	for _, mapping := range result.Mappings {
+		if mapping.Right == right {
+			return mapping.Left, mapping.Similarity, mapping.Left != -1
+		}
+	}
+	return -1, 0, false
+}
@@ -0,0 +1,94 @@
+package lhdiff
+
+// Block is a run of Length consecutive lines, from the zero-based line Left of the left file
+// to the zero-based line Right of the right file.
+type Block struct {
+	Left   int
+	Right  int
+	Length int
+}
+
+// Stats summarizes a Result, or the Results of several files with Add.
+type Stats struct {
+	Summary
+	LeftLineCount  int
+	RightLineCount int
+	// Similarity is the average similarity of the tracked lines, and 0 if there are none
+	Similarity float64
+	// LargestMove is the longest block of reordered lines (see Reordered) that moved together
+	LargestMove Block
+}
+
+// Stats returns the Summary of the result along with the average similarity of the lines that
+// were tracked, and the largest block of lines that moved.
+func (result *Result) Stats() Stats {
+	stats := Stats{Summary: result.Summary(), LeftLineCount: result.LeftLineCount, RightLineCount: result.RightLineCount}
+	total := 0.0
+	for _, mapping := range append(append([]LineMapping(nil), result.Mappings...), result.Moved...) {
+		if mapping.Left != -1 && mapping.Right != -1 {
+			total += mapping.Similarity
+		}
+	}
+	if tracked := stats.Tracked(); tracked > 0 {
+		stats.Similarity = total / float64(tracked)
+	}
+	for _, block := range result.MovedBlocks() {
+		if block.Length > stats.LargestMove.Length {
+			stats.LargestMove = block
+		}
+	}
+	return stats
+}
+
+// MovedBlocks returns the blocks of reordered lines (see Reordered) that moved together, in left
+// line order.
+func (result *Result) MovedBlocks() []Block {
+	var blocks []Block
+	for _, mapping := range result.Reordered() {
+		if last := len(blocks) - 1; last >= 0 && mapping.Left == blocks[last].Left+blocks[last].Length && mapping.Right == blocks[last].Right+blocks[last].Length {
+			blocks[last].Length++
+		} else {
+			blocks = append(blocks, Block{Left: mapping.Left, Right: mapping.Right, Length: 1})
+		}
+	}
+	return blocks
+}
+
+// Lost returns the left lines that were deleted, in order. The lines of Moved aren't lost.
+func (result *Result) Lost() []int {
+	moved := make(map[int]bool, len(result.Moved))
+	for _, mapping := range result.Moved {
+		moved[mapping.Left] = true
+	}
+	var lost []int
+	for _, mapping := range result.Mappings {
+		if mapping.Left != -1 && mapping.Right == -1 && !moved[mapping.Left] {
+			lost = append(lost, mapping.Left)
+		}
+	}
+	return lost
+}
+
+// Add adds the counts of other to stats, averaging their similarities and displacements by the
+// number of tracked lines. The largest move and displacement of the two are kept, although its
+// lines are those of another file when it is the one of other.
+func (stats *Stats) Add(other Stats) {
+	tracked, otherTracked := stats.Tracked(), other.Tracked()
+	if tracked+otherTracked > 0 {
+		stats.Similarity = (stats.Similarity*float64(tracked) + other.Similarity*float64(otherTracked)) / float64(tracked+otherTracked)
+		stats.MeanDisplacement = (stats.MeanDisplacement*float64(tracked) + other.MeanDisplacement*float64(otherTracked)) / float64(tracked+otherTracked)
+	}
+	if abs(other.MaxDisplacement) > abs(stats.MaxDisplacement) {
+		stats.MaxDisplacement = other.MaxDisplacement
+	}
+	stats.Unchanged += other.Unchanged
+	stats.Changed += other.Changed
+	stats.Reordered += other.Reordered
+	stats.Deleted += other.Deleted
+	stats.Added += other.Added
+	stats.LeftLineCount += other.LeftLineCount
+	stats.RightLineCount += other.RightLineCount
+	if other.LargestMove.Length > stats.LargestMove.Length {
+		stats.LargestMove = other.LargestMove
+	}
+}
@@ -0,0 +1,371 @@
+// Package structure tracks lines between versions of a YAML or JSON file by matching the
+// parsed documents rather than the text. Mapping entries are matched by key, and sequence
+// items and renamed or moved entries by the similarity of their values, so that reordered
+// keys, which are common in configuration files, are tracked.
+package structure
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/SmartBear/lhdiff"
+	levenshtein "github.com/ka-weihe/fast-levenshtein"
+	"gopkg.in/yaml.v3"
+	"hash/fnv"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SimilarityThreshold is the similarity that nodes not matched by key must exceed to be matched.
+const SimilarityThreshold = 0.5
+
+// maxValueLength limits the length of the text of collections that values are compared by.
+const maxValueLength = 200
+
+// node is a mapping entry, sequence item or document. Line is zero-based.
+type node struct {
+	key      string
+	value    string
+	line     int
+	kind     yaml.Kind
+	children []*node
+	// digest is a hash of the whole value, which tells the values of collections apart when
+	// their truncated texts are the same
+	digest uint64
+}
+
+// Compare maps the lines of left to the lines of right, which are YAML (or JSON) documents.
+// Lines are mapped by the nodes that start on them, and the remaining lines, such as comments
+// and closing brackets, by comparing the text with lhdiff using opts.
+func Compare(left string, right string, opts ...lhdiff.Option) (*lhdiff.Result, error) {
+	leftRoot, err := parse(left)
+	if err != nil {
+		return nil, err
+	}
+	rightRoot, err := parse(right)
+	if err != nil {
+		return nil, err
+	}
+	text, err := lhdiff.Compare(left, right, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m := &matcher{matches: make(map[*node]*node), rightMatched: make(map[*node]bool)}
+	m.matchDocuments(leftRoot, rightRoot)
+	m.matchMoved(leftRoot, rightRoot)
+
+	leftNodes := nodesByLine(leftRoot)
+	rightNodes := nodesByLine(rightRoot)
+	leftLines, rightLines := strings.Split(left, "\n"), strings.Split(right, "\n")
+	rightMapped := make(map[int]bool)
+	mappings := make([]lhdiff.LineMapping, 0, text.LeftLineCount+text.RightLineCount)
+	for _, mapping := range text.Mappings[:text.LeftLineCount] {
+		mapping.Right, mapping.Similarity = -1, 0
+		if leftNode, ok := leftNodes[mapping.Left]; ok {
+			if rightNode, ok := m.matches[leftNode]; ok && !rightMapped[rightNode.line] {
+				mapping.Right, mapping.Similarity = rightNode.line, lineSimilarity(leftNode, rightNode, leftLines, rightLines)
+			}
+		} else if right, similarity, ok := text.RightLine(mapping.Left); ok && rightNodes[right] == nil && !rightMapped[right] {
+			mapping.Right, mapping.Similarity = right, similarity
+		}
+		if mapping.Right != -1 {
+			rightMapped[mapping.Right] = true
+		}
+		mappings = append(mappings, mapping)
+	}
+	for right := 0; right < text.RightLineCount; right++ {
+		if !rightMapped[right] {
+			mappings = append(mappings, lhdiff.LineMapping{Left: -1, Right: right})
+		}
+	}
+	return &lhdiff.Result{Mappings: mappings, LeftLineCount: text.LeftLineCount, RightLineCount: text.RightLineCount}, nil
+}
+
+// parse parses a stream of documents into a tree of nodes, with the documents as the
+// children of the root.
+func parse(text string) (*node, error) {
+	root := &node{kind: yaml.SequenceNode}
+	decoder := yaml.NewDecoder(strings.NewReader(text))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(document.Content) > 0 {
+			root.children = append(root.children, newNode("", document.Content[0]))
+		}
+	}
+}
+
+func newNode(key string, value *yaml.Node) *node {
+	n := &node{key: key, line: value.Line - 1, kind: value.Kind}
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			child := newNode(value.Content[i].Value, value.Content[i+1])
+			child.line = value.Content[i].Line - 1
+			n.children = append(n.children, child)
+		}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			n.children = append(n.children, newNode("", item))
+		}
+	case yaml.AliasNode:
+		n.value = "*" + value.Value
+	default:
+		n.value = value.Value
+	}
+	digest := fnv.New64a()
+	_, _ = fmt.Fprintf(digest, "%d %q %q", n.kind, n.key, n.value)
+	for _, child := range n.children {
+		_, _ = fmt.Fprintf(digest, " %x", child.digest)
+	}
+	n.digest = digest.Sum64()
+	if len(n.children) > 0 {
+		var text bytes.Buffer
+		n.writeText(&text)
+		n.value = text.String()
+		if len(n.value) > maxValueLength {
+			n.value = n.value[:maxValueLength]
+		}
+	}
+	return n
+}
+
+// writeText writes the keys and values of the node's descendants, which collections are
+// compared by.
+func (n *node) writeText(text *bytes.Buffer) {
+	for _, child := range n.children {
+		if text.Len() >= maxValueLength {
+			return
+		}
+		if child.key != "" {
+			text.WriteString(child.key)
+			text.WriteString(": ")
+		}
+		if len(child.children) > 0 {
+			child.writeText(text)
+		} else {
+			text.WriteString(child.value)
+			text.WriteString(" ")
+		}
+	}
+}
+
+// nodesByLine returns the first node starting on each line. Documents are left out, since
+// they start on the same line as their first entry.
+func nodesByLine(root *node) map[int]*node {
+	nodes := make(map[int]*node)
+	var visit func(*node)
+	visit = func(n *node) {
+		if _, exists := nodes[n.line]; !exists {
+			nodes[n.line] = n
+		}
+		for _, child := range n.children {
+			visit(child)
+		}
+	}
+	for _, document := range root.children {
+		if len(document.children) == 0 {
+			visit(document)
+		}
+		for _, child := range document.children {
+			visit(child)
+		}
+	}
+	return nodes
+}
+
+type matcher struct {
+	matches      map[*node]*node
+	rightMatched map[*node]bool
+}
+
+type nodePair struct {
+	left       *node
+	right      *node
+	similarity float64
+	distance   int
+}
+
+// match records that left and right match, and matches their children. Entries of mappings
+// are matched by key first. Sequence items, and entries whose key was renamed, are matched
+// by similarity.
+func (m *matcher) match(left *node, right *node) {
+	m.matches[left] = right
+	m.rightMatched[right] = true
+	if left.kind != right.kind {
+		return
+	}
+	var pairs []nodePair
+	if left.kind == yaml.MappingNode {
+		byKey := make(map[string][]*node)
+		for _, child := range right.children {
+			byKey[child.key] = append(byKey[child.key], child)
+		}
+		for _, child := range left.children {
+			if candidates := byKey[child.key]; len(candidates) > 0 {
+				pairs = append(pairs, nodePair{left: child, right: candidates[0]})
+				byKey[child.key] = candidates[1:]
+			}
+		}
+		for _, pair := range pairs {
+			m.matches[pair.left] = pair.right
+			m.rightMatched[pair.right] = true
+		}
+	}
+	var unmatchedLeft, unmatchedRight []*node
+	for _, child := range left.children {
+		if _, matched := m.matches[child]; !matched {
+			unmatchedLeft = append(unmatchedLeft, child)
+		}
+	}
+	for _, child := range right.children {
+		if !m.rightMatched[child] {
+			unmatchedRight = append(unmatchedRight, child)
+		}
+	}
+	for _, pair := range pairs {
+		m.match(pair.left, pair.right)
+	}
+	m.matchGreedily(candidates(unmatchedLeft, unmatchedRight, false))
+}
+
+// matchDocuments matches similar documents first, and then the remaining documents in order,
+// since a file usually has a single document.
+func (m *matcher) matchDocuments(leftRoot *node, rightRoot *node) {
+	m.match(leftRoot, rightRoot)
+	var unmatchedRight []*node
+	for _, document := range rightRoot.children {
+		if !m.rightMatched[document] {
+			unmatchedRight = append(unmatchedRight, document)
+		}
+	}
+	for _, document := range leftRoot.children {
+		if _, matched := m.matches[document]; !matched && len(unmatchedRight) > 0 {
+			m.match(document, unmatchedRight[0])
+			unmatchedRight = unmatchedRight[1:]
+		}
+	}
+}
+
+// matchMoved matches the remaining nodes with the same key and a similar value, wherever they
+// are, so that entries moved to another parent are tracked.
+func (m *matcher) matchMoved(leftRoot *node, rightRoot *node) {
+	m.matchGreedily(candidates(m.unmatched(leftRoot, func(n *node) bool {
+		_, matched := m.matches[n]
+		return matched
+	}), m.unmatched(rightRoot, func(n *node) bool {
+		return m.rightMatched[n]
+	}), true))
+}
+
+func (m *matcher) unmatched(root *node, matched func(*node) bool) []*node {
+	var nodes []*node
+	var visit func(*node)
+	visit = func(n *node) {
+		if !matched(n) {
+			nodes = append(nodes, n)
+		}
+		for _, child := range n.children {
+			visit(child)
+		}
+	}
+	visit(root)
+	return nodes
+}
+
+// candidates returns the pairs of nodes of the same kind that are similar enough to be
+// matched. If sameKey is true, only nodes with the same non-empty key and value are paired.
+func candidates(left []*node, right []*node, sameKey bool) []nodePair {
+	var pairs []nodePair
+	for _, leftNode := range left {
+		for _, rightNode := range right {
+			if leftNode.kind != rightNode.kind {
+				continue
+			}
+			if sameKey && (leftNode.key == "" || leftNode.key != rightNode.key || leftNode.value == "") {
+				continue
+			}
+			var pairSimilarity float64
+			if sameKey {
+				pairSimilarity = textSimilarity(leftNode.value, rightNode.value)
+			} else {
+				pairSimilarity = similarity(leftNode, rightNode)
+			}
+			if pairSimilarity > SimilarityThreshold {
+				pairs = append(pairs, nodePair{left: leftNode, right: rightNode, similarity: pairSimilarity, distance: abs(leftNode.line - rightNode.line)})
+			}
+		}
+	}
+	return pairs
+}
+
+// matchGreedily matches the most similar pairs first. Equally similar pairs are matched in
+// order of how close the nodes are to each other.
+func (m *matcher) matchGreedily(pairs []nodePair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].similarity != pairs[j].similarity {
+			return pairs[i].similarity > pairs[j].similarity
+		}
+		return pairs[i].distance < pairs[j].distance
+	})
+	for _, pair := range pairs {
+		if _, matched := m.matches[pair.left]; matched || m.rightMatched[pair.right] {
+			continue
+		}
+		m.match(pair.left, pair.right)
+	}
+}
+
+// similarity is the similarity of the values of two nodes, averaged with the similarity of
+// their keys for mapping entries.
+func similarity(left *node, right *node) float64 {
+	valueSimilarity := textSimilarity(left.value, right.value)
+	if left.key == "" && right.key == "" {
+		return valueSimilarity
+	}
+	return (textSimilarity(left.key, right.key) + valueSimilarity) / 2
+}
+
+// lineSimilarity is the similarity of two matched nodes, which is 1 only if their values and the
+// texts of their lines are the same. Otherwise a similarity of 1, of values that are only the
+// same once truncated or of lines with another style or comment, is the similarity of the
+// texts of the lines, or the largest similarity below 1 if these are the same.
+func lineSimilarity(left *node, right *node, leftLines []string, rightLines []string) float64 {
+	pairSimilarity := similarity(left, right)
+	if pairSimilarity < 1 {
+		return pairSimilarity
+	}
+	leftText, rightText := strings.TrimSpace(leftLines[left.line]), strings.TrimSpace(rightLines[right.line])
+	switch {
+	case leftText != rightText:
+		return textSimilarity(leftText, rightText)
+	case left.digest != right.digest:
+		return math.Nextafter(1, 0)
+	}
+	return 1
+}
+
+func textSimilarity(left string, right string) float64 {
+	if left == right {
+		return 1
+	}
+	length := len(left)
+	if len(right) > length {
+		length = len(right)
+	}
+	return 1 - float64(levenshtein.Distance(left, right))/float64(length)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+package structure
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func ExampleCompare() {
+	left := `# Service configuration
+name: api
+replicas: 2
+env:
+  - name: LOG_LEVEL
+    value: info
+  - name: PORT
+    value: "8080"
+resources:
+  cpu: 500m
+  memory: 256Mi
+`
+	right := `# Service configuration
+resources:
+  memory: 512Mi
+  cpu: 500m
+name: api
+env:
+  - name: PORT
+    value: "8080"
+  - name: LOG_LEVEL
+    value: debug
+replicas: 3
+`
+	result, err := Compare(left, right)
+	if err != nil {
+		panic(err)
+	}
+	for _, mapping := range result.Mappings {
+		fmt.Printf("%s,%s %.2f\n", line(mapping.Left), line(mapping.Right), mapping.Similarity)
+	}
+	// Output:
+	// 1,1 1.00
+	// 2,5 1.00
+	// 3,11 0.50
+	// 4,6 0.76
+	// 5,9 0.83
+	// 6,10 0.50
+	// 7,7 1.00
+	// 8,8 1.00
+	// 9,2 0.58
+	// 10,4 1.00
+	// 11,3 0.70
+	// 12,12 1.00
+}
+
+func line(line int) string {
+	if line == -1 {
+		return "_"
+	}
+	return strconv.Itoa(line + 1)
+}
+
+func ExampleCompare_changedText() {
+	// The values of the quoted port and of the long lists are the same, or the same once truncated,
+	// but their texts changed
+	var left, right strings.Builder
+	left.WriteString("port: \"8080\"\nhosts:\n")
+	right.WriteString("port: 8080 # the default\nhosts:\n")
+	for i := 0; i < 30; i++ {
+		_, _ = fmt.Fprintf(&left, "  - host-%d.example.com\n", i)
+		_, _ = fmt.Fprintf(&right, "  - host-%d.example.com\n", i)
+	}
+	right.WriteString("  - host-30.example.com\n")
+	result, err := Compare(left.String(), right.String())
+	if err != nil {
+		panic(err)
+	}
+	for _, mapping := range result.Mappings[:2] {
+		fmt.Printf("%s,%s %t\n", line(mapping.Left), line(mapping.Right), mapping.Similarity == 1)
+	}
+	fmt.Println(result.Summary())
+
+	// Output:
+	// 1,1 false
+	// 2,2 false
+	// 31 unchanged, 2 changed, 0 reordered, 0 deleted, 1 added
+}
@@ -0,0 +1,90 @@
+package lhdiff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Reordered returns the pairs of lines whose order was inverted: the fewest pairs that have to
+// be left out for the remaining ones not to cross each other, along with Moved. The pairs are in
+// left line order.
+func (result *Result) Reordered() []LineMapping {
+	increasing := increasingPairs(result.Mappings)
+	reordered := append([]LineMapping(nil), result.Moved...)
+	for i, mapping := range result.Mappings {
+		if mapping.Left != -1 && mapping.Right != -1 && !increasing[i] {
+			reordered = append(reordered, mapping)
+		}
+	}
+	sort.Slice(reordered, func(i, j int) bool {
+		return reordered[i].Left < reordered[j].Left
+	})
+	return reordered
+}
+
+// Summary counts the lines of a Result by what happened to them, and how far the tracked lines
+// moved (see LineMapping.Displacement).
+type Summary struct {
+	Unchanged int
+	Changed   int
+	Reordered int
+	Deleted   int
+	Added     int
+	// MeanDisplacement is the average number of lines that the tracked lines moved, up or down
+	MeanDisplacement float64
+	// MaxDisplacement is the displacement of the tracked line that moved the furthest
+	MaxDisplacement int
+}
+
+// Summary returns the number of unchanged, changed, reordered, deleted and added lines. Reordered
+// lines (see Reordered) aren't counted as unchanged or changed, and the lines of Moved aren't
+// counted as deleted or added.
+func (result *Result) Summary() Summary {
+	increasing := increasingPairs(result.Mappings)
+	summary := Summary{Reordered: len(result.Moved)}
+	for i, mapping := range result.Mappings {
+		switch {
+		case mapping.Left == -1:
+			summary.Added++
+		case mapping.Right == -1:
+			summary.Deleted++
+		case !increasing[i]:
+			summary.Reordered++
+		case mapping.Similarity == 1:
+			summary.Unchanged++
+		default:
+			summary.Changed++
+		}
+	}
+	summary.Deleted -= len(result.Moved)
+	summary.Added -= len(result.Moved)
+	total := 0
+	for _, mapping := range append(append([]LineMapping(nil), result.Mappings...), result.Moved...) {
+		displacement := mapping.Displacement()
+		total += abs(displacement)
+		if abs(displacement) > abs(summary.MaxDisplacement) {
+			summary.MaxDisplacement = displacement
+		}
+	}
+	if tracked := summary.Tracked(); tracked > 0 {
+		summary.MeanDisplacement = float64(total) / float64(tracked)
+	}
+	return summary
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Tracked returns the number of lines that were found in the right file: the unchanged, changed
+// and reordered ones.
+func (summary Summary) Tracked() int {
+	return summary.Unchanged + summary.Changed + summary.Reordered
+}
+
+func (summary Summary) String() string {
+	return fmt.Sprintf("%d unchanged, %d changed, %d reordered, %d deleted, %d added", summary.Unchanged, summary.Changed, summary.Reordered, summary.Deleted, summary.Added)
+}
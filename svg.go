@@ -0,0 +1,107 @@
+package lhdiff
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+const svgWidth = 400
+const svgColumnWidth = 60
+const svgMaxHeight = 2000
+const svgMaxLineHeight = 4.0
+
+type ribbon struct {
+	left       int
+	right      int
+	length     int
+	similarity float64
+}
+
+// WriteSVG writes a "ribbon" visualization of result to w. Left lines are drawn in a column on
+// the left and right lines in a column on the right. Mapped lines are connected by ribbons
+// colored by similarity (green is identical, red is barely similar). Deleted and added lines are
+// marked in red in their column.
+func WriteSVG(w io.Writer, result *Result) error {
+	lineCount := math.Max(float64(result.LeftLineCount), float64(result.RightLineCount))
+	lineHeight := svgMaxLineHeight
+	if lineCount*lineHeight > svgMaxHeight {
+		lineHeight = svgMaxHeight / lineCount
+	}
+	height := math.Max(lineCount*lineHeight, 1)
+	rightX := float64(svgWidth - svgColumnWidth)
+
+	_, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%.0f\" viewBox=\"0 0 %d %.2f\">\n", svgWidth, math.Ceil(height), svgWidth, height)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "<rect x=\"0\" y=\"0\" width=\"%d\" height=\"%.2f\" fill=\"#eeeeee\"/>\n", svgColumnWidth, float64(result.LeftLineCount)*lineHeight)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "<rect x=\"%.0f\" y=\"0\" width=\"%d\" height=\"%.2f\" fill=\"#eeeeee\"/>\n", rightX, svgColumnWidth, float64(result.RightLineCount)*lineHeight)
+	if err != nil {
+		return err
+	}
+	for _, r := range ribbons(result.Mappings) {
+		y1 := float64(r.left) * lineHeight
+		y2 := float64(r.right) * lineHeight
+		h := float64(r.length) * lineHeight
+		midX := float64(svgWidth) / 2
+		_, err = fmt.Fprintf(w, "<path d=\"M%d %.2f C%.0f %.2f %.0f %.2f %.0f %.2f V%.2f C%.0f %.2f %.0f %.2f %d %.2f Z\" fill=\"%s\" fill-opacity=\"0.6\"/>\n",
+			svgColumnWidth, y1,
+			midX, y1, midX, y2, rightX, y2,
+			y2+h,
+			midX, y2+h, midX, y1+h, svgColumnWidth, y1+h,
+			similarityColor(r.similarity))
+		if err != nil {
+			return err
+		}
+	}
+	for _, mapping := range result.Mappings {
+		switch {
+		case mapping.Right == -1:
+			_, err = fmt.Fprintf(w, "<rect x=\"0\" y=\"%.2f\" width=\"%d\" height=\"%.2f\" fill=\"#d62728\"/>\n", float64(mapping.Left)*lineHeight, svgColumnWidth, lineHeight)
+		case mapping.Left == -1:
+			_, err = fmt.Fprintf(w, "<rect x=\"%.0f\" y=\"%.2f\" width=\"%d\" height=\"%.2f\" fill=\"#d62728\"/>\n", rightX, float64(mapping.Right)*lineHeight, svgColumnWidth, lineHeight)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// ribbons merges runs of consecutive mapped lines with the same similarity into a single
+// ribbon, so that large unchanged regions don't produce one element per line.
+func ribbons(mappings []LineMapping) []ribbon {
+	var ribbons []ribbon
+	for _, mapping := range mappings {
+		if mapping.Left == -1 || mapping.Right == -1 {
+			continue
+		}
+		if len(ribbons) > 0 {
+			last := &ribbons[len(ribbons)-1]
+			if last.left+last.length == mapping.Left && last.right+last.length == mapping.Right && last.similarity == mapping.Similarity {
+				last.length++
+				continue
+			}
+		}
+		ribbons = append(ribbons, ribbon{
+			left:       mapping.Left,
+			right:      mapping.Right,
+			length:     1,
+			similarity: mapping.Similarity,
+		})
+	}
+	return ribbons
+}
+
+// similarityColor returns a color between red (similarity at the threshold) and green (identical).
+func similarityColor(similarity float64) string {
+	t := (similarity - SimilarityThreshold) / (1 - SimilarityThreshold)
+	t = math.Max(0, math.Min(1, t))
+	hue := 120 * t
+	return fmt.Sprintf("hsl(%.0f,70%%,45%%)", hue)
+}
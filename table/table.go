@@ -0,0 +1,318 @@
+// Package table tracks rows between versions of a CSV or TSV data file. Rows are compared
+// cell by cell rather than as raw text, so that rows that are reordered or have a cell edited
+// are still tracked. Key columns can be given to identify rows by their key instead.
+package table
+
+import (
+	"encoding/csv"
+	"fmt"
+	levenshtein "github.com/ka-weihe/fast-levenshtein"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RowSimilarityThreshold is the similarity two rows must exceed to be matched when they aren't
+// identified by key columns.
+const RowSimilarityThreshold = 0.5
+
+// Table is a parsed data file. Header is nil unless the file was parsed with a header row.
+type Table struct {
+	Header *Row
+	Rows   []Row
+}
+
+// Row is a record of a data file. Line is the zero-based line number the record starts on.
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// Parse parses a data file with comma as the field delimiter. If header is true, the first
+// record is the header, and columns are matched by name when comparing.
+func Parse(data io.Reader, comma rune, header bool) (*Table, error) {
+	reader := csv.NewReader(data)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	table := &Table{}
+	for {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			return table, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		row := Row{Line: line - 1, Cells: cells}
+		if header && table.Header == nil {
+			table.Header = &row
+			continue
+		}
+		table.Rows = append(table.Rows, row)
+	}
+}
+
+// Column returns the zero-based index of a column, given either by its name in the header or
+// by its one-based number.
+func (table *Table) Column(column string) (int, error) {
+	if table.Header != nil {
+		for i, name := range table.Header.Cells {
+			if name == column {
+				return i, nil
+			}
+		}
+	}
+	number, err := strconv.Atoi(column)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("no such column: %s", column)
+	}
+	return number - 1, nil
+}
+
+// RowMapping maps a row of the left table to a row of the right table. Row numbers are
+// zero-based indexes into Rows, and -1 means that the row has no counterpart.
+type RowMapping struct {
+	Left       int
+	Right      int
+	Similarity float64
+}
+
+// Compare matches the rows of two tables. Keys are the zero-based indexes of the key columns
+// in the left table. Rows with the same key are matched whatever their content, and rows
+// whose key isn't in the other table are deleted or added. Without keys, identical rows are
+// matched first, and then the most similar remaining rows. The similarity of two rows is the
+// average similarity of their cells.
+//
+// Mappings are returned for each left row, in order, followed by the added right rows.
+func Compare(left *Table, right *Table, keys []int) []RowMapping {
+	columns := alignColumns(left, right)
+	pairs := make(map[int]RowMapping)
+	rightMatched := make(map[int]bool)
+	if len(keys) > 0 {
+		byKey := make(map[string][]int)
+		for r, row := range right.Rows {
+			key := rowKey(row.Cells, rightColumns(columns, keys))
+			byKey[key] = append(byKey[key], r)
+		}
+		for l, row := range left.Rows {
+			key := rowKey(row.Cells, keys)
+			if candidates := byKey[key]; len(candidates) > 0 {
+				r := candidates[0]
+				byKey[key] = candidates[1:]
+				pairs[l] = RowMapping{Left: l, Right: r, Similarity: rowSimilarity(row.Cells, right.Rows[r].Cells, columns)}
+				rightMatched[r] = true
+			}
+		}
+	} else {
+		matchIdentical(left, right, columns, pairs, rightMatched)
+		matchSimilar(left, right, columns, pairs, rightMatched)
+	}
+	mappings := make([]RowMapping, 0, len(left.Rows)+len(right.Rows))
+	for l := range left.Rows {
+		mapping, matched := pairs[l]
+		if !matched {
+			mapping = RowMapping{Left: l, Right: -1}
+		}
+		mappings = append(mappings, mapping)
+	}
+	for r := range right.Rows {
+		if !rightMatched[r] {
+			mappings = append(mappings, RowMapping{Left: -1, Right: r})
+		}
+	}
+	return mappings
+}
+
+// column is a pair of zero-based column indexes, -1 if the column is missing from a table.
+type column struct {
+	left  int
+	right int
+}
+
+// alignColumns pairs the columns of the tables by name if both have a header, and by position
+// otherwise. Columns of the left table come first.
+func alignColumns(left *Table, right *Table) []column {
+	leftCount, rightCount := columnCount(left), columnCount(right)
+	var columns []column
+	if left.Header == nil || right.Header == nil {
+		for i := 0; i < leftCount || i < rightCount; i++ {
+			columns = append(columns, column{left: i, right: i})
+		}
+		return columns
+	}
+	rightByName := make(map[string]int)
+	for i := len(right.Header.Cells) - 1; i >= 0; i-- {
+		rightByName[right.Header.Cells[i]] = i
+	}
+	rightAligned := make(map[int]bool)
+	for l, name := range left.Header.Cells {
+		r, ok := rightByName[name]
+		if !ok || rightAligned[r] {
+			r = -1
+		} else {
+			rightAligned[r] = true
+		}
+		columns = append(columns, column{left: l, right: r})
+	}
+	for r := 0; r < rightCount; r++ {
+		if !rightAligned[r] {
+			columns = append(columns, column{left: -1, right: r})
+		}
+	}
+	return columns
+}
+
+func columnCount(table *Table) int {
+	count := 0
+	if table.Header != nil {
+		count = len(table.Header.Cells)
+	}
+	for _, row := range table.Rows {
+		if len(row.Cells) > count {
+			count = len(row.Cells)
+		}
+	}
+	return count
+}
+
+// rightColumns returns the right columns aligned with the given left columns.
+func rightColumns(columns []column, leftColumns []int) []int {
+	indexes := make([]int, len(leftColumns))
+	for i, leftColumn := range leftColumns {
+		indexes[i] = -1
+		for _, c := range columns {
+			if c.left == leftColumn {
+				indexes[i] = c.right
+			}
+		}
+	}
+	return indexes
+}
+
+func rowKey(cells []string, indexes []int) string {
+	values := make([]string, len(indexes))
+	for i, index := range indexes {
+		values[i] = cell(cells, index)
+	}
+	return strings.Join(values, "\x00")
+}
+
+func cell(cells []string, index int) string {
+	if index < 0 || index >= len(cells) {
+		return ""
+	}
+	return cells[index]
+}
+
+// matchIdentical matches rows with identical cells, in order.
+func matchIdentical(left *Table, right *Table, columns []column, pairs map[int]RowMapping, rightMatched map[int]bool) {
+	leftIndexes := make([]int, len(columns))
+	rightIndexes := make([]int, len(columns))
+	for i, c := range columns {
+		leftIndexes[i], rightIndexes[i] = c.left, c.right
+	}
+	byContent := make(map[string][]int)
+	for r, row := range right.Rows {
+		key := rowKey(row.Cells, rightIndexes)
+		byContent[key] = append(byContent[key], r)
+	}
+	for l, row := range left.Rows {
+		key := rowKey(row.Cells, leftIndexes)
+		if candidates := byContent[key]; len(candidates) > 0 {
+			pairs[l] = RowMapping{Left: l, Right: candidates[0], Similarity: 1}
+			rightMatched[candidates[0]] = true
+			byContent[key] = candidates[1:]
+		}
+	}
+}
+
+// matchSimilar greedily matches the most similar of the remaining rows first. Equally similar
+// pairs are matched in order of how close the rows are to each other.
+func matchSimilar(left *Table, right *Table, columns []column, pairs map[int]RowMapping, rightMatched map[int]bool) {
+	var candidates []RowMapping
+	for l, leftRow := range left.Rows {
+		if _, matched := pairs[l]; matched {
+			continue
+		}
+		for r, rightRow := range right.Rows {
+			if rightMatched[r] {
+				continue
+			}
+			similarity := rowSimilarity(leftRow.Cells, rightRow.Cells, columns)
+			if similarity > RowSimilarityThreshold {
+				candidates = append(candidates, RowMapping{Left: l, Right: r, Similarity: similarity})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Similarity != candidates[j].Similarity {
+			return candidates[i].Similarity > candidates[j].Similarity
+		}
+		return abs(candidates[i].Left-candidates[i].Right) < abs(candidates[j].Left-candidates[j].Right)
+	})
+	for _, candidate := range candidates {
+		if _, matched := pairs[candidate.Left]; matched || rightMatched[candidate.Right] {
+			continue
+		}
+		pairs[candidate.Left] = candidate
+		rightMatched[candidate.Right] = true
+	}
+}
+
+// rowSimilarity is the average normalized Levenshtein similarity of the aligned cells.
+func rowSimilarity(left []string, right []string, columns []column) float64 {
+	if len(columns) == 0 {
+		return 1
+	}
+	total := 0.0
+	for _, c := range columns {
+		total += cellSimilarity(cell(left, c.left), cell(right, c.right))
+	}
+	return total / float64(len(columns))
+}
+
+func cellSimilarity(left string, right string) float64 {
+	if left == right {
+		return 1
+	}
+	length := len(left)
+	if len(right) > length {
+		length = len(right)
+	}
+	return 1 - float64(levenshtein.Distance(left, right))/float64(length)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// PrintMappings prints row mappings as left,right one-based line numbers of the rows, or _ for
+// rows that have no counterpart. The header rows are mapped first if both tables have one.
+func PrintMappings(w io.Writer, left *Table, right *Table, mappings []RowMapping) error {
+	if left.Header != nil && right.Header != nil {
+		_, err := fmt.Fprintf(w, "%d,%d\n", left.Header.Line+1, right.Header.Line+1)
+		if err != nil {
+			return err
+		}
+	}
+	for _, mapping := range mappings {
+		_, err := fmt.Fprintf(w, "%s,%s\n", rowLine(left, mapping.Left), rowLine(right, mapping.Right))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rowLine(table *Table, index int) string {
+	if index == -1 {
+		return "_"
+	}
+	return strconv.Itoa(table.Rows[index].Line + 1)
+}
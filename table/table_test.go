@@ -0,0 +1,61 @@
+package table
+
+import (
+	"os"
+	"strings"
+)
+
+func ExampleCompare() {
+	left, err := Parse(strings.NewReader(`id,name,city
+1,Alice,Oslo
+2,Bob,Bergen
+3,Carol,Trondheim
+4,Dave,Stavanger
+`), ',', true)
+	if err != nil {
+		panic(err)
+	}
+	right, err := Parse(strings.NewReader(`name,id,city
+Carol,3,Trondheim
+Alice,1,Oslo
+Bobby,2,Bergen
+Erin,5,Tromsø
+`), ',', true)
+	if err != nil {
+		panic(err)
+	}
+	err = PrintMappings(os.Stdout, left, right, Compare(left, right, nil))
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// 1,1
+	// 2,3
+	// 3,4
+	// 4,2
+	// 5,_
+	// _,5
+}
+
+func ExampleCompare_withKeys() {
+	left, err := Parse(strings.NewReader("sku\tdescription\tprice\nA1\tRed pen\t1.50\nB2\tBlue pen\t1.50\n"), '\t', true)
+	if err != nil {
+		panic(err)
+	}
+	right, err := Parse(strings.NewReader("sku\tdescription\tprice\nB2\tRed pen\t1.75\nA1\tRed ballpoint pen\t1.50\n"), '\t', true)
+	if err != nil {
+		panic(err)
+	}
+	sku, err := left.Column("sku")
+	if err != nil {
+		panic(err)
+	}
+	err = PrintMappings(os.Stdout, left, right, Compare(left, right, []int{sku}))
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// 1,1
+	// 2,3
+	// 3,2
+}
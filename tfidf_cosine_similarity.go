@@ -7,10 +7,12 @@ import (
 )
 
 func TfIdfCosineSimilarity(docA string, docB string) float64 {
-	tokensA := strings.Fields(docA)
-	tokensB := strings.Fields(docB)
+	return tfIdfCosineSimilarity(strings.Fields(docA), strings.Fields(docB))
+}
 
-	tokens := union(tokensA, tokensB)
+// tfIdfCosineSimilarity is TfIdfCosineSimilarity for documents that are already tokenized.
+func tfIdfCosineSimilarity(tokensA []string, tokensB []string) float64 {
+	tokens := union(append([]string(nil), tokensA...), tokensB)
 	n := len(tokens)
 	vectorA := make([]float64, n)
 	vectorB := make([]float64, n)
@@ -0,0 +1,116 @@
+package lhdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenizer splits contexts into the terms whose TF-IDF vectors are compared.
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// WhitespaceTokenizer is the default Tokenizer, which splits text on whitespace.
+type WhitespaceTokenizer struct{}
+
+// Tokenize implements Tokenizer.
+func (WhitespaceTokenizer) Tokenize(text string) []string {
+	return strings.Fields(text)
+}
+
+// UnicodeTokenizer splits text on whitespace like WhitespaceTokenizer, and further splits the
+// fields with non-ASCII characters at Unicode word boundaries. Han, Hiragana and Katakana, which
+// aren't written with spaces between words, are split into overlapping bigrams, so that Chinese
+// and Japanese text and identifiers share terms when they share words. Fields that are ASCII are
+// kept as they are, so code compares like with WhitespaceTokenizer.
+type UnicodeTokenizer struct{}
+
+// Tokenize implements Tokenizer.
+func (UnicodeTokenizer) Tokenize(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		if isASCII(field) {
+			tokens = append(tokens, field)
+			continue
+		}
+		var word []rune
+		var ideographs []rune
+		flush := func() {
+			if len(word) > 0 {
+				tokens = append(tokens, string(word))
+				word = word[:0]
+			}
+			if len(ideographs) == 1 {
+				tokens = append(tokens, string(ideographs))
+			}
+			for i := 0; i+1 < len(ideographs); i++ {
+				tokens = append(tokens, string(ideographs[i:i+2]))
+			}
+			ideographs = ideographs[:0]
+		}
+		for _, r := range field {
+			switch {
+			// The prolonged sound mark ー is used in both Hiragana and Katakana words
+			case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == '\u30fc':
+				if len(word) > 0 {
+					flush()
+				}
+				ideographs = append(ideographs, r)
+			case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_':
+				if len(ideographs) > 0 {
+					flush()
+				}
+				word = append(word, r)
+			default:
+				flush()
+			}
+		}
+		flush()
+	}
+	return tokens
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// WithStopWords leaves words, such as the keywords of a programming language or the most common
+// words of a natural language, out of the terms of contexts, so that the words that most lines
+// have don't make unrelated contexts similar. Words are compared with the terms of the Tokenizer
+// as they are, so they are case-sensitive.
+func WithStopWords(words ...string) Option {
+	return func(o *options) {
+		o.stopWords = make(map[string]bool, len(words))
+		for _, word := range words {
+			o.stopWords[word] = true
+		}
+	}
+}
+
+// withoutStopWords returns the tokens that aren't stop words.
+func (o *options) withoutStopWords(tokens []string) []string {
+	if len(o.stopWords) == 0 {
+		return tokens
+	}
+	kept := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !o.stopWords[token] {
+			kept = append(kept, token)
+		}
+	}
+	return kept
+}
+
+// WithTokenizer sets the Tokenizer that contexts are split into terms with. The default is
+// WhitespaceTokenizer. It isn't used with WithVectorizer, whose Vectorizer has its own (see
+// NewTokenizedVectorizer).
+func WithTokenizer(tokenizer Tokenizer) Option {
+	return func(o *options) {
+		o.tokenizer = tokenizer
+	}
+}
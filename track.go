@@ -0,0 +1,92 @@
+package lhdiff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLineNotFound is returned by TrackLine when the line was deleted.
+var ErrLineNotFound = errors.New("line not found")
+
+// TrackLine returns the zero-based right line that a zero-based left line maps to, along with
+// the similarity of the pair. The error is ErrLineNotFound if the line was deleted.
+func TrackLine(left string, right string, line int, opts ...Option) (int, float64, error) {
+	result, err := Compare(left, right, opts...)
+	if err != nil {
+		return -1, 0, err
+	}
+	if line < 0 || line >= result.LeftLineCount {
+		return -1, 0, fmt.Errorf("line %d is out of range: the left file has %d lines", line, result.LeftLineCount)
+	}
+	rightLine, similarity, ok := result.RightLine(line)
+	if !ok {
+		return -1, 0, fmt.Errorf("line %d: %w", line, ErrLineNotFound)
+	}
+	return rightLine, similarity, nil
+}
+
+// Range is a range of zero-based lines, from Start to End inclusive. Similarity is the
+// average similarity of the lines mapped to the range.
+type Range struct {
+	Start      int
+	End        int
+	Similarity float64
+}
+
+// TrackRange maps the left lines from start to end (zero-based, inclusive) to the right
+// ranges they ended up in, in right line order. The range is fragmented where its lines were
+// moved apart or lines from elsewhere were moved in between them. Lines added within a
+// fragment are part of it. No ranges are returned if all the lines were deleted.
+func TrackRange(left string, right string, start int, end int, opts ...Option) ([]Range, error) {
+	result, err := Compare(left, right, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || end >= result.LeftLineCount || start > end {
+		return nil, fmt.Errorf("lines %d-%d are out of range: the left file has %d lines", start, end, result.LeftLineCount)
+	}
+	similarities := make(map[int]float64)
+	for line := start; line <= end; line++ {
+		if rightLine, similarity, ok := result.RightLine(line); ok {
+			similarities[rightLine] = similarity
+		}
+	}
+	added := make(map[int]bool)
+	for _, mapping := range result.Mappings[result.LeftLineCount:] {
+		added[mapping.Right] = true
+	}
+	var ranges []Range
+	var total float64
+	var count int
+	for line := 0; line < result.RightLineCount; line++ {
+		similarity, mapped := similarities[line]
+		if !mapped {
+			continue
+		}
+		if len(ranges) > 0 && betweenAdded(ranges[len(ranges)-1].End, line, added) {
+			ranges[len(ranges)-1].End = line
+		} else {
+			if len(ranges) > 0 {
+				ranges[len(ranges)-1].Similarity = total / float64(count)
+			}
+			ranges = append(ranges, Range{Start: line, End: line})
+			total, count = 0, 0
+		}
+		total += similarity
+		count++
+	}
+	if len(ranges) > 0 {
+		ranges[len(ranges)-1].Similarity = total / float64(count)
+	}
+	return ranges, nil
+}
+
+// betweenAdded returns true if all the lines between from and to (exclusive) were added.
+func betweenAdded(from int, to int, added map[int]bool) bool {
+	for line := from + 1; line < to; line++ {
+		if !added[line] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package lhdiff
+
+import (
+	"sort"
+)
+
+// Trajectory is the history of a line across versions. Lines[i] is the zero-based line it has in
+// the i-th version, or -1 if it isn't in that version. Similarities[i] is its similarity to the
+// previous version it was in, and 0 if it isn't in the i-th version or wasn't in any earlier one.
+type Trajectory struct {
+	Lines        []int
+	Similarities []float64
+}
+
+// dormantLine is a line that was deleted from a version, and may re-appear in a later one.
+type dormantLine struct {
+	trajectory int
+	info       *LineInfo
+}
+
+// LhdiffN tracks the lines of an ordered series of versions of a file, and returns the trajectory
+// of every line of every version: the lines of the first version in order, followed by the lines
+// that each later version added, in order. Each version is compared with the previous one. The
+// lines that were deleted are compared with the lines of later versions that are added, so that
+// a line that was removed and put back later keeps its trajectory, which tracking each pair of
+// consecutive versions separately loses.
+func LhdiffN(versions []string, opts ...Option) ([]Trajectory, error) {
+	o := newOptions(opts)
+	var trajectories []Trajectory
+	newTrajectory := func(version int, line int) int {
+		trajectory := Trajectory{Lines: make([]int, len(versions)), Similarities: make([]float64, len(versions))}
+		for i := range trajectory.Lines {
+			trajectory.Lines[i] = -1
+		}
+		trajectory.Lines[version] = line
+		trajectories = append(trajectories, trajectory)
+		return len(trajectories) - 1
+	}
+	if len(versions) == 0 {
+		return trajectories, nil
+	}
+	previousLines := ConvertToLinesWithoutNewLine(versions[0])
+	// previous[line] is the trajectory of each line of the previous version
+	previous := make([]int, len(previousLines))
+	for line := range previousLines {
+		previous[line] = newTrajectory(0, line)
+	}
+	var dormant []dormantLine
+	for version := 1; version < len(versions); version++ {
+		lines := ConvertToLinesWithoutNewLine(versions[version])
+		result, err := compareNormalizedLines(previousLines, lines, o)
+		if err != nil {
+			return nil, err
+		}
+		current := make([]int, len(lines))
+		// The pairs that WithMonotonic left out of the mappings are tracked like the others
+		movedLefts, movedRights := make(map[int]bool), make(map[int]bool)
+		for _, mapping := range result.Moved {
+			movedLefts[mapping.Left], movedRights[mapping.Right] = true, true
+		}
+		var added []int
+		var deleted []dormantLine
+		for _, mapping := range result.Mappings {
+			switch {
+			case mapping.Left == -1:
+				if !movedRights[mapping.Right] {
+					added = append(added, mapping.Right)
+				}
+			case mapping.Right == -1:
+				if !movedLefts[mapping.Left] {
+					info := MakeLineInfo(mapping.Left, previousLines, o.contextSize)
+					o.vectorize(info)
+					deleted = append(deleted, dormantLine{trajectory: previous[mapping.Left], info: info})
+				}
+			default:
+				track(trajectories, previous, current, version, mapping)
+			}
+		}
+		for _, mapping := range result.Moved {
+			track(trajectories, previous, current, version, mapping)
+		}
+		// The lines deleted by this version were already compared with the lines it added
+		var revived map[int]bool
+		dormant, revived = revive(dormant, added, lines, version, trajectories, current, o)
+		dormant = append(dormant, deleted...)
+		for _, line := range added {
+			if !revived[line] {
+				current[line] = newTrajectory(version, line)
+			}
+		}
+		previousLines, previous = lines, current
+	}
+	return trajectories, nil
+}
+
+func track(trajectories []Trajectory, previous []int, current []int, version int, mapping LineMapping) {
+	trajectory := previous[mapping.Left]
+	trajectories[trajectory].Lines[version] = mapping.Right
+	trajectories[trajectory].Similarities[version] = mapping.Similarity
+	current[mapping.Right] = trajectory
+}
+
+// revive pairs the most similar dormant lines with the added lines of a version, records them
+// in their trajectories and in current, and returns the lines that stay dormant along with the
+// added lines that were paired.
+func revive(dormant []dormantLine, added []int, lines []string, version int, trajectories []Trajectory, current []int, o *options) ([]dormantLine, map[int]bool) {
+	type candidate struct {
+		dormant    int
+		line       int
+		similarity float64
+	}
+	var candidates []candidate
+	if len(dormant) > 0 {
+		for _, line := range added {
+			info := MakeLineInfo(line, lines, o.contextSize)
+			o.vectorize(info)
+			for i := range dormant {
+				similarity := o.similarity(LinePair{left: dormant[i].info, right: info})
+				if similarity > o.threshold {
+					candidates = append(candidates, candidate{dormant: i, line: line, similarity: similarity})
+				}
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+	revivedDormant := make(map[int]bool)
+	revived := make(map[int]bool)
+	for _, c := range candidates {
+		if revivedDormant[c.dormant] || revived[c.line] {
+			continue
+		}
+		revivedDormant[c.dormant], revived[c.line] = true, true
+		trajectory := dormant[c.dormant].trajectory
+		trajectories[trajectory].Lines[version] = c.line
+		trajectories[trajectory].Similarities[version] = c.similarity
+		current[c.line] = trajectory
+	}
+	var kept []dormantLine
+	for i, line := range dormant {
+		if !revivedDormant[i] {
+			kept = append(kept, line)
+		}
+	}
+	return kept, revived
+}
@@ -0,0 +1,136 @@
+package lhdiff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Vectorizer turns contexts into TF-IDF vectors with the term statistics of a corpus, which are
+// computed once by NewVectorizer, rather than with those of the two contexts being compared like
+// TfIdfCosineSimilarity does. A Vectorizer isn't modified once it is fitted, so it can be shared
+// by concurrent comparisons (see WithVectorizer).
+type Vectorizer struct {
+	tokenizer         Tokenizer
+	documents         int
+	documentFrequency map[string]int
+	// fingerprint is a hash of the statistics
+	fingerprint string
+}
+
+// NewVectorizer fits a Vectorizer on texts, such as the two compared files or all the files of a
+// snapshot. Each non-blank line of each text is a document.
+func NewVectorizer(texts ...string) *Vectorizer {
+	return NewTokenizedVectorizer(WhitespaceTokenizer{}, texts...)
+}
+
+// NewTokenizedVectorizer is like NewVectorizer, for documents and contexts split into terms with
+// tokenizer.
+func NewTokenizedVectorizer(tokenizer Tokenizer, texts ...string) *Vectorizer {
+	vectorizer := &Vectorizer{tokenizer: tokenizer, documentFrequency: make(map[string]int)}
+	for _, text := range texts {
+		for _, line := range strings.Split(text, "\n") {
+			tokens := tokenizer.Tokenize(line)
+			if len(tokens) == 0 {
+				continue
+			}
+			vectorizer.documents++
+			seen := make(map[string]bool, len(tokens))
+			for _, token := range tokens {
+				if !seen[token] {
+					seen[token] = true
+					vectorizer.documentFrequency[token]++
+				}
+			}
+		}
+	}
+	terms := make([]string, 0, len(vectorizer.documentFrequency))
+	for term := range vectorizer.documentFrequency {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%#v %d\n", tokenizer, vectorizer.documents)
+	for _, term := range terms {
+		_, _ = fmt.Fprintf(hash, "%s %d\n", term, vectorizer.documentFrequency[term])
+	}
+	vectorizer.fingerprint = hex.EncodeToString(hash.Sum(nil))
+	return vectorizer
+}
+
+// key identifies the statistics the Vectorizer was fitted on in cache keys.
+func (vectorizer *Vectorizer) key() string {
+	if vectorizer == nil {
+		return ""
+	}
+	return vectorizer.fingerprint
+}
+
+// Similarity returns the cosine similarity of the TF-IDF vectors of two documents. It is 1 if
+// both documents are blank, and 0 if only one of them is.
+func (vectorizer *Vectorizer) Similarity(docA string, docB string) float64 {
+	return vectorizer.vector(vectorizer.tokenizer.Tokenize(docA)).cosineSimilarity(vectorizer.vector(vectorizer.tokenizer.Tokenize(docB)))
+}
+
+// idf returns the inverse document frequency of a term. It is smoothed so that it is positive,
+// and highest for the terms that aren't in the corpus.
+func (vectorizer *Vectorizer) idf(term string) float64 {
+	return math.Log(float64(1+vectorizer.documents)/float64(1+vectorizer.documentFrequency[term])) + 1
+}
+
+// tfidfVector is a sparse TF-IDF vector, with its Euclidean norm.
+type tfidfVector struct {
+	weights map[string]float64
+	norm    float64
+}
+
+func (vectorizer *Vectorizer) vector(tokens []string) *tfidfVector {
+	counts := make(map[string]int)
+	for _, token := range tokens {
+		counts[token]++
+	}
+	vector := &tfidfVector{weights: make(map[string]float64, len(counts))}
+	for term, count := range counts {
+		weight := float64(count) * vectorizer.idf(term)
+		vector.weights[term] = weight
+		vector.norm += weight * weight
+	}
+	vector.norm = math.Sqrt(vector.norm)
+	return vector
+}
+
+func (vector *tfidfVector) cosineSimilarity(other *tfidfVector) float64 {
+	if vector.norm == 0 || other.norm == 0 {
+		if vector.norm == other.norm {
+			return 1
+		}
+		return 0
+	}
+	smaller, larger := vector, other
+	if len(larger.weights) < len(smaller.weights) {
+		smaller, larger = larger, smaller
+	}
+	dot := 0.0
+	for term, weight := range smaller.weights {
+		dot += weight * larger.weights[term]
+	}
+	return dot / (vector.norm * other.norm)
+}
+
+// vectorize computes the vectors of the contexts of lineInfos if there is a Vectorizer, or their
+// terms if there is a Tokenizer or stop words, so that they are computed once per line.
+func (o *options) vectorize(lineInfos ...*LineInfo) {
+	for _, lineInfo := range lineInfos {
+		switch {
+		case o.vectorizer != nil:
+			lineInfo.vector = o.vectorizer.vector(o.withoutStopWords(o.vectorizer.tokenizer.Tokenize(lineInfo.context)))
+		case o.tokenizer != nil:
+			lineInfo.tokens = append([]string{}, o.withoutStopWords(o.tokenizer.Tokenize(lineInfo.context))...)
+		case o.stopWords != nil:
+			lineInfo.tokens = append([]string{}, o.withoutStopWords(strings.Fields(lineInfo.context))...)
+		}
+	}
+}
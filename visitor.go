@@ -0,0 +1,69 @@
+package lhdiff
+
+import (
+	"sort"
+)
+
+// Visitor is told about the pairs of lines as Compare makes them, see WithVisitor.
+type Visitor interface {
+	// Paired is called for each pair of a left line and a right line, once it is final.
+	Paired(mapping LineMapping)
+	// Rejected is called for the most similar deleted line of each added line that isn't paired
+	// with it, because the pair isn't similar enough, or because a later added line is paired
+	// with the deleted line instead.
+	Rejected(mapping LineMapping)
+}
+
+// WithVisitor calls visitor as the lines are paired, so that consumers can stream the pairs or
+// compute statistics without keeping the result. The pairs that are certain (the unchanged
+// lines, the anchors, the pins and the lines that are equal) are visited first, in left line
+// order, and then those made by similarity, in left line order, once they are all made. The
+// cache isn't looked up, so that the comparison is always made. With WithMonotonic, the pairs
+// that cross others are visited too, although they end up in Result.Moved. Lines aren't rejected
+// in reference mode.
+func WithVisitor(visitor Visitor) Option {
+	return func(o *options) {
+		o.visitor = visitor
+	}
+}
+
+// visitCertain visits the pairs that are made before the lines are compared by similarity, and
+// returns the left lines of those that aren't unchanged.
+func (pairs *pairing) visitCertain(visitor Visitor) map[int]bool {
+	var mappings []LineMapping
+	for _, run := range pairs.identical {
+		for i := 0; i < run.Length; i++ {
+			mappings = append(mappings, LineMapping{Left: run.Left + i, Right: run.Right + i, Similarity: 1})
+		}
+	}
+	certain := make(map[int]bool, len(pairs.similar))
+	for left, pair := range pairs.similar {
+		mappings = append(mappings, LineMapping{Left: left, Right: pair.right.lineNumber, Similarity: pairs.similarities[left], WhitespaceOnly: whitespaceOnly(pair)})
+		certain[left] = true
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].Left < mappings[j].Left
+	})
+	for _, mapping := range mappings {
+		visitor.Paired(mapping)
+	}
+	return certain
+}
+
+// visitSimilar visits the pairs made by similarity, which are those that aren't certain.
+func (pairs *pairing) visitSimilar(visitor Visitor, certain map[int]bool) {
+	if visitor == nil {
+		return
+	}
+	var lefts []int
+	for left := range pairs.similar {
+		if !certain[left] {
+			lefts = append(lefts, left)
+		}
+	}
+	sort.Ints(lefts)
+	for _, left := range lefts {
+		pair := pairs.similar[left]
+		visitor.Paired(LineMapping{Left: left, Right: pair.right.lineNumber, Similarity: pairs.similarities[left], WhitespaceOnly: whitespaceOnly(pair)})
+	}
+}
@@ -0,0 +1,45 @@
+package lhdiff
+
+// The weights of the content similarity of short and long lines with WithAdaptiveWeighting.
+// The context is weighted by the rest.
+const shortLineContentFactor = 0.4
+const longLineContentFactor = 0.8
+
+// DefaultShortLineLength and DefaultLongLineLength are the line lengths used by the command
+// line for WithAdaptiveWeighting.
+const DefaultShortLineLength = 20
+const DefaultLongLineLength = 80
+
+// similarity returns the similarity of a pair of lines, weighted according to the options.
+func (o *options) similarity(pair LinePair) float64 {
+	contentFactor, contextFactor := o.factors(pair)
+	return pair.weightedSimilarity(contentFactor, contextFactor, o.contentGate)
+}
+
+// factors returns the weights of the content and context similarities of a pair of lines. Lines
+// have no context when the context size is 0, so they are compared by their content alone.
+func (o *options) factors(pair LinePair) (float64, float64) {
+	if o.contextSize == 0 {
+		return 1, 0
+	}
+	if o.longLineLength == 0 {
+		return ContentSimilarityFactor, ContextSimilarityFactor
+	}
+	length := len(pair.left.content)
+	if len(pair.right.content) > length {
+		length = len(pair.right.content)
+	}
+	contentFactor := o.contentFactor(length)
+	return contentFactor, 1 - contentFactor
+}
+
+func (o *options) contentFactor(length int) float64 {
+	if length <= o.shortLineLength {
+		return shortLineContentFactor
+	}
+	if length >= o.longLineLength {
+		return longLineContentFactor
+	}
+	t := float64(length-o.shortLineLength) / float64(o.longLineLength-o.shortLineLength)
+	return shortLineContentFactor + t*(longLineContentFactor-shortLineContentFactor)
+}